@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+var appleMakerNoteMarker = []byte("Apple iOS\x00")
+
+const (
+	tagAppleAccelerationVector = 0x0008
+	tagAppleHDRImageType       = 0x000A
+	tagAppleBurstUUID          = 0x000B
+	tagAppleContentIdentifier  = 0x0011
+)
+
+type appleMakerNoteDecoder struct{}
+
+// Decode parses the Apple MakerNote,
+// which consists of "Apple iOS\0", 2 bytes version, "MM" and a big endian IFD.
+// Offsets are relative to the beginning of the MakerNote.
+func (appleMakerNoteDecoder) Decode(a *APP1, e *IFDElement) (*MakerNote, error) {
+	if !bytes.HasPrefix(e.Value, appleMakerNoteMarker) {
+		return nil, nil
+	}
+	base := int(e.Uint32(a.Endian))
+	ifd, err := parseIFD(a.RawTIFF, base, uint32(len(appleMakerNoteMarker)+4), binary.BigEndian)
+	if err != nil {
+		return nil, fmt.Errorf("Could not parse Apple MakerNote IFD: %s", err)
+	}
+	return &MakerNote{Vendor: "Apple", Endian: binary.BigEndian, IFD: ifd}, nil
+}
+
+// AppleHDRImageType represents whether the image is HDR or the original of HDR.
+type AppleHDRImageType int32
+
+const (
+	AppleHDRImage         AppleHDRImageType = 3
+	AppleHDROriginalImage AppleHDRImageType = 4
+)
+
+var appleHDRImageTypeNames = map[AppleHDRImageType]string{
+	AppleHDRImage:         "HDR Image",
+	AppleHDROriginalImage: "Original Image",
+}
+
+func (t AppleHDRImageType) String() string {
+	if s, ok := appleHDRImageTypeNames[t]; ok {
+		return s
+	}
+	return fmt.Sprintf("AppleHDRImageType(%d)", int32(t))
+}
+
+// AppleHDRImageType returns the HDR image type of an Apple MakerNote.
+func (m *MakerNote) AppleHDRImageType() (AppleHDRImageType, bool) {
+	if m.Vendor != "Apple" {
+		return 0, false
+	}
+	e := m.IFD.Find(tagAppleHDRImageType)
+	if e == nil {
+		return 0, false
+	}
+	v, err := e.Int32s(m.Endian)
+	if err != nil || len(v) != 1 {
+		return 0, false
+	}
+	return AppleHDRImageType(v[0]), true
+}
+
+// AppleBurstUUID returns the UUID shared by the images of a burst in an Apple MakerNote.
+func (m *MakerNote) AppleBurstUUID() (string, bool) {
+	if m.Vendor != "Apple" {
+		return "", false
+	}
+	return findTrimmedASCII(m.IFD, tagAppleBurstUUID)
+}
+
+// AppleContentIdentifier returns the identifier linking the image and the video of a Live Photo.
+func (m *MakerNote) AppleContentIdentifier() (string, bool) {
+	if m.Vendor != "Apple" {
+		return "", false
+	}
+	return findTrimmedASCII(m.IFD, tagAppleContentIdentifier)
+}
+
+// AppleAccelerationVector returns the acceleration of x, y and z in g in an Apple MakerNote.
+func (m *MakerNote) AppleAccelerationVector() (x, y, z float64, ok bool) {
+	if m.Vendor != "Apple" {
+		return 0, 0, 0, false
+	}
+	e := m.IFD.Find(tagAppleAccelerationVector)
+	if e == nil {
+		return 0, 0, 0, false
+	}
+	v, err := e.SRationals(m.Endian)
+	if err != nil || len(v) != 3 {
+		return 0, 0, 0, false
+	}
+	return v[0].Float64(), v[1].Float64(), v[2].Float64(), true
+}
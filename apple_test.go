@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// appleMakerNote returns an Apple MakerNote of HDRImageType, BurstUUID and AccelerationVector.
+func appleMakerNote() []byte {
+	var b bytes.Buffer
+	b.Write(appleMakerNoteMarker)
+	b.WriteString("\x00\x01MM")
+	e := binary.BigEndian
+	binary.Write(&b, e, uint16(3))
+	// offsets are relative to the beginning of the MakerNote
+	for _, v := range []interface{}{
+		uint16(tagAppleAccelerationVector), uint16(10), uint32(3), uint32(61),
+		uint16(tagAppleHDRImageType), uint16(9), uint32(1), int32(3),
+		uint16(tagAppleBurstUUID), uint16(2), uint32(5), uint32(56),
+		uint32(0),
+	} {
+		binary.Write(&b, e, v)
+	}
+	b.WriteString("abcd\x00")
+	for _, v := range []int32{-1, 2, 0, 1, 98, 100} {
+		binary.Write(&b, e, v)
+	}
+	return b.Bytes()
+}
+
+func TestMakerNote_Apple(t *testing.T) {
+	a := decodeWithMakerNote(t, newTestAPP1(map[uint16]string{tagMake: "Apple"}), appleMakerNote())
+	m, err := a.MakerNote()
+	if err != nil {
+		t.Fatalf("MakerNote error: %s", err)
+	}
+	if m.Vendor != "Apple" || m.Endian != binary.BigEndian {
+		t.Errorf("MakerNote wants Apple of big endian but %s of %s", m.Vendor, m.Endian)
+	}
+	if v, ok := m.AppleHDRImageType(); !ok || v != AppleHDRImage || v.String() != "HDR Image" {
+		t.Errorf("AppleHDRImageType wants HDR Image but %s, %v", v, ok)
+	}
+	if v, ok := m.AppleBurstUUID(); !ok || v != "abcd" {
+		t.Errorf("AppleBurstUUID wants abcd but %q, %v", v, ok)
+	}
+	if x, y, z, ok := m.AppleAccelerationVector(); !ok || x != -0.5 || y != 0 || z != 0.98 {
+		t.Errorf("AppleAccelerationVector wants -0.5, 0, 0.98 but %f, %f, %f, %v", x, y, z, ok)
+	}
+	if _, ok := m.AppleContentIdentifier(); ok {
+		t.Errorf("AppleContentIdentifier wants false if not present")
+	}
+}
+
+func TestMakerNote_AppleAccessorsOfOtherVendor(t *testing.T) {
+	m := &MakerNote{Vendor: "Canon", Endian: binary.BigEndian, IFD: &IFD{}}
+	if _, ok := m.AppleHDRImageType(); ok {
+		t.Errorf("AppleHDRImageType of Canon wants false")
+	}
+	if _, ok := m.AppleBurstUUID(); ok {
+		t.Errorf("AppleBurstUUID of Canon wants false")
+	}
+	if s := AppleHDRImageType(1).String(); s != "AppleHDRImageType(1)" {
+		t.Errorf("String of unknown value wants AppleHDRImageType(1) but %s", s)
+	}
+}
+
+type testMakerNoteDecoder struct{}
+
+func (testMakerNoteDecoder) Decode(a *APP1, e *IFDElement) (*MakerNote, error) {
+	if !bytes.HasPrefix(e.Value, []byte("TEST")) {
+		return nil, nil
+	}
+	return &MakerNote{Vendor: "Test", Endian: a.Endian, IFD: &IFD{}}, nil
+}
+
+func TestRegisterMakerNoteDecoder(t *testing.T) {
+	defer func(decoders []MakerNoteDecoder) { makerNoteDecoders = decoders }(makerNoteDecoders)
+	RegisterMakerNoteDecoder(testMakerNoteDecoder{})
+	// the registered decoder is tried before the Canon decoder
+	a := decodeWithMakerNote(t, newTestAPP1(map[uint16]string{tagMake: "Canon"}), []byte("TEST maker note"))
+	m, err := a.MakerNote()
+	if err != nil || m == nil || m.Vendor != "Test" {
+		t.Errorf("MakerNote wants Test but %+v, %v", m, err)
+	}
+}
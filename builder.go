@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// NewAPP1 returns an APP1 with an empty 0th IFD.
+// Elements can be added by IFD.Set and the APP1 can be written by the writer.
+func NewAPP1(endian binary.ByteOrder) *APP1 {
+	return &APP1{Endian: endian, IFD0: &IFD{}}
+}
+
+// MinimalOrientationAPP1 returns the smallest APP1 segment including the marker,
+// which contains only the 0th IFD with Orientation.
+// It is useful for thumbnail generators to keep the orientation.
+// It returns nil if the endian is neither big nor little endian.
+func MinimalOrientationAPP1(endian binary.ByteOrder, o Orientation) []byte {
+	if _, err := endianName(endian); err != nil {
+		return nil
+	}
+	app1 := NewAPP1(endian)
+	app1.IFD0.Set(newShortElement(tagOrientation, uint16(o), endian))
+	var b bytes.Buffer
+	if err := writeAPP1(&b, app1); err != nil {
+		return nil
+	}
+	return b.Bytes()
+}
+
+// newElement returns an element of the value.
+// A value up to 4 bytes is padded to 4 bytes for the inline value.
+func newElement(tag uint16, typ IFDElementType, count uint32, value []byte) *IFDElement {
+	e := &IFDElement{Tag: tag, Type: typ, Count: count, Value: value}
+	if e.Length() <= 4 {
+		e.rawValue = make([]byte, 4)
+		copy(e.rawValue, value)
+		e.Value = e.rawValue
+	} else {
+		// the offset is determined on writing
+		e.rawValue = make([]byte, 4)
+	}
+	return e
+}
+
+func newASCIIElement(tag uint16, s string) *IFDElement {
+	b := append([]byte(s), 0)
+	return newElement(tag, 2, uint32(len(b)), b)
+}
+
+func newShortElement(tag uint16, value uint16, endian binary.ByteOrder) *IFDElement {
+	b := make([]byte, 2)
+	endian.PutUint16(b, value)
+	return newElement(tag, 3, 1, b)
+}
+
+func newLongElement(tag uint16, value uint32, endian binary.ByteOrder) *IFDElement {
+	b := make([]byte, 4)
+	endian.PutUint32(b, value)
+	return newElement(tag, 4, 1, b)
+}
+
+func newRationalElement(tag uint16, values []Rational, endian binary.ByteOrder) *IFDElement {
+	b := make([]byte, 8*len(values))
+	for i, r := range values {
+		endian.PutUint32(b[i*8:], r.Numerator)
+		endian.PutUint32(b[i*8+4:], r.Denominator)
+	}
+	return newElement(tag, 5, uint32(len(values)), b)
+}
+
+func newSRationalElement(tag uint16, values []SRational, endian binary.ByteOrder) *IFDElement {
+	b := make([]byte, 8*len(values))
+	for i, r := range values {
+		endian.PutUint32(b[i*8:], uint32(r.Numerator))
+		endian.PutUint32(b[i*8+4:], uint32(r.Denominator))
+	}
+	return newElement(tag, 10, uint32(len(values)), b)
+}
+
+func newUndefinedElement(tag uint16, value []byte) *IFDElement {
+	return newElement(tag, 7, uint32(len(value)), value)
+}
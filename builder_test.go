@@ -0,0 +1,36 @@
+package main
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func TestMinimalOrientationAPP1(t *testing.T) {
+	for _, endian := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		t.Run(endian.String(), func(t *testing.T) {
+			b := MinimalOrientationAPP1(endian, 6)
+			if len(b) == 0 || b[0] != 0xff || b[1] != markerAPP1 {
+				t.Fatalf("MinimalOrientationAPP1 wants the APP1 marker but % x", b)
+			}
+			if length := int(binary.BigEndian.Uint16(b[2:4])); length != len(b)-2 {
+				t.Errorf("length wants %d but %d", len(b)-2, length)
+			}
+			h, err := Decode(insertSegments(loadTestdata(t, "noexif.jpg"), b))
+			if err != nil {
+				t.Fatalf("Decode error: %s", err)
+			}
+			if h.APP1.Endian != endian {
+				t.Errorf("Endian wants %s but %s", endian, h.APP1.Endian)
+			}
+			if o, ok := h.APP1.Orientation(); !ok || o != 6 {
+				t.Errorf("Orientation wants 6 but %d, %v", o, ok)
+			}
+			if n := len(h.APP1.IFD0.Elements); n != 1 || h.APP1.ExifIFD != nil || h.APP1.IFD1 != nil {
+				t.Errorf("APP1 wants only Orientation in IFD0 but %d elements", n)
+			}
+		})
+	}
+	if b := MinimalOrientationAPP1(nil, 6); b != nil {
+		t.Errorf("MinimalOrientationAPP1 of nil endian wants nil but % x", b)
+	}
+}
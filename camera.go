@@ -0,0 +1,82 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// HasCameraMetadata returns true if the image looks like an original file from a camera.
+//
+// It requires Make and Model, and at least one of the capture settings
+// ExposureTime, FNumber or ISO.
+// Edited images and screenshots usually have only Software or a few tags,
+// because editors drop the capture settings or never had them.
+func (a *APP1) HasCameraMetadata() bool {
+	if a == nil {
+		return false
+	}
+	if _, ok := a.Make(); !ok {
+		return false
+	}
+	if _, ok := a.Model(); !ok {
+		return false
+	}
+	if _, ok := a.ExposureTime(); ok {
+		return true
+	}
+	if _, ok := a.FNumber(); ok {
+		return true
+	}
+	if _, ok := a.ISO(); ok {
+		return true
+	}
+	return false
+}
+
+// cameraMakeSuffixes are the words removed from the end of Make.
+var cameraMakeSuffixes = map[string]bool{
+	"corporation": true,
+	"corp":        true,
+	"company":     true,
+	"co":          true,
+	"ltd":         true,
+	"inc":         true,
+	"imaging":     true,
+	"optical":     true,
+}
+
+var nonAlphanumeric = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slugWords returns the lowercased alphanumeric words of the string.
+func slugWords(s string) []string {
+	return strings.Fields(nonAlphanumeric.ReplaceAllString(strings.ToLower(s), " "))
+}
+
+// CameraKey returns the normalized key of Make and Model for joining against camera databases,
+// e.g. "canon_eos_5d_mark_iv" for "Canon" and "Canon EOS 5D Mark IV".
+//
+// The rules are:
+//   - lowercase and collapse runs of whitespace and punctuation into "_"
+//   - remove corporate suffixes of Make such as "Corporation", "Co., Ltd." or "Imaging Corp."
+//   - remove Make from the beginning of Model if it is duplicated
+//
+// It returns an empty string if both Make and Model are not present or the APP1 is nil.
+func (a *APP1) CameraKey() string {
+	if a == nil {
+		return ""
+	}
+	cameraMake, _ := a.Make()
+	model, _ := a.Model()
+	makeWords := slugWords(cameraMake)
+	for len(makeWords) > 1 && cameraMakeSuffixes[makeWords[len(makeWords)-1]] {
+		makeWords = makeWords[:len(makeWords)-1]
+	}
+	modelWords := slugWords(model)
+	if len(makeWords) > 0 && len(modelWords) >= len(makeWords) &&
+		strings.Join(modelWords[:len(makeWords)], "_") == strings.Join(makeWords, "_") {
+		modelWords = modelWords[len(makeWords):]
+	} else if len(makeWords) > 0 && len(modelWords) > 0 && modelWords[0] == makeWords[0] {
+		modelWords = modelWords[1:]
+	}
+	return strings.Join(append(makeWords, modelWords...), "_")
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+// newTestAPP1 returns an APP1 of the 0th IFD with the ASCII elements.
+func newTestAPP1(values map[uint16]string) *APP1 {
+	a := &APP1{Endian: binary.LittleEndian, IFD0: &IFD{}}
+	for tag, v := range values {
+		a.IFD0.Set(newASCIIElement(tag, v))
+	}
+	return a
+}
+
+func TestAPP1_CameraKey(t *testing.T) {
+	for _, c := range []struct {
+		make, model string
+		want        string
+	}{
+		{"Canon", "Canon EOS 5D Mark IV", "canon_eos_5d_mark_iv"},
+		{"NIKON CORPORATION", "NIKON D850", "nikon_d850"},
+		{"OLYMPUS IMAGING CORP.", "E-M1", "olympus_e_m1"},
+		{"Apple", "iPhone 12 Pro", "apple_iphone_12_pro"},
+		{"", "", ""},
+	} {
+		values := map[uint16]string{}
+		if c.make != "" {
+			values[tagMake] = c.make
+		}
+		if c.model != "" {
+			values[tagModel] = c.model
+		}
+		if got := newTestAPP1(values).CameraKey(); got != c.want {
+			t.Errorf("CameraKey(%q, %q) wants %q but %q", c.make, c.model, c.want, got)
+		}
+	}
+}
+
+func TestAPP1_CameraKey_Nil(t *testing.T) {
+	var a *APP1
+	if got := a.CameraKey(); got != "" {
+		t.Errorf("CameraKey wants empty but %q", got)
+	}
+	if a.HasCameraMetadata() {
+		t.Errorf("HasCameraMetadata wants false")
+	}
+}
+
+func TestAPP1_HasCameraMetadata(t *testing.T) {
+	if h := decodeTestdata(t, "ii.jpg"); !h.APP1.HasCameraMetadata() {
+		t.Errorf("HasCameraMetadata wants true")
+	}
+}
+
+func TestAPP1_HasCameraMetadata_NoCaptureSettings(t *testing.T) {
+	a := newTestAPP1(map[uint16]string{tagMake: "Canon", tagModel: "Canon EOS 5D Mark III"})
+	if a.HasCameraMetadata() {
+		t.Errorf("HasCameraMetadata without capture settings wants false")
+	}
+	a.SetElement(ExifIFDKind, newShortElement(tagPhotographicSensitivity, 100, a.Endian))
+	if !a.HasCameraMetadata() {
+		t.Errorf("HasCameraMetadata with ISO wants true")
+	}
+	b := newTestAPP1(map[uint16]string{tagModel: "Canon EOS 5D Mark III"})
+	b.SetElement(ExifIFDKind, newShortElement(tagPhotographicSensitivity, 100, b.Endian))
+	if b.HasCameraMetadata() {
+		t.Errorf("HasCameraMetadata without Make wants false")
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"math"
+	"time"
+)
+
+// CaptureTolerance represents the tolerances of SameCapture.
+type CaptureTolerance struct {
+	// Time is the maximum difference of DateTimeOriginal.
+	Time time.Duration
+	// ExposureTime is the maximum difference of exposure times in seconds.
+	ExposureTime float64
+	// FNumber is the maximum difference of F numbers.
+	FNumber float64
+	// ISO is the maximum difference of ISO.
+	ISO int
+}
+
+// DefaultCaptureTolerance is the tolerance of SameCapture, which allows a burst within 2 seconds.
+var DefaultCaptureTolerance = CaptureTolerance{
+	Time:         2 * time.Second,
+	ExposureTime: 0.0001,
+	FNumber:      0.05,
+}
+
+// SameCapture returns true if the two APP1s look taken by the same camera with the same settings
+// within DefaultCaptureTolerance.
+func SameCapture(a, b *APP1) bool {
+	return DefaultCaptureTolerance.SameCapture(a, b)
+}
+
+// SameCapture returns true if the two APP1s have the same Make, Model and BodySerialNumber,
+// and the capture settings and DateTimeOriginal are within the tolerance.
+// A setting is ignored if both do not have it, and they are different if only one has it.
+func (t CaptureTolerance) SameCapture(a, b *APP1) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	for _, f := range []func(*APP1) (string, bool){(*APP1).Make, (*APP1).Model, (*APP1).BodySerialNumber} {
+		va, oka := f(a)
+		vb, okb := f(b)
+		if oka != okb || va != vb {
+			return false
+		}
+	}
+	ea, oka := a.ExposureTime()
+	eb, okb := b.ExposureTime()
+	if oka != okb || (oka && math.Abs(ea.Float64()-eb.Float64()) > t.ExposureTime) {
+		return false
+	}
+	fa, oka := a.FNumber()
+	fb, okb := b.FNumber()
+	if oka != okb || (oka && math.Abs(fa-fb) > t.FNumber) {
+		return false
+	}
+	ia, oka := a.ISO()
+	ib, okb := b.ISO()
+	if oka != okb || (oka && abs(ia-ib) > t.ISO) {
+		return false
+	}
+	ta := a.Timestamps().DateTimeOriginal
+	tb := b.Timestamps().DateTimeOriginal
+	if ta.IsZero() != tb.IsZero() {
+		return false
+	}
+	d := ta.Sub(tb)
+	if d < 0 {
+		d = -d
+	}
+	return d <= t.Time
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
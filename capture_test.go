@@ -0,0 +1,61 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSameCapture(t *testing.T) {
+	a := decodeTestdata(t, "ii.jpg").APP1
+	if !SameCapture(a, decodeTestdata(t, "mm.jpg").APP1) {
+		t.Errorf("SameCapture of the same capture in the other endian wants true")
+	}
+	for _, c := range []struct {
+		name string
+		edit func(b *APP1)
+		want bool
+	}{
+		{"within time", func(b *APP1) {
+			b.SetElement(ExifIFDKind, newASCIIElement(tagDateTimeOriginal, "2018:09:22 10:11:14"))
+		}, true},
+		{"beyond time", func(b *APP1) {
+			b.SetElement(ExifIFDKind, newASCIIElement(tagDateTimeOriginal, "2018:09:22 10:11:15"))
+		}, false},
+		{"another model", func(b *APP1) {
+			b.SetElement(IFD0Kind, newASCIIElement(tagModel, "Canon EOS R5"))
+		}, false},
+		{"another serial number", func(b *APP1) {
+			b.SetElement(ExifIFDKind, newASCIIElement(tagBodySerialNumber, "012345678901"))
+		}, false},
+		{"another exposure time", func(b *APP1) { b.SetExposureTime(1, 125) }, false},
+		{"another F number", func(b *APP1) { b.SetFNumber(40, 10) }, false},
+		{"another ISO", func(b *APP1) {
+			b.SetElement(ExifIFDKind, newShortElement(tagPhotographicSensitivity, 800, b.Endian))
+		}, false},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			b := a.Clone()
+			c.edit(b)
+			if got := SameCapture(a, b); got != c.want {
+				t.Errorf("SameCapture wants %v but %v", c.want, got)
+			}
+		})
+	}
+	if SameCapture(a, nil) {
+		t.Errorf("SameCapture of nil wants false")
+	}
+}
+
+func TestCaptureTolerance_SameCapture(t *testing.T) {
+	a := decodeTestdata(t, "ii.jpg").APP1
+	b := a.Clone()
+	b.SetElement(ExifIFDKind, newShortElement(tagPhotographicSensitivity, 500, b.Endian))
+	b.SetElement(ExifIFDKind, newASCIIElement(tagDateTimeOriginal, "2018:09:22 10:12:12"))
+	tolerance := CaptureTolerance{Time: time.Minute, ExposureTime: 0.0001, FNumber: 0.05, ISO: 100}
+	if !tolerance.SameCapture(a, b) {
+		t.Errorf("SameCapture wants true within the tolerance")
+	}
+	if DefaultCaptureTolerance.SameCapture(a, b) {
+		t.Errorf("SameCapture wants false within the default tolerance")
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import "fmt"
+
+const (
+	tagCompositeImage                      = 0xA460
+	tagSourceImageNumberOfCompositeImage   = 0xA461
+	tagSourceExposureTimesOfCompositeImage = 0xA462
+)
+
+// CompositeImage indicates whether the image is a composite of multiple frames, since Exif 2.32.
+type CompositeImage uint16
+
+const (
+	CompositeImageUnknown      CompositeImage = 0
+	CompositeImageNonComposite CompositeImage = 1
+	CompositeImageGeneral      CompositeImage = 2
+	CompositeImageCaptured     CompositeImage = 3
+)
+
+var compositeImageNames = map[CompositeImage]string{
+	0: "Unknown",
+	1: "Non-composite image",
+	2: "General composite image",
+	3: "Composite image captured when shooting",
+}
+
+func (c CompositeImage) String() string {
+	if s, ok := compositeImageNames[c]; ok {
+		return s
+	}
+	return fmt.Sprintf("Reserved(%d)", uint16(c))
+}
+
+// CompositeImage returns the CompositeImage tag in the Exif IFD.
+func (a *APP1) CompositeImage() (CompositeImage, bool) {
+	v, ok := findUint16(a.ExifIFD, tagCompositeImage, a.Endian)
+	return CompositeImage(v), ok
+}
+
+// SourceImageNumberOfCompositeImage returns the total number of source images
+// and the number of images used for the composite image.
+func (a *APP1) SourceImageNumberOfCompositeImage() (total, used int, ok bool) {
+	e := a.ExifIFD.Find(tagSourceImageNumberOfCompositeImage)
+	if e == nil {
+		return 0, 0, false
+	}
+	v, err := e.Uint16s(a.Endian)
+	if err != nil || len(v) != 2 {
+		return 0, 0, false
+	}
+	return int(v[0]), int(v[1]), true
+}
+
+// SourceExposureTimesOfCompositeImage returns the raw bytes of the exposure times of the source images.
+func (a *APP1) SourceExposureTimesOfCompositeImage() ([]byte, bool) {
+	e := a.ExifIFD.Find(tagSourceExposureTimesOfCompositeImage)
+	if e == nil || e.Type != 7 || e.checkLength() != nil {
+		return nil, false
+	}
+	return e.Value[:e.Length()], true
+}
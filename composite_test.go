@@ -0,0 +1,40 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestAPP1_CompositeImage(t *testing.T) {
+	a := newTestAPP1(nil)
+	if _, ok := a.CompositeImage(); ok {
+		t.Errorf("CompositeImage wants false if not present")
+	}
+	a.SetElement(ExifIFDKind, newShortElement(tagCompositeImage, 3, a.Endian))
+	a.SetElement(ExifIFDKind, newElement(tagSourceImageNumberOfCompositeImage, 3, 2, []byte{5, 0, 3, 0}))
+	a.SetElement(ExifIFDKind, newUndefinedElement(tagSourceExposureTimesOfCompositeImage, []byte("exposure times")))
+	if v, ok := a.CompositeImage(); !ok || v != CompositeImageCaptured {
+		t.Errorf("CompositeImage wants captured but %s, %v", v, ok)
+	}
+	if total, used, ok := a.SourceImageNumberOfCompositeImage(); !ok || total != 5 || used != 3 {
+		t.Errorf("SourceImageNumberOfCompositeImage wants 5, 3 but %d, %d, %v", total, used, ok)
+	}
+	if b, ok := a.SourceExposureTimesOfCompositeImage(); !ok || !bytes.Equal(b, []byte("exposure times")) {
+		t.Errorf("SourceExposureTimesOfCompositeImage wants the raw bytes but %q, %v", b, ok)
+	}
+	if s := CompositeImage(4).String(); s != "Reserved(4)" {
+		t.Errorf("String of unknown value wants Reserved(4) but %s", s)
+	}
+}
+
+func TestAPP1_SourceImageNumberOfCompositeImage_Invalid(t *testing.T) {
+	a := newTestAPP1(nil)
+	a.SetElement(ExifIFDKind, newShortElement(tagSourceImageNumberOfCompositeImage, 5, a.Endian))
+	a.SetElement(ExifIFDKind, newASCIIElement(tagSourceExposureTimesOfCompositeImage, "ASCII"))
+	if _, _, ok := a.SourceImageNumberOfCompositeImage(); ok {
+		t.Errorf("SourceImageNumberOfCompositeImage of a single value wants false")
+	}
+	if _, ok := a.SourceExposureTimesOfCompositeImage(); ok {
+		t.Errorf("SourceExposureTimesOfCompositeImage of ASCII wants false")
+	}
+}
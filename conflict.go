@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Conflict represents a field whose values differ between Exif and XMP.
+type Conflict struct {
+	Field string
+	Exif  string
+	XMP   string
+}
+
+func (c Conflict) String() string {
+	return fmt.Sprintf("%s: Exif=%q, XMP=%q", c.Field, c.Exif, c.XMP)
+}
+
+// Conflicts compares the fields present in both Exif and XMP,
+// i.e. Make, Model, Orientation, DateTimeOriginal and GPS position.
+// Date and time are compared in the wall clock without the time zone,
+// and the position is compared within 0.0001 degrees.
+// It returns nil if Exif or XMP is not present.
+func (h *JPEGHeader) Conflicts() []Conflict {
+	packet := h.XMP()
+	if h.APP1 == nil || packet == nil {
+		return nil
+	}
+	var conflicts []Conflict
+	for _, f := range []struct {
+		field string
+		tag   uint16
+		xmp   string
+	}{
+		{"Make", tagMake, "tiff:Make"},
+		{"Model", tagModel, "tiff:Model"},
+	} {
+		exif, ok := findTrimmedASCII(h.APP1.IFD0, f.tag)
+		xmp, xmpOK := xmpProperty(packet, f.xmp)
+		if ok && xmpOK && exif != xmp {
+			conflicts = append(conflicts, Conflict{f.field, exif, xmp})
+		}
+	}
+	if o, ok := h.APP1.Orientation(); ok {
+		if xmp, ok := xmpProperty(packet, "tiff:Orientation"); ok && xmp != strconv.Itoa(int(o)) {
+			conflicts = append(conflicts, Conflict{"Orientation", strconv.Itoa(int(o)), xmp})
+		}
+	}
+	if exif, ok := findTrimmedASCII(h.APP1.ExifIFD, tagDateTimeOriginal); ok {
+		if xmp, ok := xmpProperty(packet, "exif:DateTimeOriginal"); ok && !sameXMPDateTime(exif, xmp) {
+			conflicts = append(conflicts, Conflict{"DateTimeOriginal", exif, xmp})
+		}
+	}
+	if lat, lng, ok := h.APP1.LatLng(); ok {
+		xmpLat, latOK := xmpProperty(packet, "exif:GPSLatitude")
+		xmpLng, lngOK := xmpProperty(packet, "exif:GPSLongitude")
+		if latOK && lngOK {
+			exif := fmt.Sprintf("%.6f,%.6f", lat, lng)
+			xmp := xmpLat + " " + xmpLng
+			v, err := parseXMPCoordinate(xmpLat)
+			w, err2 := parseXMPCoordinate(xmpLng)
+			if err != nil || err2 != nil || math.Abs(v-lat) > 1e-4 || math.Abs(w-lng) > 1e-4 {
+				conflicts = append(conflicts, Conflict{"GPS", exif, xmp})
+			}
+		}
+	}
+	return conflicts
+}
+
+// sameXMPDateTime returns true if the XMP date such as "2018-09-22T10:11:12.34+09:00"
+// has the same wall clock as the Exif date such as "2018:09:22 10:11:12".
+// The fraction of seconds and time zone are ignored, and the time or seconds are ignored if XMP does not have them.
+func sameXMPDateTime(exif, xmp string) bool {
+	if i := strings.IndexAny(xmp, ".+Z"); i >= 0 {
+		xmp = xmp[:i]
+	}
+	if i := strings.LastIndex(xmp, "-"); i > len("2006-01-02") {
+		xmp = xmp[:i]
+	}
+	for _, layout := range []struct{ xmp, exif string }{
+		{"2006-01-02T15:04:05", dateTimeLayout},
+		{"2006-01-02T15:04", "2006:01:02 15:04"},
+		{"2006-01-02", "2006:01:02"},
+	} {
+		t, err := time.Parse(layout.xmp, xmp)
+		if err != nil {
+			continue
+		}
+		return strings.HasPrefix(exif, t.Format(layout.exif))
+	}
+	return false
+}
+
+// parseXMPCoordinate parses the GPS coordinate of XMP, "DDD,MM,SSk" or "DDD,MM.mmk"
+// where k is N, S, E or W. South and west are negative.
+func parseXMPCoordinate(s string) (float64, error) {
+	if len(s) < 2 {
+		return 0, fmt.Errorf("Invalid coordinate: %s", s)
+	}
+	ref := s[len(s)-1]
+	parts := strings.Split(s[:len(s)-1], ",")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("Invalid coordinate: %s", s)
+	}
+	var v float64
+	for i, p := range parts {
+		f, err := strconv.ParseFloat(p, 64)
+		if err != nil {
+			return 0, fmt.Errorf("Invalid coordinate: %s", s)
+		}
+		v += f / math.Pow(60, float64(i))
+	}
+	switch ref {
+	case 'N', 'E':
+		return v, nil
+	case 'S', 'W':
+		return -v, nil
+	}
+	return 0, fmt.Errorf("Invalid reference of coordinate: %s", s)
+}
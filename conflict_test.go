@@ -0,0 +1,77 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestJPEGHeader_Conflicts(t *testing.T) {
+	h := decodeTestdata(t, "ii.jpg")
+	if c := h.Conflicts(); c != nil {
+		t.Errorf("Conflicts without XMP wants nil but %v", c)
+	}
+	same := `<rdf:Description tiff:Make="Canon" tiff:Model="Canon EOS 5D Mark III" tiff:Orientation="6"` +
+		` exif:DateTimeOriginal="2018-09-22T10:11:12.34+09:00" exif:GPSLatitude="35,40.5N" exif:GPSLongitude="139,45.25E"/>`
+	if err := h.SetXMP([]byte(same), nil); err != nil {
+		t.Fatalf("SetXMP error: %s", err)
+	}
+	if c := h.Conflicts(); len(c) != 0 {
+		t.Errorf("Conflicts wants none but %v", c)
+	}
+
+	differ := `<rdf:Description tiff:Make="Nikon" tiff:Orientation="1"` +
+		` exif:DateTimeOriginal="2018-09-22T10:12" exif:GPSLatitude="35,40.5S" exif:GPSLongitude="139,45.25E"/>`
+	if err := h.SetXMP([]byte(differ), nil); err != nil {
+		t.Fatalf("SetXMP error: %s", err)
+	}
+	want := []Conflict{
+		{"Make", "Canon", "Nikon"},
+		{"Orientation", "6", "1"},
+		{"DateTimeOriginal", "2018:09:22 10:11:12", "2018-09-22T10:12"},
+		{"GPS", "35.675000,139.754167", "35,40.5S 139,45.25E"},
+	}
+	if c := h.Conflicts(); !reflect.DeepEqual(c, want) {
+		t.Errorf("Conflicts wants %v but %v", want, c)
+	}
+}
+
+func TestSameXMPDateTime(t *testing.T) {
+	for _, c := range []struct {
+		xmp  string
+		want bool
+	}{
+		{"2018-09-22T10:11:12", true},
+		{"2018-09-22T10:11:12Z", true},
+		{"2018-09-22T10:11:12-05:00", true},
+		{"2018-09-22T10:11", true},
+		{"2018-09-22", true},
+		{"2018-09-22T10:11:13", false},
+		{"2018-09-23", false},
+		{"invalid", false},
+	} {
+		if got := sameXMPDateTime("2018:09:22 10:11:12", c.xmp); got != c.want {
+			t.Errorf("sameXMPDateTime of %s wants %v but %v", c.xmp, c.want, got)
+		}
+	}
+}
+
+func TestParseXMPCoordinate(t *testing.T) {
+	for _, c := range []struct {
+		s    string
+		want float64
+	}{
+		{"35,40,30N", 35.675},
+		{"35,40.5N", 35.675},
+		{"139,45.25W", -139.754166},
+	} {
+		v, err := parseXMPCoordinate(c.s)
+		if err != nil || v-c.want > 1e-6 || c.want-v > 1e-6 {
+			t.Errorf("parseXMPCoordinate of %s wants %f but %f, %v", c.s, c.want, v, err)
+		}
+	}
+	for _, s := range []string{"", "35N", "35,40,30,1N", "35,xN", "35,40.5X"} {
+		if _, err := parseXMPCoordinate(s); err == nil {
+			t.Errorf("parseXMPCoordinate of %q wants error", s)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+// Clone returns a deep copy of the element.
+func (e *IFDElement) Clone() *IFDElement {
+	// the lazy value is not copied
+	return &IFDElement{
+		Tag:      e.Tag,
+		Type:     e.Type,
+		Count:    e.Count,
+		Value:    append([]byte{}, e.Value...),
+		rawValue: append([]byte{}, e.rawValue...),
+		Decoded:  e.Decoded,
+	}
+}
+
+// Clone returns a deep copy of the IFD, or nil if the IFD is nil.
+func (d *IFD) Clone() *IFD {
+	if d == nil {
+		return nil
+	}
+	c := &IFD{NextIFDOffset: d.NextIFDOffset, rawValues: append([]byte{}, d.rawValues...)}
+	for _, e := range d.Elements {
+		c.Elements = append(c.Elements, e.Clone())
+	}
+	return c
+}
+
+// Clone returns a deep copy of the APP1.
+// RawTIFF is not copied, so the offsets are recomputed when the copy is written.
+func (a *APP1) Clone() *APP1 {
+	c := &APP1{
+		Endian:              a.Endian,
+		rawPreIFD:           append([]byte{}, a.rawPreIFD...),
+		IFD0:                a.IFD0.Clone(),
+		ExifIFD:             a.ExifIFD.Clone(),
+		GPSIFD:              a.GPSIFD.Clone(),
+		InteroperabilityIFD: a.InteroperabilityIFD.Clone(),
+		IFD1:                a.IFD1.Clone(),
+		thumbnail:           append([]byte(nil), a.thumbnail...),
+	}
+	for _, page := range a.Pages {
+		switch page {
+		case a.IFD0:
+			c.Pages = append(c.Pages, c.IFD0)
+		case a.IFD1:
+			c.Pages = append(c.Pages, c.IFD1)
+		default:
+			c.Pages = append(c.Pages, page.Clone())
+		}
+	}
+	return c
+}
+
+// CopyExif replaces the Exif of dst with a deep copy of src.
+// The tags of the exclude names or ids such as "MakerNote" or "JPEGInterchangeFormat" are removed from the copy.
+func CopyExif(dst, src *JPEGHeader, exclude ...string) error {
+	if src.APP1 == nil {
+		dst.APP1 = nil
+		return nil
+	}
+	app1 := src.APP1.Clone()
+	for _, name := range exclude {
+		if err := removeTags(app1, name); err != nil {
+			return err
+		}
+	}
+	dst.APP1 = app1
+	return nil
+}
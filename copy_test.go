@@ -0,0 +1,43 @@
+package main
+
+import "testing"
+
+func TestAPP1_Clone(t *testing.T) {
+	a := decodeTestdata(t, "ii.jpg").APP1
+	c := a.Clone()
+	assertSameElements(t, a, c)
+	c.IFD0.Find(tagMake).Value[0] = 'X'
+	if v, _ := a.Make(); v != "Canon" {
+		t.Errorf("Make of the original wants Canon but %q", v)
+	}
+	if c.RawTIFF != nil {
+		t.Errorf("RawTIFF of the copy wants nil")
+	}
+	if len(c.Thumbnail()) != len(a.Thumbnail()) {
+		t.Errorf("Thumbnail wants %d bytes but %d bytes", len(a.Thumbnail()), len(c.Thumbnail()))
+	}
+}
+
+func TestCopyExif(t *testing.T) {
+	src := decodeTestdata(t, "ii.jpg")
+	dst := decodeTestdata(t, "noexif.jpg")
+	if err := CopyExif(dst, src, "GPSInfo", "JPEGInterchangeFormat"); err != nil {
+		t.Fatalf("CopyExif error: %s", err)
+	}
+	decoded := reencode(t, dst)
+	if v, ok := decoded.APP1.Model(); !ok || v != "Canon EOS 5D Mark III" {
+		t.Errorf("Model wants Canon EOS 5D Mark III but %q", v)
+	}
+	if decoded.APP1.GPSIFD != nil || decoded.APP1.Thumbnail() != nil {
+		t.Errorf("CopyExif wants GPS and thumbnail excluded")
+	}
+	if src.APP1.GPSIFD == nil || src.APP1.Thumbnail() == nil {
+		t.Errorf("CopyExif wants the source kept")
+	}
+	if err := CopyExif(dst, src, "NoSuchTag"); err == nil {
+		t.Errorf("CopyExif of unknown tag wants error")
+	}
+	if err := CopyExif(dst, decodeTestdata(t, "noexif.jpg")); err != nil || dst.APP1 != nil {
+		t.Errorf("CopyExif from no Exif wants nil but %v", err)
+	}
+}
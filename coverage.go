@@ -0,0 +1,33 @@
+package main
+
+var coverageKeys = []string{"Make", "Model", "DateTimeOriginal", "Orientation", "GPS",
+	"ExposureTime", "FNumber", "ISO", "FocalLength", "LensModel"}
+
+// Coverage returns whether each of the common fields is present and decodable,
+// for a report of metadata completeness.
+// The keys are Make, Model, DateTimeOriginal, Orientation, GPS, ExposureTime,
+// FNumber, ISO, FocalLength and LensModel.
+// All fields are false if the APP1 is nil.
+func (a *APP1) Coverage() map[string]bool {
+	if a == nil {
+		coverage := make(map[string]bool, len(coverageKeys))
+		for _, key := range coverageKeys {
+			coverage[key] = false
+		}
+		return coverage
+	}
+	ok := func(_ interface{}, ok bool) bool { return ok }
+	_, _, gps := a.LatLng()
+	return map[string]bool{
+		"Make":             ok(a.Make()),
+		"Model":            ok(a.Model()),
+		"DateTimeOriginal": !a.Timestamps().DateTimeOriginal.IsZero(),
+		"Orientation":      ok(a.Orientation()),
+		"GPS":              gps,
+		"ExposureTime":     ok(a.ExposureTime()),
+		"FNumber":          ok(a.FNumber()),
+		"ISO":              ok(a.ISO()),
+		"FocalLength":      ok(a.FocalLength()),
+		"LensModel":        ok(a.LensModel()),
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import "testing"
+
+func TestAPP1_Coverage(t *testing.T) {
+	for _, c := range []struct {
+		name string
+		want map[string]bool
+	}{
+		{"ii.jpg", map[string]bool{"GPS": true}},
+		{"nothumb.jpg", map[string]bool{"GPS": false}},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			coverage := decodeTestdata(t, c.name).APP1.Coverage()
+			if len(coverage) != len(coverageKeys) {
+				t.Errorf("Coverage wants %d keys but %v", len(coverageKeys), coverage)
+			}
+			for _, key := range coverageKeys {
+				want, ok := c.want[key]
+				if !ok {
+					want = true
+				}
+				if coverage[key] != want {
+					t.Errorf("Coverage of %s wants %v but %v", key, want, coverage[key])
+				}
+			}
+		})
+	}
+}
+
+func TestAPP1_Coverage_Nil(t *testing.T) {
+	var a *APP1
+	coverage := a.Coverage()
+	if len(coverage) != len(coverageKeys) {
+		t.Errorf("Coverage wants %d keys but %v", len(coverageKeys), coverage)
+	}
+	for key, v := range coverage {
+		if v {
+			t.Errorf("Coverage of %s wants false", key)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+)
+
+var csvHeader = []string{"file", "ifd", "tag_id", "tag_name", "type", "value"}
+
+// writeCSV writes a row for each element of the header.
+func writeCSV(w *csv.Writer, filename string, h *JPEGHeader) error {
+	var err error
+	h.APP1.Walk(func(kind IFDKind, e *IFDElement) {
+		if err != nil {
+			return
+		}
+		name, _ := TagName(kind, e.Tag)
+		err = w.Write([]string{
+			filename,
+			kind.String(),
+			fmt.Sprintf("0x%04X", e.Tag),
+			name,
+			e.Type.String(),
+			formatValue(e, h.APP1.Endian),
+		})
+	})
+	return err
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"testing"
+)
+
+func TestRun_CSV(t *testing.T) {
+	var b bytes.Buffer
+	if err := run(nil, &b, "csv", false, []string{"testdata/ii.jpg", "testdata/noexif.jpg"}); err != nil {
+		t.Fatalf("run error: %s", err)
+	}
+	records, err := csv.NewReader(&b).ReadAll()
+	if err != nil {
+		t.Fatalf("Could not read csv: %s", err)
+	}
+	if !reflect.DeepEqual(records[0], csvHeader) {
+		t.Errorf("Header wants %v but %v", csvHeader, records[0])
+	}
+	want := []string{"testdata/ii.jpg", "IFD0", "0x010F", "Make", "ASCII", "Canon"}
+	var found bool
+	for _, r := range records[1:] {
+		if r[0] != "testdata/ii.jpg" {
+			t.Errorf("file wants testdata/ii.jpg but %s", r[0])
+		}
+		found = found || reflect.DeepEqual(r, want)
+	}
+	if !found {
+		t.Errorf("Records wants %v", want)
+	}
+}
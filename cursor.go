@@ -0,0 +1,53 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// cursor reads values from a byte slice in the endian.
+// It advances on each read and returns an error instead of reading out of the slice.
+type cursor struct {
+	b      []byte
+	offset int
+	endian binary.ByteOrder
+}
+
+func newCursor(b []byte, offset int, endian binary.ByteOrder) (*cursor, error) {
+	if offset < 0 || offset > len(b) {
+		return nil, fmt.Errorf("Offset 0x%x is out of %d bytes", offset, len(b))
+	}
+	return &cursor{b, offset, endian}, nil
+}
+
+// Offset returns the current position in the slice.
+func (c *cursor) Offset() int {
+	return c.offset
+}
+
+func (c *cursor) ReadUint16() (uint16, error) {
+	b, err := c.ReadBytes(2)
+	if err != nil {
+		return 0, err
+	}
+	return c.endian.Uint16(b), nil
+}
+
+func (c *cursor) ReadUint32() (uint32, error) {
+	b, err := c.ReadBytes(4)
+	if err != nil {
+		return 0, err
+	}
+	return c.endian.Uint32(b), nil
+}
+
+// ReadBytes returns the next n bytes.
+// The returned slice shares the underlying array.
+func (c *cursor) ReadBytes(n int) ([]byte, error) {
+	if n < 0 || n > len(c.b)-c.offset {
+		return nil, fmt.Errorf("Could not read %d bytes at 0x%x: %d bytes left", n, c.offset, len(c.b)-c.offset)
+	}
+	b := c.b[c.offset : c.offset+n]
+	c.offset += n
+	return b, nil
+}
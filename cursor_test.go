@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func TestCursor(t *testing.T) {
+	c, err := newCursor([]byte{0xff, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06}, 1, binary.BigEndian)
+	if err != nil {
+		t.Fatalf("newCursor error: %s", err)
+	}
+	if v, err := c.ReadUint16(); err != nil || v != 0x0102 {
+		t.Errorf("ReadUint16 wants 0x0102 but 0x%x, %v", v, err)
+	}
+	if v, err := c.ReadUint32(); err != nil || v != 0x03040506 {
+		t.Errorf("ReadUint32 wants 0x03040506 but 0x%x, %v", v, err)
+	}
+	if c.Offset() != 7 {
+		t.Errorf("Offset wants 7 but %d", c.Offset())
+	}
+	if _, err := c.ReadBytes(1); err == nil {
+		t.Errorf("ReadBytes past the end wants error")
+	}
+	if c.Offset() != 7 {
+		t.Errorf("Offset wants not advanced on error but %d", c.Offset())
+	}
+}
+
+func TestCursor_LittleEndian(t *testing.T) {
+	c, err := newCursor([]byte{0x01, 0x02, 0x03, 0x04}, 0, binary.LittleEndian)
+	if err != nil {
+		t.Fatalf("newCursor error: %s", err)
+	}
+	if v, err := c.ReadUint32(); err != nil || v != 0x04030201 {
+		t.Errorf("ReadUint32 wants 0x04030201 but 0x%x, %v", v, err)
+	}
+	if _, err := c.ReadUint16(); err == nil {
+		t.Errorf("ReadUint16 past the end wants error")
+	}
+}
+
+func TestNewCursor_OutOfRange(t *testing.T) {
+	for _, offset := range []int{-1, 5} {
+		if _, err := newCursor(make([]byte, 4), offset, binary.BigEndian); err == nil {
+			t.Errorf("newCursor at %d wants error", offset)
+		}
+	}
+	c, err := newCursor(make([]byte, 4), 4, binary.BigEndian)
+	if err != nil {
+		t.Fatalf("newCursor at the end error: %s", err)
+	}
+	if _, err := c.ReadBytes(-1); err == nil {
+		t.Errorf("ReadBytes of negative length wants error")
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	tagDateTime            = 0x0132
+	tagOffsetTime          = 0x9010
+	tagOffsetTimeOriginal  = 0x9011
+	tagOffsetTimeDigitized = 0x9012
+	tagDateTimeOriginal    = 0x9003
+	tagDateTimeDigitized   = 0x9004
+	tagSubSecTime          = 0x9290
+	tagSubSecTimeOriginal  = 0x9291
+	tagSubSecTimeDigitized = 0x9292
+)
+
+const dateTimeLayout = "2006:01:02 15:04:05"
+
+// Timestamps represents the date and time tags.
+// A field is zero if the tag is not present or malformed.
+type Timestamps struct {
+	DateTime          time.Time
+	DateTimeOriginal  time.Time
+	DateTimeDigitized time.Time
+}
+
+// Timestamps returns the date and time tags with SubSecTime and OffsetTime applied.
+// If OffsetTime is not present, the wall clock is taken as UTC,
+// so that the result does not depend on the time zone of the machine.
+func (a *APP1) Timestamps() Timestamps {
+	return Timestamps{
+		DateTime:          timestamp(a.IFD0.Find(tagDateTime), a.ExifIFD.Find(tagSubSecTime), a.ExifIFD.Find(tagOffsetTime)),
+		DateTimeOriginal:  timestamp(a.ExifIFD.Find(tagDateTimeOriginal), a.ExifIFD.Find(tagSubSecTimeOriginal), a.ExifIFD.Find(tagOffsetTimeOriginal)),
+		DateTimeDigitized: timestamp(a.ExifIFD.Find(tagDateTimeDigitized), a.ExifIFD.Find(tagSubSecTimeDigitized), a.ExifIFD.Find(tagOffsetTimeDigitized)),
+	}
+}
+
+// dateTimeSubElements returns the SubSecTime and OffsetTime elements of the date time tag,
+// or nil if the tag is not a date time tag.
+func (a *APP1) dateTimeSubElements(tag uint16) (subSecTime, offsetTime *IFDElement) {
+	switch tag {
+	case tagDateTime:
+		return a.ExifIFD.Find(tagSubSecTime), a.ExifIFD.Find(tagOffsetTime)
+	case tagDateTimeOriginal:
+		return a.ExifIFD.Find(tagSubSecTimeOriginal), a.ExifIFD.Find(tagOffsetTimeOriginal)
+	case tagDateTimeDigitized:
+		return a.ExifIFD.Find(tagSubSecTimeDigitized), a.ExifIFD.Find(tagOffsetTimeDigitized)
+	}
+	return nil, nil
+}
+
+func timestamp(dateTime, subSecTime, offsetTime *IFDElement) time.Time {
+	if dateTime == nil || dateTime.Count == 0 {
+		return time.Time{}
+	}
+	s, err := dateTime.ASCII()
+	if err != nil {
+		return time.Time{}
+	}
+	// the time zone is unknown without OffsetTime
+	loc := time.UTC
+	if offsetTime != nil {
+		if o, err := offsetTime.ASCII(); err == nil {
+			if l, ok := parseOffsetTime(o); ok {
+				loc = l
+			}
+		}
+	}
+	t, err := time.ParseInLocation(dateTimeLayout, strings.TrimSpace(s), loc)
+	if err != nil {
+		return time.Time{}
+	}
+	if subSecTime != nil {
+		if ss, err := subSecTime.ASCII(); err == nil {
+			if d, ok := parseSubSecTime(ss); ok {
+				t = t.Add(d)
+			}
+		}
+	}
+	return t
+}
+
+// parseOffsetTime parses the offset such as "+09:00".
+func parseOffsetTime(s string) (*time.Location, bool) {
+	t, err := time.Parse("-07:00", strings.TrimSpace(s))
+	if err != nil {
+		return nil, false
+	}
+	_, offset := t.Zone()
+	return time.FixedZone(s, offset), true
+}
+
+// parseSubSecTime parses the fractional digits of a second, e.g. "34" is 340ms.
+func parseSubSecTime(s string) (time.Duration, bool) {
+	s = strings.TrimRight(s, " ")
+	if s == "" || len(s) > 9 {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(s, 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	for i := len(s); i < 9; i++ {
+		n *= 10
+	}
+	return time.Duration(n), true
+}
+
+// SubSecTime returns the fractional seconds of DateTime.
+func (a *APP1) SubSecTime() (time.Duration, bool) {
+	return findSubSecTime(a.ExifIFD, tagSubSecTime)
+}
+
+// SubSecTimeOriginal returns the fractional seconds of DateTimeOriginal.
+func (a *APP1) SubSecTimeOriginal() (time.Duration, bool) {
+	return findSubSecTime(a.ExifIFD, tagSubSecTimeOriginal)
+}
+
+// SubSecTimeDigitized returns the fractional seconds of DateTimeDigitized.
+func (a *APP1) SubSecTimeDigitized() (time.Duration, bool) {
+	return findSubSecTime(a.ExifIFD, tagSubSecTimeDigitized)
+}
+
+func findSubSecTime(d *IFD, tag uint16) (time.Duration, bool) {
+	s, ok := findASCII(d, tag)
+	if !ok {
+		return 0, false
+	}
+	return parseSubSecTime(s)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAPP1_Timestamps(t *testing.T) {
+	h := decodeTestdata(t, "ii.jpg")
+	ts := h.APP1.Timestamps()
+	want := time.Date(2018, 9, 22, 10, 11, 12, 340*int(time.Millisecond), time.FixedZone("+09:00", 9*60*60))
+	if !ts.DateTimeOriginal.Equal(want) {
+		t.Errorf("DateTimeOriginal wants %s but %s", want, ts.DateTimeOriginal)
+	}
+	if _, offset := ts.DateTimeOriginal.Zone(); offset != 9*60*60 {
+		t.Errorf("Offset wants +09:00 but %d", offset)
+	}
+}
+
+func TestAPP1_Timestamps_NoOffsetTime(t *testing.T) {
+	local := time.Local
+	time.Local = time.FixedZone("test", -5*60*60)
+	defer func() { time.Local = local }()
+	h := decodeTestdata(t, "ii.jpg")
+	ts := h.APP1.Timestamps()
+	want := time.Date(2018, 9, 22, 10, 11, 12, 0, time.UTC)
+	if !ts.DateTime.Equal(want) || ts.DateTime.Location() != time.UTC {
+		t.Errorf("DateTime wants %s but %s", want, ts.DateTime)
+	}
+	if !ts.DateTimeDigitized.Equal(want) {
+		t.Errorf("DateTimeDigitized wants %s but %s", want, ts.DateTimeDigitized)
+	}
+}
+
+func TestParseSubSecTime(t *testing.T) {
+	for _, c := range []struct {
+		s    string
+		want time.Duration
+		ok   bool
+	}{
+		{"34", 340 * time.Millisecond, true},
+		{"5 ", 500 * time.Millisecond, true},
+		{"123456789", 123456789, true},
+		{"", 0, false},
+		{"1234567890", 0, false},
+		{"ab", 0, false},
+	} {
+		got, ok := parseSubSecTime(c.s)
+		if got != c.want || ok != c.ok {
+			t.Errorf("parseSubSecTime(%q) wants %v, %v but %v, %v", c.s, c.want, c.ok, got, ok)
+		}
+	}
+}
+
+func TestAPP1_SubSecTimeOriginal(t *testing.T) {
+	a := decodeTestdata(t, "ii.jpg").APP1
+	if v, ok := a.SubSecTimeOriginal(); !ok || v != 340*time.Millisecond {
+		t.Errorf("SubSecTimeOriginal wants 340ms but %v, %v", v, ok)
+	}
+	if v, ok := a.SubSecTime(); ok {
+		t.Errorf("SubSecTime wants false if not present but %v", v)
+	}
+	a.SetElement(ExifIFDKind, newASCIIElement(tagSubSecTimeDigitized, "05"))
+	if v, ok := a.SubSecTimeDigitized(); !ok || v != 50*time.Millisecond {
+		t.Errorf("SubSecTimeDigitized wants 50ms but %v, %v", v, ok)
+	}
+}
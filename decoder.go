@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+)
+
+// Decoder decodes JPEG headers reusing the buffer across calls.
+//
+// The header returned by Decode refers to the buffer,
+// so it is valid only until the next call of Decode.
+// A Decoder is not safe for concurrent use. Use one Decoder per goroutine,
+// or AcquireDecoder and ReleaseDecoder to share them via a pool.
+type Decoder struct {
+	Options DecodeOptions
+	// Logger receives the warnings and debug logs of decoding.
+	// Default to a no-op logger if nil.
+	Logger *slog.Logger
+	buf    []byte
+}
+
+// DecodeOptions represents the options of decoding.
+type DecodeOptions struct {
+	// DetectEndianMismatch logs a warning if the byte order mark looks inconsistent with the offsets.
+	// The declared endian is still used.
+	DetectEndianMismatch bool
+
+	// MaxSegmentSize is the maximum bytes allocated by a single read,
+	// or by the data reassembled from multiple segments such as the extended XMP.
+	// Default to defaultMaxSegmentSize if zero.
+	MaxSegmentSize int
+
+	// DecodeValues sets IFDElement.Decoded of each element on parse.
+	DecodeValues bool
+
+	// DuplicateTags is the policy for a tag appearing more than once in an IFD.
+	// Default to FirstWins.
+	DuplicateTags DuplicateTagPolicy
+
+	logger *slog.Logger
+}
+
+var discardLogger = slog.New(slog.DiscardHandler)
+
+func (o DecodeOptions) log() *slog.Logger {
+	if o.logger != nil {
+		return o.logger
+	}
+	return discardLogger
+}
+
+const defaultMaxSegmentSize = 4 << 20
+
+func (o DecodeOptions) maxSegmentSize() int {
+	if o.MaxSegmentSize > 0 {
+		return o.MaxSegmentSize
+	}
+	return defaultMaxSegmentSize
+}
+
+// Decode parses the JPEG header in the reader.
+func (d *Decoder) Decode(r io.Reader) (*JPEGHeader, error) {
+	d.buf = d.buf[:0]
+	opts := d.Options
+	opts.logger = d.Logger
+	h, err := parseJPEGHeader(r, d.readBytes, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Could not parse JPEG header: %s", err)
+	}
+	return h, nil
+}
+
+// readBytes reads the bytes into the buffer.
+// If the buffer is full, a larger one is allocated and the old one is left to the slices already returned.
+func (d *Decoder) readBytes(r io.Reader, length int) ([]byte, error) {
+	if len(d.buf)+length > cap(d.buf) {
+		size := 2 * cap(d.buf)
+		if size < length {
+			size = length
+		}
+		if size < 4096 {
+			size = 4096
+		}
+		d.buf = make([]byte, 0, size)
+	}
+	b := d.buf[len(d.buf) : len(d.buf)+length]
+	if n, err := io.ReadFull(r, b); err != nil {
+		return nil, fmt.Errorf("Could not read %d bytes: got %d bytes: %s", length, n, err)
+	}
+	d.buf = d.buf[:len(d.buf)+length]
+	if d.Logger != nil {
+		d.Logger.Debug("Read bytes", "length", length)
+	}
+	return b, nil
+}
+
+var decoderPool = sync.Pool{
+	New: func() interface{} { return &Decoder{} },
+}
+
+// AcquireDecoder returns a Decoder from the pool.
+func AcquireDecoder() *Decoder {
+	return decoderPool.Get().(*Decoder)
+}
+
+// ReleaseDecoder returns the Decoder to the pool.
+// The headers decoded by it must not be used after release.
+// The options and logger are reset, so the next user gets the defaults.
+func ReleaseDecoder(d *Decoder) {
+	d.Options = DecodeOptions{}
+	d.Logger = nil
+	decoderPool.Put(d)
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"log/slog"
+	"reflect"
+	"testing"
+)
+
+func TestDecoder_Decode(t *testing.T) {
+	b := loadTestdata(t, "ii.jpg")
+	var d Decoder
+	for i := 0; i < 2; i++ {
+		h, err := d.Decode(bytes.NewReader(b))
+		if err != nil {
+			t.Fatalf("Decode error: %s", err)
+		}
+		if v, ok := h.APP1.Make(); !ok || v != "Canon" {
+			t.Errorf("Make wants Canon but %q", v)
+		}
+	}
+}
+
+func TestDecoder_MaxSegmentSize(t *testing.T) {
+	d := Decoder{Options: DecodeOptions{MaxSegmentSize: 16}}
+	if _, err := d.Decode(bytes.NewReader(loadTestdata(t, "ii.jpg"))); err == nil {
+		t.Errorf("Decode wants error")
+	}
+}
+
+func TestReleaseDecoder(t *testing.T) {
+	d := AcquireDecoder()
+	d.Options.MaxSegmentSize = 16
+	d.Logger = slog.Default()
+	ReleaseDecoder(d)
+	if d.Options != (DecodeOptions{}) {
+		t.Errorf("Options wants zero but %+v", d.Options)
+	}
+	if d.Logger != nil {
+		t.Errorf("Logger wants nil")
+	}
+}
+
+func BenchmarkDecode(b *testing.B) {
+	data := loadTestdata(b, "ii.jpg")
+	b.Run("Decode", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := Decode(data); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Decoder", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			d := AcquireDecoder()
+			if _, err := d.Decode(bytes.NewReader(data)); err != nil {
+				b.Fatal(err)
+			}
+			ReleaseDecoder(d)
+		}
+	})
+}
+
+func TestDecoder_DecodeValues(t *testing.T) {
+	d := Decoder{Options: DecodeOptions{DecodeValues: true}}
+	h, err := d.Decode(bytes.NewReader(loadTestdata(t, "ii.jpg")))
+	if err != nil {
+		t.Fatalf("Decode error: %s", err)
+	}
+	a := h.APP1
+	for _, c := range []struct {
+		kind IFDKind
+		tag  uint16
+		want interface{}
+	}{
+		{IFD0Kind, tagMake, "Canon"},
+		{IFD0Kind, tagOrientation, uint16(6)},
+		{ExifIFDKind, tagExposureTime, 0.004},
+		{GPSIFDKind, tagGPSLatitude, []float64{35, 40, 30}},
+	} {
+		if v := a.IFD(c.kind).Find(c.tag).Decoded; !reflect.DeepEqual(v, c.want) {
+			t.Errorf("Decoded of %s 0x%04X wants %#v but %#v", c.kind, c.tag, c.want, v)
+		}
+	}
+
+	h, err = Decode(loadTestdata(t, "ii.jpg"))
+	if err != nil {
+		t.Fatalf("Decode error: %s", err)
+	}
+	if v := h.APP1.IFD0.Find(tagMake).Decoded; v != nil {
+		t.Errorf("Decoded wants nil without DecodeValues but %#v", v)
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+const (
+	tagDNGVersion         = 0xC612
+	tagDNGBackwardVersion = 0xC613
+	tagUniqueCameraModel  = 0xC614
+	tagLinearizationTable = 0xC618
+	tagOpcodeList1        = 0xC740
+	tagOpcodeList2        = 0xC741
+	tagOpcodeList3        = 0xC74E
+)
+
+// DNGVersion returns the DNG specification version, e.g. "1.4.0.0".
+func (a *APP1) DNGVersion() (string, bool) {
+	return findDNGVersion(a.IFD0, tagDNGVersion)
+}
+
+// DNGBackwardVersion returns the oldest DNG specification version which the file is compatible with.
+func (a *APP1) DNGBackwardVersion() (string, bool) {
+	return findDNGVersion(a.IFD0, tagDNGBackwardVersion)
+}
+
+// UniqueCameraModel returns the unique non-localized name of the camera model.
+func (a *APP1) UniqueCameraModel() (string, bool) {
+	return findTrimmedASCII(a.IFD0, tagUniqueCameraModel)
+}
+
+func findDNGVersion(d *IFD, tag uint16) (string, bool) {
+	b, ok := findBytes(d, tag)
+	if !ok || len(b) != 4 {
+		return "", false
+	}
+	parts := make([]string, len(b))
+	for i, v := range b {
+		parts[i] = fmt.Sprintf("%d", v)
+	}
+	return strings.Join(parts, "."), true
+}
+
+// LinearizationTable returns the curve which maps the stored raw values into linear values.
+// The IFD is of the raw image, i.e. the 0th IFD or one of SubIFDs.
+func (a *APP1) LinearizationTable(d *IFD) ([]uint16, bool) {
+	e := d.Find(tagLinearizationTable)
+	if e == nil {
+		return nil, false
+	}
+	v, err := e.Uint16s(a.Endian)
+	if err != nil || len(v) == 0 {
+		return nil, false
+	}
+	return v, true
+}
+
+// DNGOpcodeID represents the kind of a DNG opcode.
+type DNGOpcodeID uint32
+
+var dngOpcodeNames = map[DNGOpcodeID]string{
+	1:  "WarpRectilinear",
+	2:  "WarpFisheye",
+	3:  "FixVignetteRadial",
+	4:  "FixBadPixelsConstant",
+	5:  "FixBadPixelsList",
+	6:  "TrimBounds",
+	7:  "MapTable",
+	8:  "MapPolynomial",
+	9:  "GainMap",
+	10: "DeltaPerRow",
+	11: "DeltaPerColumn",
+	12: "ScalePerRow",
+	13: "ScalePerColumn",
+	14: "WarpRectilinear2",
+}
+
+func (id DNGOpcodeID) String() string {
+	if s, ok := dngOpcodeNames[id]; ok {
+		return s
+	}
+	return fmt.Sprintf("DNGOpcodeID(%d)", uint32(id))
+}
+
+// DNGOpcode is an entry of an opcode list.
+// The parameters are left as raw bytes in big endian, since the layout depends on the opcode.
+type DNGOpcode struct {
+	ID      DNGOpcodeID
+	Version string // DNG version of the opcode, e.g. "1.3.0.0"
+	Flags   uint32 // bit 0 is optional and bit 1 can be skipped for preview
+	Params  []byte
+}
+
+// OpcodeList returns the opcodes of OpcodeList1, OpcodeList2 or OpcodeList3 by the number 1, 2 or 3,
+// which are applied to the raw image at the stages of the processing.
+// The IFD is of the raw image, i.e. the 0th IFD or one of SubIFDs.
+// The raw bytes are returned even if the list could not be decoded, in which case the opcodes are nil.
+func (a *APP1) OpcodeList(d *IFD, n int) (opcodes []DNGOpcode, raw []byte, ok bool) {
+	tags := map[int]uint16{1: tagOpcodeList1, 2: tagOpcodeList2, 3: tagOpcodeList3}
+	tag, ok := tags[n]
+	if !ok {
+		return nil, nil, false
+	}
+	e := d.Find(tag)
+	if e == nil || e.checkLength() != nil {
+		return nil, nil, false
+	}
+	raw = e.Value[:e.Length()]
+	opcodes, err := parseDNGOpcodeList(raw)
+	if err != nil {
+		return nil, raw, true
+	}
+	return opcodes, raw, true
+}
+
+// parseDNGOpcodeList parses an opcode list, which is always big endian regardless of the TIFF.
+func parseDNGOpcodeList(b []byte) ([]DNGOpcode, error) {
+	c, err := newCursor(b, 0, binary.BigEndian)
+	if err != nil {
+		return nil, err
+	}
+	count, err := c.ReadUint32()
+	if err != nil {
+		return nil, fmt.Errorf("Could not read opcode count: %s", err)
+	}
+	// each opcode needs 16 bytes of the header
+	if int64(count)*16 > int64(len(b)-c.Offset()) {
+		return nil, fmt.Errorf("Opcode list has %d opcodes but only %d bytes remain", count, len(b)-c.Offset())
+	}
+	opcodes := make([]DNGOpcode, count)
+	for i := range opcodes {
+		header, err := c.ReadBytes(16)
+		if err != nil {
+			return nil, fmt.Errorf("Could not read opcode #%d: %s", i, err)
+		}
+		version := header[4:8]
+		size := binary.BigEndian.Uint32(header[12:16])
+		if int64(size) > int64(len(b)-c.Offset()) {
+			return nil, fmt.Errorf("Opcode #%d has %d bytes of parameters but only %d bytes remain", i, size, len(b)-c.Offset())
+		}
+		params, err := c.ReadBytes(int(size))
+		if err != nil {
+			return nil, fmt.Errorf("Could not read parameters of opcode #%d: %s", i, err)
+		}
+		opcodes[i] = DNGOpcode{
+			ID:      DNGOpcodeID(binary.BigEndian.Uint32(header[0:4])),
+			Version: fmt.Sprintf("%d.%d.%d.%d", version[0], version[1], version[2], version[3]),
+			Flags:   binary.BigEndian.Uint32(header[8:12]),
+			Params:  params,
+		}
+	}
+	return opcodes, nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestAPP1_DNGVersion(t *testing.T) {
+	a := newTestAPP1(map[uint16]string{tagUniqueCameraModel: "Canon EOS 5D Mark III "})
+	if _, ok := a.DNGVersion(); ok {
+		t.Errorf("DNGVersion wants false if not present")
+	}
+	a.SetElement(IFD0Kind, newElement(tagDNGVersion, 1, 4, []byte{1, 4, 0, 0}))
+	a.SetElement(IFD0Kind, newElement(tagDNGBackwardVersion, 1, 4, []byte{1, 1, 0, 0}))
+	if v, ok := a.DNGVersion(); !ok || v != "1.4.0.0" {
+		t.Errorf("DNGVersion wants 1.4.0.0 but %q, %v", v, ok)
+	}
+	if v, ok := a.DNGBackwardVersion(); !ok || v != "1.1.0.0" {
+		t.Errorf("DNGBackwardVersion wants 1.1.0.0 but %q, %v", v, ok)
+	}
+	if v, ok := a.UniqueCameraModel(); !ok || v != "Canon EOS 5D Mark III" {
+		t.Errorf("UniqueCameraModel wants Canon EOS 5D Mark III but %q, %v", v, ok)
+	}
+	a.SetElement(IFD0Kind, newElement(tagDNGVersion, 1, 2, []byte{1, 4}))
+	if v, ok := a.DNGVersion(); ok {
+		t.Errorf("DNGVersion of 2 bytes wants false but %q", v)
+	}
+}
+
+func TestAPP1_LinearizationTable(t *testing.T) {
+	a := newTestAPP1(nil)
+	if _, ok := a.LinearizationTable(a.IFD0); ok {
+		t.Errorf("LinearizationTable wants false if not present")
+	}
+	b := make([]byte, 6)
+	for i, v := range []uint16{0, 100, 4095} {
+		a.Endian.PutUint16(b[i*2:], v)
+	}
+	a.SetElement(IFD0Kind, newElement(tagLinearizationTable, 3, 3, b))
+	if v, ok := a.LinearizationTable(a.IFD0); !ok || len(v) != 3 || v[2] != 4095 {
+		t.Errorf("LinearizationTable wants [0 100 4095] but %v, %v", v, ok)
+	}
+}
+
+func TestAPP1_OpcodeList(t *testing.T) {
+	// the opcode list is big endian even in the little endian TIFF
+	var b bytes.Buffer
+	for _, v := range []uint32{1, 9, 0x01030000, 1, 4, 0xdeadbeef} {
+		binary.Write(&b, binary.BigEndian, v)
+	}
+	a := newTestAPP1(nil)
+	a.SetElement(IFD0Kind, newUndefinedElement(tagOpcodeList2, b.Bytes()))
+	if _, _, ok := a.OpcodeList(a.IFD0, 1); ok {
+		t.Errorf("OpcodeList1 wants false if not present")
+	}
+	if _, _, ok := a.OpcodeList(a.IFD0, 4); ok {
+		t.Errorf("OpcodeList4 wants false")
+	}
+	opcodes, raw, ok := a.OpcodeList(a.IFD0, 2)
+	if !ok || !bytes.Equal(raw, b.Bytes()) {
+		t.Fatalf("OpcodeList2 wants the raw bytes but %v, %v", raw, ok)
+	}
+	want := DNGOpcode{ID: 9, Version: "1.3.0.0", Flags: 1, Params: []byte{0xde, 0xad, 0xbe, 0xef}}
+	if len(opcodes) != 1 || opcodes[0].ID != want.ID || opcodes[0].Version != want.Version ||
+		opcodes[0].Flags != want.Flags || !bytes.Equal(opcodes[0].Params, want.Params) {
+		t.Errorf("OpcodeList2 wants %+v but %+v", want, opcodes)
+	}
+	if s := opcodes[0].ID.String(); s != "GainMap" {
+		t.Errorf("String wants GainMap but %s", s)
+	}
+	if s := DNGOpcodeID(99).String(); s != "DNGOpcodeID(99)" {
+		t.Errorf("String wants DNGOpcodeID(99) but %s", s)
+	}
+
+	truncated := b.Bytes()[:b.Len()-2]
+	a.SetElement(IFD0Kind, newUndefinedElement(tagOpcodeList3, truncated))
+	opcodes, raw, ok = a.OpcodeList(a.IFD0, 3)
+	if !ok || opcodes != nil || !bytes.Equal(raw, truncated) {
+		t.Errorf("OpcodeList3 of truncated wants the raw bytes without opcodes but %+v, %v", opcodes, ok)
+	}
+}
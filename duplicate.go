@@ -0,0 +1,57 @@
+package main
+
+import "fmt"
+
+// DuplicateTagPolicy determines which element is used if a tag appears twice in an IFD,
+// which is written by some malformed writers.
+type DuplicateTagPolicy int
+
+const (
+	// FirstWins uses the first element of the tag.
+	// All elements are kept for round-trip and IFD.Find returns the first one.
+	FirstWins DuplicateTagPolicy = iota
+	// LastWins uses the last element of the tag.
+	// The preceding elements of the same tag are removed on parse.
+	LastWins
+)
+
+// keepLastDuplicates removes the elements followed by another element of the same tag.
+func (d *IFD) keepLastDuplicates() {
+	if d == nil {
+		return
+	}
+	last := make(map[uint16]int)
+	for i, e := range d.Elements {
+		last[e.Tag] = i
+	}
+	var elements []*IFDElement
+	for i, e := range d.Elements {
+		if last[e.Tag] == i {
+			elements = append(elements, e)
+		}
+	}
+	d.Elements = elements
+}
+
+// validateDuplicates reports the tags which appear more than once in an IFD.
+func (a *APP1) validateDuplicates() []Finding {
+	var findings []Finding
+	for _, kind := range []IFDKind{IFD0Kind, ExifIFDKind, GPSIFDKind, InteroperabilityIFDKind, IFD1Kind} {
+		d := a.IFD(kind)
+		if d == nil {
+			continue
+		}
+		counts := make(map[uint16]int)
+		for _, e := range d.Elements {
+			counts[e.Tag]++
+		}
+		reported := make(map[uint16]bool)
+		for _, e := range d.Elements {
+			if counts[e.Tag] > 1 && !reported[e.Tag] {
+				findings = append(findings, Finding{kind, e.Tag, fmt.Sprintf("Tag appears %d times", counts[e.Tag])})
+				reported[e.Tag] = true
+			}
+		}
+	}
+	return findings
+}
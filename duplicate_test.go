@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// duplicateMakeJPEG returns ii.jpg with the tag of Model replaced with Make.
+func duplicateMakeJPEG(t *testing.T) []byte {
+	b := append([]byte{}, loadTestdata(t, "ii.jpg")...)
+	// the 2nd element of the 0th IFD
+	i := 12 + 8 + 2 + 12
+	if tag := binary.LittleEndian.Uint16(b[i:]); tag != tagModel {
+		t.Fatalf("element wants Model but 0x%04X", tag)
+	}
+	binary.LittleEndian.PutUint16(b[i:], tagMake)
+	return b
+}
+
+func TestDecodeOptions_DuplicateTags(t *testing.T) {
+	b := duplicateMakeJPEG(t)
+	for _, c := range []struct {
+		policy   DuplicateTagPolicy
+		want     string
+		elements int
+	}{
+		{FirstWins, "Canon", 9},
+		{LastWins, "Canon EOS 5D Mark III", 8},
+	} {
+		d := Decoder{Options: DecodeOptions{DuplicateTags: c.policy}}
+		h, err := d.Decode(bytes.NewReader(b))
+		if err != nil {
+			t.Fatalf("Decode error: %s", err)
+		}
+		if v, ok := h.APP1.Make(); !ok || v != c.want {
+			t.Errorf("Make of policy %d wants %s but %q", c.policy, c.want, v)
+		}
+		if n := len(h.APP1.IFD0.Elements); n != c.elements {
+			t.Errorf("Elements of policy %d wants %d but %d", c.policy, c.elements, n)
+		}
+	}
+}
+
+func TestAPP1_validateDuplicates(t *testing.T) {
+	h, err := Decode(duplicateMakeJPEG(t))
+	if err != nil {
+		t.Fatalf("Decode error: %s", err)
+	}
+	findings := h.APP1.validateDuplicates()
+	if len(findings) != 1 || findings[0].String() != "IFD0 Make: Tag appears 2 times" {
+		t.Errorf("validateDuplicates wants Make but %v", findings)
+	}
+	if findings := decodeTestdata(t, "ii.jpg").APP1.validateDuplicates(); findings != nil {
+		t.Errorf("validateDuplicates wants nil but %v", findings)
+	}
+}
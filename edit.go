@@ -0,0 +1,60 @@
+package main
+
+import "fmt"
+
+// SetElement sets the element to the IFD of the kind.
+// The IFD is created if it does not exist.
+func (a *APP1) SetElement(kind IFDKind, e *IFDElement) error {
+	d := a.IFD(kind)
+	if d == nil {
+		d = &IFD{}
+		switch kind {
+		case IFD0Kind:
+			a.IFD0 = d
+		case ExifIFDKind:
+			a.ExifIFD = d
+		case GPSIFDKind:
+			a.GPSIFD = d
+		case InteroperabilityIFDKind:
+			a.InteroperabilityIFD = d
+		case IFD1Kind:
+			a.IFD1 = d
+		default:
+			return fmt.Errorf("Unknown IFD kind: %s", kind)
+		}
+	}
+	d.Set(e)
+	return nil
+}
+
+// SetExposureTime sets ExposureTime in seconds to the Exif IFD.
+func (a *APP1) SetExposureTime(numerator, denominator uint32) error {
+	return a.SetElement(ExifIFDKind, newRationalElement(tagExposureTime, []Rational{{numerator, denominator}}, a.Endian))
+}
+
+// SetFNumber sets FNumber to the Exif IFD.
+func (a *APP1) SetFNumber(numerator, denominator uint32) error {
+	return a.SetElement(ExifIFDKind, newRationalElement(tagFNumber, []Rational{{numerator, denominator}}, a.Endian))
+}
+
+// RemoveElement removes the element of the tag from the IFD of the kind.
+// If the tag is a link to an IFD or the thumbnail, the linked block is removed as well.
+// It returns true if anything was removed.
+func (a *APP1) RemoveElement(kind IFDKind, tag uint16) bool {
+	removed := a.IFD(kind).Remove(tag)
+	switch {
+	case kind == IFD0Kind && tag == tagExifIFDPointer && a.ExifIFD != nil:
+		a.ExifIFD, a.InteroperabilityIFD = nil, nil
+		removed = true
+	case kind == IFD0Kind && tag == tagGPSInfoIFDPointer && a.GPSIFD != nil:
+		a.GPSIFD = nil
+		removed = true
+	case kind == ExifIFDKind && tag == tagInteroperabilityIFDPointer && a.InteroperabilityIFD != nil:
+		a.InteroperabilityIFD = nil
+		removed = true
+	case kind == IFD1Kind && (tag == tagJPEGInterchangeFormat || tag == tagJPEGInterchangeFormatLength) && a.thumbnail != nil:
+		a.thumbnail = nil
+		removed = true
+	}
+	return removed
+}
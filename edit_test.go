@@ -0,0 +1,39 @@
+package main
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func TestAPP1_SetElement(t *testing.T) {
+	a := &APP1{Endian: binary.LittleEndian}
+	for _, kind := range []IFDKind{IFD0Kind, ExifIFDKind, GPSIFDKind, InteroperabilityIFDKind, IFD1Kind} {
+		if err := a.SetElement(kind, newShortElement(0x0001, 1, a.Endian)); err != nil {
+			t.Fatalf("SetElement(%s) error: %s", kind, err)
+		}
+		if a.IFD(kind).Find(0x0001) == nil {
+			t.Errorf("SetElement wants the element in the created %s", kind)
+		}
+	}
+	if err := a.SetElement(IFDKind(99), newShortElement(0x0001, 1, a.Endian)); err == nil {
+		t.Errorf("SetElement of unknown kind wants error")
+	}
+}
+
+func TestAPP1_SetExposureTime(t *testing.T) {
+	h := decodeTestdata(t, "ii.jpg")
+	a := h.APP1
+	if err := a.SetExposureTime(1, 60); err != nil {
+		t.Fatalf("SetExposureTime error: %s", err)
+	}
+	if err := a.SetFNumber(40, 10); err != nil {
+		t.Fatalf("SetFNumber error: %s", err)
+	}
+	decoded := reencode(t, h).APP1
+	if v, ok := decoded.ExposureTime(); !ok || v != (Rational{1, 60}) {
+		t.Errorf("ExposureTime wants 1/60 but %v, %v", v, ok)
+	}
+	if v, ok := decoded.FNumber(); !ok || v != 4 {
+		t.Errorf("FNumber wants 4 but %f, %v", v, ok)
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// maxPlausibleElementCount is the maximum number of elements in an APP1,
+// which is limited by 64KB segment and 12 bytes per element.
+const maxPlausibleElementCount = 0xffff / 12
+
+// detectEndianMismatch returns a warning if the offset or element count of the 0th IFD
+// is implausible in the declared endian but plausible in the opposite endian.
+// Some third-party writers emit the byte order mark inconsistent with the offsets.
+func detectEndianMismatch(b []byte, declared binary.ByteOrder) (string, bool) {
+	opposite := binary.ByteOrder(binary.BigEndian)
+	if declared == binary.BigEndian {
+		opposite = binary.LittleEndian
+	}
+	if plausibleIFD0(b, declared) || !plausibleIFD0(b, opposite) {
+		return "", false
+	}
+	return fmt.Sprintf("0th IFD is implausible in %s but plausible in %s: header is % x", declared, opposite, b[0:8]), true
+}
+
+func plausibleIFD0(b []byte, endian binary.ByteOrder) bool {
+	if len(b) < 8 {
+		return false
+	}
+	offset := endian.Uint32(b[4:8])
+	if offset < 8 || int64(offset)+2 > int64(len(b)) {
+		return false
+	}
+	count := int(endian.Uint16(b[offset : offset+2]))
+	return count > 0 && count <= maxPlausibleElementCount && int(offset)+2+count*12+4 <= len(b)
+}
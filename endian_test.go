@@ -0,0 +1,38 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// mismatchedTIFF is a TIFF which declares II but the offsets are big endian.
+var mismatchedTIFF = []byte("II\x2a\x00\x00\x00\x00\x08" +
+	"\x00\x01" +
+	"\x01\x12\x00\x03\x00\x00\x00\x01\x00\x06\x00\x00" +
+	"\x00\x00\x00\x00")
+
+func TestDetectEndianMismatch(t *testing.T) {
+	if warning, ok := detectEndianMismatch(mismatchedTIFF, binary.LittleEndian); !ok || !strings.Contains(warning, "plausible in BigEndian") {
+		t.Errorf("detectEndianMismatch wants a warning but %q, %v", warning, ok)
+	}
+	if warning, ok := detectEndianMismatch(loadTestdata(t, "exif.tiff"), binary.LittleEndian); ok {
+		t.Errorf("detectEndianMismatch of consistent TIFF wants false but %q", warning)
+	}
+	if _, ok := detectEndianMismatch([]byte("II\x2a\x00"), binary.LittleEndian); ok {
+		t.Errorf("detectEndianMismatch of short TIFF wants false")
+	}
+}
+
+func TestDecoder_DetectEndianMismatch(t *testing.T) {
+	segment := append([]byte{0xff, markerAPP1, 0x00, byte(2 + len(exifMarker) + len(mismatchedTIFF))}, exifMarker...)
+	b := insertSegments(loadTestdata(t, "noexif.jpg"), append(segment, mismatchedTIFF...))
+	var logs bytes.Buffer
+	d := Decoder{Options: DecodeOptions{DetectEndianMismatch: true}, Logger: slog.New(slog.NewTextHandler(&logs, nil))}
+	d.Decode(bytes.NewReader(b))
+	if !strings.Contains(logs.String(), "level=WARN") || !strings.Contains(logs.String(), "implausible in LittleEndian") {
+		t.Errorf("Decode wants a warning but %q", logs.String())
+	}
+}
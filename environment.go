@@ -0,0 +1,67 @@
+package main
+
+import "encoding/binary"
+
+const (
+	tagTemperature          = 0x9400
+	tagHumidity             = 0x9401
+	tagPressure             = 0x9402
+	tagWaterDepth           = 0x9403
+	tagAcceleration         = 0x9404
+	tagCameraElevationAngle = 0x9405
+)
+
+// Temperature returns the ambient temperature in degrees Celsius.
+func (a *APP1) Temperature() (float64, bool) {
+	return findFloat64(a.ExifIFD, tagTemperature, a.Endian)
+}
+
+// Humidity returns the ambient relative humidity in percent.
+func (a *APP1) Humidity() (float64, bool) {
+	return findFloat64(a.ExifIFD, tagHumidity, a.Endian)
+}
+
+// Pressure returns the ambient air pressure in hPa.
+func (a *APP1) Pressure() (float64, bool) {
+	return findFloat64(a.ExifIFD, tagPressure, a.Endian)
+}
+
+// WaterDepth returns the water depth in meters.
+// It is negative above the water surface.
+func (a *APP1) WaterDepth() (float64, bool) {
+	return findFloat64(a.ExifIFD, tagWaterDepth, a.Endian)
+}
+
+// Acceleration returns the acceleration in mGal (10^-5 m/s^2).
+func (a *APP1) Acceleration() (float64, bool) {
+	return findFloat64(a.ExifIFD, tagAcceleration, a.Endian)
+}
+
+// CameraElevationAngle returns the elevation angle of the camera in degrees.
+func (a *APP1) CameraElevationAngle() (float64, bool) {
+	return findFloat64(a.ExifIFD, tagCameraElevationAngle, a.Endian)
+}
+
+// findFloat64 returns the RATIONAL or SRATIONAL value of the tag in the IFD as float64.
+// It returns false if the denominator is zero or the value is 0xFFFFFFFF/0xFFFFFFFF which means unknown.
+func findFloat64(d *IFD, tag uint16, endian binary.ByteOrder) (float64, bool) {
+	e := d.Find(tag)
+	if e == nil {
+		return 0, false
+	}
+	switch e.Type {
+	case 5:
+		r, err := e.Rationals(endian)
+		if err != nil || len(r) == 0 || r[0].Denominator == 0 || r[0].Denominator == 0xffffffff && r[0].Numerator == 0xffffffff {
+			return 0, false
+		}
+		return r[0].Float64(), true
+	case 10:
+		r, err := e.SRationals(endian)
+		if err != nil || len(r) == 0 || r[0].Denominator == 0 || r[0].Denominator == -1 && r[0].Numerator == -1 {
+			return 0, false
+		}
+		return r[0].Float64(), true
+	}
+	return 0, false
+}
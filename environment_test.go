@@ -0,0 +1,53 @@
+package main
+
+import "testing"
+
+func TestAPP1_Temperature(t *testing.T) {
+	a := newTestAPP1(nil)
+	if _, ok := a.Temperature(); ok {
+		t.Errorf("Temperature wants false if not present")
+	}
+	a.SetElement(ExifIFDKind, newSRationalElement(tagTemperature, []SRational{{-55, 10}}, a.Endian))
+	a.SetElement(ExifIFDKind, newRationalElement(tagHumidity, []Rational{{45, 1}}, a.Endian))
+	a.SetElement(ExifIFDKind, newRationalElement(tagPressure, []Rational{{10132, 10}}, a.Endian))
+	a.SetElement(ExifIFDKind, newSRationalElement(tagWaterDepth, []SRational{{-3, 2}}, a.Endian))
+	a.SetElement(ExifIFDKind, newRationalElement(tagAcceleration, []Rational{{980, 1}}, a.Endian))
+	a.SetElement(ExifIFDKind, newSRationalElement(tagCameraElevationAngle, []SRational{{-90, 1}}, a.Endian))
+	for _, c := range []struct {
+		name string
+		f    func() (float64, bool)
+		want float64
+	}{
+		{"Temperature", a.Temperature, -5.5},
+		{"Humidity", a.Humidity, 45},
+		{"Pressure", a.Pressure, 1013.2},
+		{"WaterDepth", a.WaterDepth, -1.5},
+		{"Acceleration", a.Acceleration, 980},
+		{"CameraElevationAngle", a.CameraElevationAngle, -90},
+	} {
+		if v, ok := c.f(); !ok || v != c.want {
+			t.Errorf("%s wants %f but %f, %v", c.name, c.want, v, ok)
+		}
+	}
+}
+
+func TestFindFloat64_Unknown(t *testing.T) {
+	a := newTestAPP1(nil)
+	a.SetElement(ExifIFDKind, newSRationalElement(tagTemperature, []SRational{{-1, -1}}, a.Endian))
+	a.SetElement(ExifIFDKind, newRationalElement(tagHumidity, []Rational{{0xffffffff, 0xffffffff}}, a.Endian))
+	a.SetElement(ExifIFDKind, newRationalElement(tagPressure, []Rational{{1, 0}}, a.Endian))
+	a.SetElement(ExifIFDKind, newShortElement(tagWaterDepth, 1, a.Endian))
+	for _, c := range []struct {
+		name string
+		f    func() (float64, bool)
+	}{
+		{"Temperature", a.Temperature},
+		{"Humidity", a.Humidity},
+		{"Pressure", a.Pressure},
+		{"WaterDepth", a.WaterDepth},
+	} {
+		if v, ok := c.f(); ok {
+			t.Errorf("%s of unknown value wants false but %f", c.name, v)
+		}
+	}
+}
@@ -0,0 +1,488 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math"
+)
+
+const (
+	tagExposureTime              = 0x829A
+	tagFNumber                   = 0x829D
+	tagPhotographicSensitivity   = 0x8827
+	tagSensitivityType           = 0x8830
+	tagStandardOutputSensitivity = 0x8831
+	tagRecommendedExposureIndex  = 0x8832
+	tagExifVersion               = 0x9000
+	tagUserComment               = 0x9286
+	tagPixelXDimension           = 0xA002
+	tagPixelYDimension           = 0xA003
+	tagRelatedSoundFile          = 0xA004
+	tagShutterSpeedValue         = 0x9201
+	tagBrightnessValue           = 0x9203
+	tagLightSource               = 0x9208
+	tagFocalLength               = 0x920A
+	tagTIFFEPFlashEnergy         = 0x920B
+	tagFlashEnergy               = 0xA20B
+	tagFileSource                = 0xA300
+	tagSceneType                 = 0xA301
+	tagCustomRendered            = 0xA401
+	tagExposureMode              = 0xA402
+	tagDigitalZoomRatio          = 0xA404
+	tagGainControl               = 0xA407
+	tagContrast                  = 0xA408
+	tagSaturation                = 0xA409
+	tagSharpness                 = 0xA40A
+	tagImageUniqueID             = 0xA420
+	tagCameraOwnerName           = 0xA430
+	tagBodySerialNumber          = 0xA431
+	tagLensModel                 = 0xA434
+	tagGamma                     = 0xA500
+)
+
+// ExposureTime returns the exposure time in seconds.
+func (a *APP1) ExposureTime() (Rational, bool) {
+	r, ok := findRational(a.ExifIFD, tagExposureTime, a.Endian)
+	if !ok || r.Denominator == 0 {
+		return Rational{}, false
+	}
+	return r, true
+}
+
+// FNumber returns the F number.
+func (a *APP1) FNumber() (float64, bool) {
+	r, ok := findRational(a.ExifIFD, tagFNumber, a.Endian)
+	if !ok || r.Denominator == 0 {
+		return 0, false
+	}
+	return r.Float64(), true
+}
+
+// FocalLength returns the actual focal length of the lens in mm.
+func (a *APP1) FocalLength() (float64, bool) {
+	r, ok := findRational(a.ExifIFD, tagFocalLength, a.Endian)
+	if !ok || r.Denominator == 0 {
+		return 0, false
+	}
+	return r.Float64(), true
+}
+
+// ISO returns PhotographicSensitivity, which is known as ISOSpeedRatings in Exif 2.2.
+func (a *APP1) ISO() (int, bool) {
+	v, ok := findUint16(a.ExifIFD, tagPhotographicSensitivity, a.Endian)
+	return int(v), ok
+}
+
+// SensitivityType indicates which sensitivity tag is authoritative.
+type SensitivityType uint16
+
+var sensitivityTypeNames = map[SensitivityType]string{
+	0: "Unknown",
+	1: "Standard output sensitivity (SOS)",
+	2: "Recommended exposure index (REI)",
+	3: "ISO speed",
+	4: "SOS and REI",
+	5: "SOS and ISO speed",
+	6: "REI and ISO speed",
+	7: "SOS and REI and ISO speed",
+}
+
+func (t SensitivityType) String() string {
+	if s, ok := sensitivityTypeNames[t]; ok {
+		return s
+	}
+	return fmt.Sprintf("SensitivityType(%d)", uint16(t))
+}
+
+// SensitivityType returns the SensitivityType tag in the Exif IFD.
+func (a *APP1) SensitivityType() (SensitivityType, bool) {
+	v, ok := findUint16(a.ExifIFD, tagSensitivityType, a.Endian)
+	return SensitivityType(v), ok
+}
+
+// StandardOutputSensitivity returns the StandardOutputSensitivity tag in the Exif IFD.
+func (a *APP1) StandardOutputSensitivity() (uint32, bool) {
+	return findUint32(a.ExifIFD, tagStandardOutputSensitivity, a.Endian)
+}
+
+// RecommendedExposureIndex returns the RecommendedExposureIndex tag in the Exif IFD.
+func (a *APP1) RecommendedExposureIndex() (uint32, bool) {
+	return findUint32(a.ExifIFD, tagRecommendedExposureIndex, a.Endian)
+}
+
+// PixelDimensions returns PixelXDimension and PixelYDimension in the Exif IFD.
+func (a *APP1) PixelDimensions() (width, height int, ok bool) {
+	x, ok := findUint(a.ExifIFD, tagPixelXDimension, a.Endian)
+	if !ok {
+		return 0, 0, false
+	}
+	y, ok := findUint(a.ExifIFD, tagPixelYDimension, a.Endian)
+	if !ok {
+		return 0, 0, false
+	}
+	return int(x), int(y), true
+}
+
+// ExifSpecVersion returns the version of the Exif spec in ExifVersion, such as "0232" for 2.32.
+// The minor version is two digits for comparison, e.g. 2.2 is (2, 20), 2.3 is (2, 30) and 2.31 is (2, 31).
+func (a *APP1) ExifSpecVersion() (major, minor int, ok bool) {
+	e := a.ExifIFD.Find(tagExifVersion)
+	if e == nil || (e.Type != 7 && e.Type != 2) || e.Count < 4 || len(e.Value) < 4 {
+		return 0, 0, false
+	}
+	for _, c := range e.Value[:4] {
+		if c < '0' || c > '9' {
+			return 0, 0, false
+		}
+	}
+	v := e.Value
+	major = int(v[0]-'0')*10 + int(v[1]-'0')
+	minor = int(v[2]-'0')*10 + int(v[3]-'0')
+	return major, minor, true
+}
+
+// UserComment returns the comment written by the user.
+func (a *APP1) UserComment() (string, bool) {
+	return findEncodedString(a.ExifIFD, tagUserComment, a.Endian)
+}
+
+// LightSource represents the kind of light source.
+type LightSource uint16
+
+const (
+	LightSourceUnknown LightSource = 0
+	LightSourceOther   LightSource = 255
+)
+
+var lightSourceNames = map[LightSource]string{
+	0:   "Unknown",
+	1:   "Daylight",
+	2:   "Fluorescent",
+	3:   "Tungsten (incandescent light)",
+	4:   "Flash",
+	9:   "Fine weather",
+	10:  "Cloudy weather",
+	11:  "Shade",
+	12:  "Daylight fluorescent (D 5700 - 7100K)",
+	13:  "Day white fluorescent (N 4600 - 5500K)",
+	14:  "Cool white fluorescent (W 3800 - 4500K)",
+	15:  "White fluorescent (WW 3250 - 3800K)",
+	16:  "Warm white fluorescent (L 2600 - 3250K)",
+	17:  "Standard light A",
+	18:  "Standard light B",
+	19:  "Standard light C",
+	20:  "D55",
+	21:  "D65",
+	22:  "D75",
+	23:  "D50",
+	24:  "ISO studio tungsten",
+	255: "Other light source",
+}
+
+// String returns the name of the light source.
+// Values not defined in the spec (5-8, 25-254 and above 255) are reserved.
+func (l LightSource) String() string {
+	if s, ok := lightSourceNames[l]; ok {
+		return s
+	}
+	return fmt.Sprintf("Reserved(%d)", uint16(l))
+}
+
+// LightSource returns the LightSource tag in the Exif IFD.
+func (a *APP1) LightSource() (LightSource, bool) {
+	v, ok := findUint16(a.ExifIFD, tagLightSource, a.Endian)
+	return LightSource(v), ok
+}
+
+// CustomRendered indicates the use of special processing on image data.
+type CustomRendered uint16
+
+var customRenderedNames = map[CustomRendered]string{
+	0: "Normal",
+	1: "Custom",
+	// values written by iPhone
+	2: "HDR (original not saved)",
+	3: "HDR (original saved)",
+	4: "Original (for HDR)",
+	6: "Panorama",
+	7: "Portrait HDR",
+	8: "Portrait",
+}
+
+func (c CustomRendered) String() string {
+	if s, ok := customRenderedNames[c]; ok {
+		return s
+	}
+	return fmt.Sprintf("CustomRendered(%d)", uint16(c))
+}
+
+// CustomRendered returns the CustomRendered tag in the Exif IFD.
+func (a *APP1) CustomRendered() (CustomRendered, bool) {
+	v, ok := findUint16(a.ExifIFD, tagCustomRendered, a.Endian)
+	return CustomRendered(v), ok
+}
+
+// ExposureMode indicates the exposure mode set when the image was shot.
+type ExposureMode uint16
+
+var exposureModeNames = map[ExposureMode]string{
+	0: "Auto",
+	1: "Manual",
+	2: "Auto bracket",
+}
+
+func (m ExposureMode) String() string {
+	if s, ok := exposureModeNames[m]; ok {
+		return s
+	}
+	return fmt.Sprintf("ExposureMode(%d)", uint16(m))
+}
+
+// ExposureMode returns the ExposureMode tag in the Exif IFD.
+func (a *APP1) ExposureMode() (ExposureMode, bool) {
+	v, ok := findUint16(a.ExifIFD, tagExposureMode, a.Endian)
+	return ExposureMode(v), ok
+}
+
+// DigitalZoomRatio returns the digital zoom ratio at the time of capture.
+// The ratio is 0 if digital zoom was not used, which is written as 0 or 0/0.
+func (a *APP1) DigitalZoomRatio() (float64, bool) {
+	r, ok := findRational(a.ExifIFD, tagDigitalZoomRatio, a.Endian)
+	if !ok {
+		return 0, false
+	}
+	if r.Numerator == 0 || r.Denominator == 0 {
+		return 0, true
+	}
+	return r.Float64(), true
+}
+
+// GainControl indicates the degree of overall image gain adjustment.
+type GainControl uint16
+
+var gainControlNames = map[GainControl]string{
+	0: "None",
+	1: "Low gain up",
+	2: "High gain up",
+	3: "Low gain down",
+	4: "High gain down",
+}
+
+func (g GainControl) String() string {
+	if s, ok := gainControlNames[g]; ok {
+		return s
+	}
+	return fmt.Sprintf("GainControl(%d)", uint16(g))
+}
+
+// GainControl returns the GainControl tag in the Exif IFD.
+func (a *APP1) GainControl() (GainControl, bool) {
+	v, ok := findUint16(a.ExifIFD, tagGainControl, a.Endian)
+	return GainControl(v), ok
+}
+
+// Contrast indicates the direction of contrast processing applied by the camera.
+type Contrast uint16
+
+var contrastNames = map[Contrast]string{
+	0: "Normal",
+	1: "Soft",
+	2: "Hard",
+}
+
+func (c Contrast) String() string {
+	if s, ok := contrastNames[c]; ok {
+		return s
+	}
+	return fmt.Sprintf("Contrast(%d)", uint16(c))
+}
+
+// Contrast returns the Contrast tag in the Exif IFD.
+func (a *APP1) Contrast() (Contrast, bool) {
+	v, ok := findUint16(a.ExifIFD, tagContrast, a.Endian)
+	return Contrast(v), ok
+}
+
+// Saturation indicates the direction of saturation processing applied by the camera.
+type Saturation uint16
+
+var saturationNames = map[Saturation]string{
+	0: "Normal",
+	1: "Low saturation",
+	2: "High saturation",
+}
+
+func (s Saturation) String() string {
+	if name, ok := saturationNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("Saturation(%d)", uint16(s))
+}
+
+// Saturation returns the Saturation tag in the Exif IFD.
+func (a *APP1) Saturation() (Saturation, bool) {
+	v, ok := findUint16(a.ExifIFD, tagSaturation, a.Endian)
+	return Saturation(v), ok
+}
+
+// Sharpness indicates the direction of sharpness processing applied by the camera.
+type Sharpness uint16
+
+var sharpnessNames = map[Sharpness]string{
+	0: "Normal",
+	1: "Soft",
+	2: "Hard",
+}
+
+func (s Sharpness) String() string {
+	if name, ok := sharpnessNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("Sharpness(%d)", uint16(s))
+}
+
+// Sharpness returns the Sharpness tag in the Exif IFD.
+func (a *APP1) Sharpness() (Sharpness, bool) {
+	v, ok := findUint16(a.ExifIFD, tagSharpness, a.Endian)
+	return Sharpness(v), ok
+}
+
+// FileSource indicates the image source.
+type FileSource uint8
+
+const FileSourceDSC FileSource = 3
+
+var fileSourceNames = map[FileSource]string{
+	0: "Others",
+	1: "Scanner of transparent type",
+	2: "Scanner of reflex type",
+	3: "DSC",
+}
+
+func (f FileSource) String() string {
+	if s, ok := fileSourceNames[f]; ok {
+		return s
+	}
+	return fmt.Sprintf("FileSource(%d)", uint8(f))
+}
+
+// FileSource returns the FileSource tag in the Exif IFD.
+// It is 3 (DSC) if the image was recorded by a digital still camera.
+func (a *APP1) FileSource() (FileSource, bool) {
+	v, ok := findUndefinedByte(a.ExifIFD, tagFileSource)
+	return FileSource(v), ok
+}
+
+// SceneType indicates the type of scene.
+type SceneType uint8
+
+const SceneTypeDirectlyPhotographed SceneType = 1
+
+var sceneTypeNames = map[SceneType]string{
+	1: "Directly photographed",
+}
+
+func (s SceneType) String() string {
+	if name, ok := sceneTypeNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("SceneType(%d)", uint8(s))
+}
+
+// SceneType returns the SceneType tag in the Exif IFD.
+func (a *APP1) SceneType() (SceneType, bool) {
+	v, ok := findUndefinedByte(a.ExifIFD, tagSceneType)
+	return SceneType(v), ok
+}
+
+// ImageUniqueID returns the identifier of the image, which is 32 hex digits.
+// It returns false if the value is malformed.
+func (a *APP1) ImageUniqueID() (string, bool) {
+	s, ok := findTrimmedASCII(a.ExifIFD, tagImageUniqueID)
+	if !ok || len(s) != 32 {
+		return "", false
+	}
+	if _, err := hex.DecodeString(s); err != nil {
+		return "", false
+	}
+	return s, true
+}
+
+// ExposureValue returns the exposure value normalized to ISO 100,
+// i.e. EV = log2(N^2 / t) - log2(ISO / 100).
+func (a *APP1) ExposureValue() (float64, bool) {
+	n, ok := a.FNumber()
+	if !ok || n <= 0 {
+		return 0, false
+	}
+	t, ok := a.ExposureTime()
+	if !ok || t.Numerator == 0 {
+		return 0, false
+	}
+	iso, ok := a.ISO()
+	if !ok || iso == 0 {
+		return 0, false
+	}
+	return math.Log2(n*n/t.Float64()) - math.Log2(float64(iso)/100), true
+}
+
+// RelatedSoundFile returns the name of the audio file related to the image,
+// which is 8.3 format such as "DSCN0042.WAV".
+func (a *APP1) RelatedSoundFile() (string, bool) {
+	return findTrimmedASCII(a.ExifIFD, tagRelatedSoundFile)
+}
+
+// CameraOwnerName returns the name of the camera owner.
+func (a *APP1) CameraOwnerName() (string, bool) {
+	return findTrimmedASCII(a.ExifIFD, tagCameraOwnerName)
+}
+
+// BodySerialNumber returns the serial number of the camera body.
+func (a *APP1) BodySerialNumber() (string, bool) {
+	return findTrimmedASCII(a.ExifIFD, tagBodySerialNumber)
+}
+
+// LensModel returns the model name of the lens.
+func (a *APP1) LensModel() (string, bool) {
+	return findTrimmedASCII(a.ExifIFD, tagLensModel)
+}
+
+// FlashEnergy returns the strobe energy at the time of capture in BCPS.
+// The tag is 0xA20B in Exif, and 0x920B of TIFF/EP is also accepted.
+func (a *APP1) FlashEnergy() (float64, bool) {
+	for _, tag := range []uint16{tagFlashEnergy, tagTIFFEPFlashEnergy} {
+		if v, ok := findFloat64s(a.ExifIFD, tag, 1, a.Endian); ok {
+			return v[0], true
+		}
+	}
+	return 0, false
+}
+
+// Gamma returns the gamma coefficient of the transfer function.
+func (a *APP1) Gamma() (float64, bool) {
+	v, ok := findFloat64s(a.ExifIFD, tagGamma, 1, a.Endian)
+	if !ok {
+		return 0, false
+	}
+	return v[0], true
+}
+
+// ShutterSpeedValue returns the shutter speed in APEX and the exposure time in seconds,
+// i.e. t = 2^(-apex).
+func (a *APP1) ShutterSpeedValue() (apex, seconds float64, ok bool) {
+	apex, ok = findFloat64(a.ExifIFD, tagShutterSpeedValue, a.Endian)
+	if !ok {
+		return 0, 0, false
+	}
+	return apex, math.Pow(2, -apex), true
+}
+
+// BrightnessValue returns the brightness in APEX and the approximate luminance in cd/m^2,
+// i.e. L = 2^apex * 3.426 for the calibration constants N = 0.32 and K = 12.5 (ISO 2720).
+func (a *APP1) BrightnessValue() (apex, luminance float64, ok bool) {
+	apex, ok = findFloat64(a.ExifIFD, tagBrightnessValue, a.Endian)
+	if !ok {
+		return 0, 0, false
+	}
+	return apex, math.Pow(2, apex) * 3.426, true
+}
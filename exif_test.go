@@ -0,0 +1,332 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestAPP1_SensitivityType(t *testing.T) {
+	a := newTestAPP1(nil)
+	if _, ok := a.SensitivityType(); ok {
+		t.Errorf("SensitivityType wants false if not present")
+	}
+	a.SetElement(ExifIFDKind, newShortElement(tagSensitivityType, 2, a.Endian))
+	a.SetElement(ExifIFDKind, newLongElement(tagStandardOutputSensitivity, 100, a.Endian))
+	a.SetElement(ExifIFDKind, newLongElement(tagRecommendedExposureIndex, 200, a.Endian))
+	if v, ok := a.SensitivityType(); !ok || v != 2 || v.String() != "Recommended exposure index (REI)" {
+		t.Errorf("SensitivityType wants REI but %s", v)
+	}
+	if v, ok := a.StandardOutputSensitivity(); !ok || v != 100 {
+		t.Errorf("StandardOutputSensitivity wants 100 but %d", v)
+	}
+	if v, ok := a.RecommendedExposureIndex(); !ok || v != 200 {
+		t.Errorf("RecommendedExposureIndex wants 200 but %d", v)
+	}
+	if s := SensitivityType(99).String(); s != "SensitivityType(99)" {
+		t.Errorf("String of unknown value wants SensitivityType(99) but %s", s)
+	}
+}
+
+func TestAPP1_UserComment(t *testing.T) {
+	a := newTestAPP1(nil)
+	if _, ok := a.UserComment(); ok {
+		t.Errorf("UserComment wants false if not present")
+	}
+	v := []byte("ASCII\x00\x00\x00Hello")
+	a.SetElement(ExifIFDKind, newElement(tagUserComment, 7, uint32(len(v)), v))
+	if s, ok := a.UserComment(); !ok || s != "Hello" {
+		t.Errorf("UserComment wants Hello but %q, %v", s, ok)
+	}
+}
+
+func TestAPP1_GPSProcessingMethod(t *testing.T) {
+	a := newTestAPP1(nil)
+	if _, ok := a.GPSProcessingMethod(); ok {
+		t.Errorf("GPSProcessingMethod wants false if not present")
+	}
+	method := []byte("ASCII\x00\x00\x00CELLID")
+	area := []byte("UNICODE\x00T\x00o\x00k\x00y\x00o\x00")
+	a.SetElement(GPSIFDKind, newElement(tagGPSProcessingMethod, 7, uint32(len(method)), method))
+	a.SetElement(GPSIFDKind, newElement(tagGPSAreaInformation, 7, uint32(len(area)), area))
+	if s, ok := a.GPSProcessingMethod(); !ok || s != "CELLID" {
+		t.Errorf("GPSProcessingMethod wants CELLID but %q, %v", s, ok)
+	}
+	if s, ok := a.GPSAreaInformation(); !ok || s != "Tokyo" {
+		t.Errorf("GPSAreaInformation wants Tokyo but %q, %v", s, ok)
+	}
+}
+
+func TestAPP1_CaptureSettings(t *testing.T) {
+	a := decodeTestdata(t, "ii.jpg").APP1
+	if v, ok := a.ExposureTime(); !ok || v != (Rational{1, 250}) {
+		t.Errorf("ExposureTime wants 1/250 but %v, %v", v, ok)
+	}
+	if v, ok := a.FNumber(); !ok || v != 2.8 {
+		t.Errorf("FNumber wants 2.8 but %f, %v", v, ok)
+	}
+	if v, ok := a.ISO(); !ok || v != 400 {
+		t.Errorf("ISO wants 400 but %d, %v", v, ok)
+	}
+	if v, ok := a.Make(); !ok || v != "Canon" {
+		t.Errorf("Make wants Canon but %q, %v", v, ok)
+	}
+	a.SetElement(ExifIFDKind, newRationalElement(tagFNumber, []Rational{{28, 0}}, a.Endian))
+	if v, ok := a.FNumber(); ok {
+		t.Errorf("FNumber of zero denominator wants false but %f", v)
+	}
+}
+
+func TestAPP1_CustomRendered(t *testing.T) {
+	a := newTestAPP1(nil)
+	if _, ok := a.CustomRendered(); ok {
+		t.Errorf("CustomRendered wants false if not present")
+	}
+	a.SetElement(ExifIFDKind, newShortElement(tagCustomRendered, 8, a.Endian))
+	a.SetElement(ExifIFDKind, newShortElement(tagExposureMode, 2, a.Endian))
+	a.SetElement(ExifIFDKind, newShortElement(tagGainControl, 1, a.Endian))
+	if v, ok := a.CustomRendered(); !ok || v.String() != "Portrait" {
+		t.Errorf("CustomRendered wants Portrait but %s, %v", v, ok)
+	}
+	if v, ok := a.ExposureMode(); !ok || v.String() != "Auto bracket" {
+		t.Errorf("ExposureMode wants Auto bracket but %s, %v", v, ok)
+	}
+	if v, ok := a.GainControl(); !ok || v.String() != "Low gain up" {
+		t.Errorf("GainControl wants Low gain up but %s, %v", v, ok)
+	}
+	for _, c := range []struct {
+		s    fmt.Stringer
+		want string
+	}{
+		{CustomRendered(5), "CustomRendered(5)"},
+		{ExposureMode(3), "ExposureMode(3)"},
+		{GainControl(5), "GainControl(5)"},
+	} {
+		if s := c.s.String(); s != c.want {
+			t.Errorf("String of unknown value wants %s but %s", c.want, s)
+		}
+	}
+}
+
+func TestAPP1_ImageUniqueID(t *testing.T) {
+	for _, c := range []struct {
+		name   string
+		value  string
+		wantOK bool
+	}{
+		{"valid", "0123456789abcdef0123456789ABCDEF", true},
+		{"short", "0123456789abcdef", false},
+		{"not hex", "0123456789abcdef0123456789abcdeg", false},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			a := newTestAPP1(nil)
+			a.SetElement(ExifIFDKind, newASCIIElement(tagImageUniqueID, c.value))
+			v, ok := a.ImageUniqueID()
+			if ok != c.wantOK || ok && v != c.value {
+				t.Errorf("ImageUniqueID wants %v but %q, %v", c.wantOK, v, ok)
+			}
+		})
+	}
+}
+
+func TestAPP1_LightSource(t *testing.T) {
+	a := newTestAPP1(nil)
+	if _, ok := a.LightSource(); ok {
+		t.Errorf("LightSource wants false if not present")
+	}
+	a.SetElement(ExifIFDKind, newShortElement(tagLightSource, 21, a.Endian))
+	if v, ok := a.LightSource(); !ok || v.String() != "D65" {
+		t.Errorf("LightSource wants D65 but %s, %v", v, ok)
+	}
+	for _, c := range []struct {
+		v    LightSource
+		want string
+	}{
+		{LightSourceUnknown, "Unknown"},
+		{LightSourceOther, "Other light source"},
+		{5, "Reserved(5)"},
+		{25, "Reserved(25)"},
+		{256, "Reserved(256)"},
+	} {
+		if s := c.v.String(); s != c.want {
+			t.Errorf("String of %d wants %s but %s", uint16(c.v), c.want, s)
+		}
+	}
+}
+
+func TestAPP1_ExposureValue(t *testing.T) {
+	a := decodeTestdata(t, "ii.jpg").APP1
+	// f/2.8, 1/250s and ISO 400
+	want := math.Log2(2.8*2.8*250) - 2
+	if v, ok := a.ExposureValue(); !ok || math.Abs(v-want) > 1e-9 {
+		t.Errorf("ExposureValue wants %f but %f, %v", want, v, ok)
+	}
+	a.SetElement(ExifIFDKind, newShortElement(tagPhotographicSensitivity, 0, a.Endian))
+	if v, ok := a.ExposureValue(); ok {
+		t.Errorf("ExposureValue of ISO 0 wants false but %f", v)
+	}
+	if v, ok := newTestAPP1(nil).ExposureValue(); ok {
+		t.Errorf("ExposureValue without settings wants false but %f", v)
+	}
+}
+
+func TestAPP1_BodySerialNumber(t *testing.T) {
+	a := newTestAPP1(nil)
+	if _, ok := a.BodySerialNumber(); ok {
+		t.Errorf("BodySerialNumber wants false if not present")
+	}
+	a.SetElement(ExifIFDKind, newASCIIElement(tagBodySerialNumber, "012345678901 "))
+	a.SetElement(ExifIFDKind, newASCIIElement(tagCameraOwnerName, "Alice"))
+	if v, ok := a.BodySerialNumber(); !ok || v != "012345678901" {
+		t.Errorf("BodySerialNumber wants 012345678901 but %q, %v", v, ok)
+	}
+	if v, ok := a.CameraOwnerName(); !ok || v != "Alice" {
+		t.Errorf("CameraOwnerName wants Alice but %q, %v", v, ok)
+	}
+}
+
+func TestAPP1_Gamma(t *testing.T) {
+	a := newTestAPP1(nil)
+	if _, ok := a.Gamma(); ok {
+		t.Errorf("Gamma wants false if not present")
+	}
+	a.SetElement(ExifIFDKind, newRationalElement(tagGamma, []Rational{{22, 10}}, a.Endian))
+	if v, ok := a.Gamma(); !ok || v != 2.2 {
+		t.Errorf("Gamma wants 2.2 but %f, %v", v, ok)
+	}
+}
+
+func TestAPP1_ShutterSpeedValue(t *testing.T) {
+	a := newTestAPP1(nil)
+	if _, _, ok := a.ShutterSpeedValue(); ok {
+		t.Errorf("ShutterSpeedValue wants false if not present")
+	}
+	a.SetElement(ExifIFDKind, newSRationalElement(tagShutterSpeedValue, []SRational{{8, 1}}, a.Endian))
+	a.SetElement(ExifIFDKind, newSRationalElement(tagBrightnessValue, []SRational{{-1, 1}}, a.Endian))
+	if apex, seconds, ok := a.ShutterSpeedValue(); !ok || apex != 8 || seconds != 1.0/256 {
+		t.Errorf("ShutterSpeedValue wants 8 and 1/256s but %f, %f, %v", apex, seconds, ok)
+	}
+	if apex, luminance, ok := a.BrightnessValue(); !ok || apex != -1 || luminance != 3.426/2 {
+		t.Errorf("BrightnessValue wants -1 and 1.713 but %f, %f, %v", apex, luminance, ok)
+	}
+}
+
+func TestAPP1_Contrast(t *testing.T) {
+	a := newTestAPP1(nil)
+	if _, ok := a.Contrast(); ok {
+		t.Errorf("Contrast wants false if not present")
+	}
+	a.SetElement(ExifIFDKind, newShortElement(tagContrast, 1, a.Endian))
+	a.SetElement(ExifIFDKind, newShortElement(tagSaturation, 2, a.Endian))
+	a.SetElement(ExifIFDKind, newShortElement(tagSharpness, 2, a.Endian))
+	if v, ok := a.Contrast(); !ok || v.String() != "Soft" {
+		t.Errorf("Contrast wants Soft but %s, %v", v, ok)
+	}
+	if v, ok := a.Saturation(); !ok || v.String() != "High saturation" {
+		t.Errorf("Saturation wants High saturation but %s, %v", v, ok)
+	}
+	if v, ok := a.Sharpness(); !ok || v.String() != "Hard" {
+		t.Errorf("Sharpness wants Hard but %s, %v", v, ok)
+	}
+	for _, c := range []struct {
+		s    fmt.Stringer
+		want string
+	}{
+		{Contrast(3), "Contrast(3)"},
+		{Saturation(3), "Saturation(3)"},
+		{Sharpness(3), "Sharpness(3)"},
+	} {
+		if s := c.s.String(); s != c.want {
+			t.Errorf("String of unknown value wants %s but %s", c.want, s)
+		}
+	}
+}
+
+func TestAPP1_FileSource(t *testing.T) {
+	a := newTestAPP1(nil)
+	if _, ok := a.FileSource(); ok {
+		t.Errorf("FileSource wants false if not present")
+	}
+	a.SetElement(ExifIFDKind, newUndefinedElement(tagFileSource, []byte{3}))
+	a.SetElement(ExifIFDKind, newUndefinedElement(tagSceneType, []byte{1}))
+	if v, ok := a.FileSource(); !ok || v != FileSourceDSC || v.String() != "DSC" {
+		t.Errorf("FileSource wants DSC but %s, %v", v, ok)
+	}
+	if v, ok := a.SceneType(); !ok || v != SceneTypeDirectlyPhotographed {
+		t.Errorf("SceneType wants directly photographed but %s, %v", v, ok)
+	}
+	a.SetElement(ExifIFDKind, newShortElement(tagFileSource, 3, a.Endian))
+	if v, ok := a.FileSource(); ok {
+		t.Errorf("FileSource of SHORT wants false but %s", v)
+	}
+	if s := SceneType(2).String(); s != "SceneType(2)" {
+		t.Errorf("String of unknown value wants SceneType(2) but %s", s)
+	}
+	if s := FileSource(4).String(); s != "FileSource(4)" {
+		t.Errorf("String of unknown value wants FileSource(4) but %s", s)
+	}
+}
+
+func TestAPP1_ExifSpecVersion(t *testing.T) {
+	a := decodeTestdata(t, "ii.jpg").APP1
+	if major, minor, ok := a.ExifSpecVersion(); !ok || major != 2 || minor != 30 {
+		t.Errorf("ExifSpecVersion wants 2.30 but %d.%d, %v", major, minor, ok)
+	}
+	a.SetElement(ExifIFDKind, newUndefinedElement(tagExifVersion, []byte("0232")))
+	if major, minor, ok := a.ExifSpecVersion(); !ok || major != 2 || minor != 32 {
+		t.Errorf("ExifSpecVersion wants 2.32 but %d.%d, %v", major, minor, ok)
+	}
+	a.SetElement(ExifIFDKind, newUndefinedElement(tagExifVersion, []byte("02.3")))
+	if _, _, ok := a.ExifSpecVersion(); ok {
+		t.Errorf("ExifSpecVersion of non digits wants false")
+	}
+	if _, _, ok := newTestAPP1(nil).ExifSpecVersion(); ok {
+		t.Errorf("ExifSpecVersion wants false if not present")
+	}
+}
+
+func TestAPP1_RelatedSoundFile(t *testing.T) {
+	a := newTestAPP1(nil)
+	if _, ok := a.RelatedSoundFile(); ok {
+		t.Errorf("RelatedSoundFile wants false if not present")
+	}
+	a.SetElement(ExifIFDKind, newASCIIElement(tagRelatedSoundFile, "DSCN0042.WAV"))
+	if v, ok := a.RelatedSoundFile(); !ok || v != "DSCN0042.WAV" {
+		t.Errorf("RelatedSoundFile wants DSCN0042.WAV but %q, %v", v, ok)
+	}
+}
+
+func TestAPP1_FlashEnergy(t *testing.T) {
+	a := newTestAPP1(nil)
+	if _, ok := a.FlashEnergy(); ok {
+		t.Errorf("FlashEnergy wants false if not present")
+	}
+	a.SetElement(ExifIFDKind, newRationalElement(tagTIFFEPFlashEnergy, []Rational{{100, 1}}, a.Endian))
+	if v, ok := a.FlashEnergy(); !ok || v != 100 {
+		t.Errorf("FlashEnergy of TIFF/EP wants 100 but %f, %v", v, ok)
+	}
+	a.SetElement(ExifIFDKind, newRationalElement(tagFlashEnergy, []Rational{{45, 2}}, a.Endian))
+	if v, ok := a.FlashEnergy(); !ok || v != 22.5 {
+		t.Errorf("FlashEnergy wants 22.5 of Exif first but %f, %v", v, ok)
+	}
+}
+
+func TestAPP1_DigitalZoomRatio(t *testing.T) {
+	a := newTestAPP1(nil)
+	if _, ok := a.DigitalZoomRatio(); ok {
+		t.Errorf("DigitalZoomRatio wants false if not present")
+	}
+	for _, c := range []struct {
+		r    Rational
+		want float64
+	}{
+		{Rational{3, 2}, 1.5},
+		{Rational{0, 1}, 0},
+		{Rational{0, 0}, 0},
+	} {
+		a.SetElement(ExifIFDKind, newRationalElement(tagDigitalZoomRatio, []Rational{c.r}, a.Endian))
+		if v, ok := a.DigitalZoomRatio(); !ok || v != c.want {
+			t.Errorf("DigitalZoomRatio of %+v wants %f but %f, %v", c.r, c.want, v, ok)
+		}
+	}
+}
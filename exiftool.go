@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// exifToolGroups are the family 1 group names of ExifTool.
+var exifToolGroups = map[IFDKind]string{
+	IFD0Kind:                "IFD0",
+	ExifIFDKind:             "ExifIFD",
+	GPSIFDKind:              "GPS",
+	InteroperabilityIFDKind: "InteropIFD",
+	IFD1Kind:                "IFD1",
+}
+
+// exifToolNames are the tag names which differ in ExifTool.
+var exifToolNames = map[string]string{
+	"ImageLength":                 "ImageHeight",
+	"DateTime":                    "ModifyDate",
+	"DateTimeDigitized":           "CreateDate",
+	"PhotographicSensitivity":     "ISO",
+	"PixelXDimension":             "ExifImageWidth",
+	"PixelYDimension":             "ExifImageHeight",
+	"InteroperabilityIndex":       "InteropIndex",
+	"InteroperabilityVersion":     "InteropVersion",
+	"JPEGInterchangeFormat":       "ThumbnailOffset",
+	"JPEGInterchangeFormatLength": "ThumbnailLength",
+}
+
+// newExifToolObject returns the object shaped like the output of "exiftool -j -G1 -n".
+// Keys are prefixed with the group, e.g. "IFD0:Make", and values are not converted for print.
+// Unknown tags and IFD pointers are omitted as ExifTool does.
+func newExifToolObject(filename string, h *JPEGHeader) map[string]interface{} {
+	o := map[string]interface{}{"SourceFile": filename}
+	h.APP1.Walk(func(kind IFDKind, e *IFDElement) {
+		switch e.Tag {
+		case tagExifIFDPointer, tagGPSInfoIFDPointer, tagInteroperabilityIFDPointer:
+			return
+		}
+		name, ok := TagName(kind, e.Tag)
+		if !ok {
+			return
+		}
+		if n, ok := exifToolNames[name]; ok {
+			name = n
+		}
+		if kind == GPSIFDKind {
+			if v, ok := exifToolCoordinate(e, h.APP1.Endian); ok {
+				o[exifToolGroups[kind]+":"+name] = v
+				return
+			}
+		}
+		o[exifToolGroups[kind]+":"+name] = exifToolValue(e, h.APP1.Endian)
+	})
+	return o
+}
+
+// exifToolCoordinate returns the unsigned degrees of the GPS coordinate tag.
+func exifToolCoordinate(e *IFDElement, endian binary.ByteOrder) (float64, bool) {
+	switch e.Tag {
+	case tagGPSLatitude, tagGPSLongitude, tagGPSDestLatitude, tagGPSDestLongitude:
+	default:
+		return 0, false
+	}
+	dms, err := e.Rationals(endian)
+	if err != nil || len(dms) != 3 {
+		return 0, false
+	}
+	return dms[0].Float64() + dms[1].Float64()/60 + dms[2].Float64()/3600, true
+}
+
+// exifToolValue returns a number for a single numeric value, or a string otherwise.
+func exifToolValue(e *IFDElement, endian binary.ByteOrder) interface{} {
+	switch e.Type {
+	case 1, 3, 4, 9:
+		s := formatValue(e, endian)
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return n
+		}
+		return s
+	case 5, 10:
+		v, err := e.Decode(endian)
+		if err != nil {
+			return formatHex(e)
+		}
+		switch r := v.(type) {
+		case Rational:
+			return r.Float64()
+		case SRational:
+			return r.Float64()
+		}
+		var values []string
+		switch r := v.(type) {
+		case []Rational:
+			for _, x := range r {
+				values = append(values, strconv.FormatFloat(x.Float64(), 'g', -1, 64))
+			}
+		case []SRational:
+			for _, x := range r {
+				values = append(values, strconv.FormatFloat(x.Float64(), 'g', -1, 64))
+			}
+		}
+		return strings.Join(values, " ")
+	case 2:
+		s, err := e.ASCII()
+		if err != nil {
+			return formatHex(e)
+		}
+		return s
+	}
+	if err := e.checkLength(); err != nil {
+		return formatHex(e)
+	}
+	b := e.Value[:e.Length()]
+	for _, c := range b {
+		if c < 0x20 || c > 0x7e {
+			return fmt.Sprintf("(Binary data %d bytes, use -b option to extract)", len(b))
+		}
+	}
+	return string(b)
+}
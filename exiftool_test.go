@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestRun_ExifTool(t *testing.T) {
+	var b bytes.Buffer
+	if err := run(nil, &b, "exiftool", false, []string{"testdata/ii.jpg", "testdata/noexif.jpg"}); err != nil {
+		t.Fatalf("run error: %s", err)
+	}
+	var objects []map[string]interface{}
+	if err := json.Unmarshal(b.Bytes(), &objects); err != nil {
+		t.Fatalf("Could not decode json: %s", err)
+	}
+	if len(objects) != 2 {
+		t.Fatalf("objects wants 2 but %d", len(objects))
+	}
+	o := objects[0]
+	for key, want := range map[string]interface{}{
+		"SourceFile":              "testdata/ii.jpg",
+		"IFD0:Make":               "Canon",
+		"IFD0:Orientation":        float64(6),
+		"IFD0:ModifyDate":         "2018:09:22 10:11:12",
+		"ExifIFD:ISO":             float64(400),
+		"ExifIFD:FNumber":         2.8,
+		"ExifIFD:ExifVersion":     "0230",
+		"InteropIFD:InteropIndex": "R98",
+		"GPS:GPSLatitude":         35.675,
+	} {
+		if o[key] != want {
+			t.Errorf("%s wants %v but %v", key, want, o[key])
+		}
+	}
+	for _, key := range []string{"IFD0:ExifIFDPointer", "IFD0:GPSInfoIFDPointer", "ExifIFD:InteroperabilityIFDPointer"} {
+		if v, ok := o[key]; ok {
+			t.Errorf("%s wants omitted but %v", key, v)
+		}
+	}
+	if len(objects[1]) != 1 || objects[1]["SourceFile"] != "testdata/noexif.jpg" {
+		t.Errorf("object without Exif wants SourceFile only but %v", objects[1])
+	}
+}
+
+func TestExifToolValue(t *testing.T) {
+	endian := newTestAPP1(nil).Endian
+	for _, c := range []struct {
+		name string
+		e    *IFDElement
+		want interface{}
+	}{
+		{"SHORT", newShortElement(tagOrientation, 6, endian), int64(6)},
+		{"RATIONAL", newRationalElement(tagExposureTime, []Rational{{1, 250}}, endian), 0.004},
+		{"RATIONALs", newRationalElement(tagGPSTimeStamp, []Rational{{10, 1}, {11, 1}, {125, 10}}, endian), "10 11 12.5"},
+		{"ASCII", newASCIIElement(tagMake, "Canon"), "Canon"},
+		{"UNDEFINED text", newUndefinedElement(tagExifVersion, []byte("0230")), "0230"},
+		{"UNDEFINED binary", newUndefinedElement(tagMakerNote, []byte{0, 1, 2, 3, 4}), "(Binary data 5 bytes, use -b option to extract)"},
+	} {
+		if v := exifToolValue(c.e, endian); v != c.want {
+			t.Errorf("exifToolValue of %s wants %v but %v", c.name, c.want, v)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Fingerprint returns a hash of the capture metadata to find copies of the same original photo,
+// such as re-compressed or re-saved by an editor.
+// Editing software, modification time and the thumbnail are ignored.
+//
+// The fields are normalized as follows:
+//   - Make and Model by CameraKey
+//   - DateTimeOriginal as written without SubSecTime and OffsetTime
+//   - ExposureTime in seconds of 6 significant digits, e.g. 1/250 and 4/1000 are the same
+//   - FNumber and FocalLength with 1 decimal place
+//   - ISO as integer
+//   - latitude and longitude with 4 decimal places, about 11 meters
+//
+// An absent field is empty. It returns an empty string if all fields are absent or the APP1 is nil.
+func (a *APP1) Fingerprint() string {
+	if a == nil {
+		return ""
+	}
+	var fields []string
+	var present bool
+	add := func(name, value string) {
+		if value != "" {
+			present = true
+		}
+		fields = append(fields, name+"="+value)
+	}
+	add("camera", a.CameraKey())
+	dateTime, _ := findTrimmedASCII(a.ExifIFD, tagDateTimeOriginal)
+	add("datetime", dateTime)
+	var exposureTime string
+	if r, ok := a.ExposureTime(); ok {
+		exposureTime = strconv.FormatFloat(r.Float64(), 'g', 6, 64)
+	}
+	add("exposure", exposureTime)
+	var fNumber string
+	if v, ok := a.FNumber(); ok {
+		fNumber = fmt.Sprintf("%.1f", v)
+	}
+	add("fnumber", fNumber)
+	var iso string
+	if v, ok := a.ISO(); ok {
+		iso = strconv.Itoa(v)
+	}
+	add("iso", iso)
+	var focalLength string
+	if v, ok := a.FocalLength(); ok {
+		focalLength = fmt.Sprintf("%.1f", v)
+	}
+	add("focal", focalLength)
+	var location string
+	if lat, lng, ok := a.LatLng(); ok {
+		location = fmt.Sprintf("%.4f,%.4f", lat, lng)
+	}
+	add("location", location)
+	if !present {
+		return ""
+	}
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(strings.Join(fields, "\n"))))
+}
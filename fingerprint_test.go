@@ -0,0 +1,47 @@
+package main
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func TestAPP1_Fingerprint(t *testing.T) {
+	ii := decodeTestdata(t, "ii.jpg").APP1.Fingerprint()
+	if len(ii) != 64 {
+		t.Fatalf("Fingerprint wants sha256 in hex but %q", ii)
+	}
+	if mm := decodeTestdata(t, "mm.jpg").APP1.Fingerprint(); mm != ii {
+		t.Errorf("Fingerprint of the other byte order wants %s but %s", ii, mm)
+	}
+	if other := decodeTestdata(t, "nothumb.jpg").APP1.Fingerprint(); other == ii {
+		t.Errorf("Fingerprint without GPS wants different from %s", ii)
+	}
+}
+
+func TestAPP1_Fingerprint_Normalized(t *testing.T) {
+	a := decodeTestdata(t, "ii.jpg").APP1
+	want := a.Fingerprint()
+	// 4/1000 is the same exposure time as 1/250
+	if err := a.SetExposureTime(4, 1000); err != nil {
+		t.Fatalf("SetExposureTime error: %s", err)
+	}
+	// editing software is ignored
+	a.IFD0.Set(newASCIIElement(0x0131, "editor"))
+	if got := a.Fingerprint(); got != want {
+		t.Errorf("Fingerprint wants %s but %s", want, got)
+	}
+	a.ExifIFD.Set(newShortElement(tagPhotographicSensitivity, 800, binary.LittleEndian))
+	if got := a.Fingerprint(); got == want {
+		t.Errorf("Fingerprint with another ISO wants different from %s", want)
+	}
+}
+
+func TestAPP1_Fingerprint_Empty(t *testing.T) {
+	var a *APP1
+	if got := a.Fingerprint(); got != "" {
+		t.Errorf("Fingerprint of nil wants empty but %q", got)
+	}
+	if got := newTestAPP1(nil).Fingerprint(); got != "" {
+		t.Errorf("Fingerprint without fields wants empty but %q", got)
+	}
+}
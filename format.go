@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+// formatValue returns a human readable representation of the value.
+// Multiple values are separated by a space.
+// UNDEFINED and unknown types are shown in hex.
+func formatValue(e *IFDElement, endian binary.ByteOrder) string {
+	var values []string
+	var err error
+	switch e.Type {
+	case 1:
+		var v []byte
+		v, err = e.Bytes()
+		for _, n := range v {
+			values = append(values, fmt.Sprintf("%d", n))
+		}
+	case 2:
+		var s string
+		s, err = e.ASCII()
+		values = []string{s}
+	case 3:
+		var v []uint16
+		v, err = e.Uint16s(endian)
+		for _, n := range v {
+			values = append(values, fmt.Sprintf("%d", n))
+		}
+	case 4:
+		var v []uint32
+		v, err = e.Uint32s(endian)
+		for _, n := range v {
+			values = append(values, fmt.Sprintf("%d", n))
+		}
+	case 5:
+		var v []Rational
+		v, err = e.Rationals(endian)
+		for _, r := range v {
+			values = append(values, r.String())
+		}
+	case 9:
+		var v []int32
+		v, err = e.Int32s(endian)
+		for _, n := range v {
+			values = append(values, fmt.Sprintf("%d", n))
+		}
+	case 10:
+		var v []SRational
+		v, err = e.SRationals(endian)
+		for _, r := range v {
+			values = append(values, r.String())
+		}
+	default:
+		return formatHex(e)
+	}
+	if err != nil {
+		return formatHex(e)
+	}
+	return strings.Join(values, " ")
+}
+
+func formatHex(e *IFDElement) string {
+	n := e.Length()
+	if n > len(e.Value) {
+		n = len(e.Value)
+	}
+	return fmt.Sprintf("%x", e.Value[:n])
+}
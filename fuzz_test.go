@@ -0,0 +1,43 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+// callAccessors calls all exported methods without arguments of the value.
+func callAccessors(v interface{}) {
+	rv := reflect.ValueOf(v)
+	for i := 0; i < rv.NumMethod(); i++ {
+		if m := rv.Method(i); m.Type().NumIn() == 0 {
+			m.Call(nil)
+		}
+	}
+}
+
+func FuzzDecode(f *testing.F) {
+	for _, name := range []string{"ii.jpg", "mm.jpg", "nothumb.jpg", "noexif.jpg", "exif.tiff", "exif.heic"} {
+		f.Add(loadTestdata(f, name))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		if h, err := Decode(b); err == nil {
+			callAccessors(h)
+			if h.APP1 != nil {
+				callAccessors(h.APP1)
+			}
+		}
+		if a, err := DecodeTIFFBytes(b); err == nil {
+			callAccessors(a)
+		}
+		if a, err := DecodeHEIF(bytes.NewReader(b)); err == nil {
+			callAccessors(a)
+		}
+		tokenizer := NewTokenizer(bytes.NewReader(b))
+		for {
+			if _, err := tokenizer.Token(); err != nil {
+				break
+			}
+		}
+	})
+}
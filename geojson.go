@@ -0,0 +1,54 @@
+package main
+
+import "time"
+
+type geoJSONFeature struct {
+	Type       string                 `json:"type"`
+	Geometry   geoJSONGeometry        `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type geoJSONGeometry struct {
+	Type        string    `json:"type"`
+	Coordinates []float64 `json:"coordinates"`
+}
+
+type geoJSONFeatureCollection struct {
+	Type     string            `json:"type"`
+	Features []*geoJSONFeature `json:"features"`
+}
+
+// newGeoJSONFeature returns a Point feature of the GPS IFD, or nil if no location is present.
+// Coordinates are longitude, latitude and altitude if present.
+func newGeoJSONFeature(filename string, a *APP1) *geoJSONFeature {
+	if a == nil {
+		return nil
+	}
+	lat, lng, ok := a.LatLng()
+	if !ok {
+		return nil
+	}
+	f := &geoJSONFeature{
+		Type:       "Feature",
+		Geometry:   geoJSONGeometry{Type: "Point", Coordinates: []float64{lng, lat}},
+		Properties: map[string]interface{}{"file": filename},
+	}
+	if alt, ok := a.Altitude(); ok {
+		f.Geometry.Coordinates = append(f.Geometry.Coordinates, alt)
+	}
+	if t, ok := a.GPSTime(); ok {
+		f.Properties["timestamp"] = t.Format(time.RFC3339)
+	}
+	if direction, ref, ok := a.GPSImgDirection(); ok {
+		f.Properties["direction"] = direction
+		f.Properties["directionRef"] = ref
+	}
+	return f
+}
+
+// newGeoJSON returns a FeatureCollection of the features, which may be empty.
+// It is always a FeatureCollection regardless of the number of features,
+// so that the consumer can handle the output in the same way.
+func newGeoJSON(features []*geoJSONFeature) *geoJSONFeatureCollection {
+	return &geoJSONFeatureCollection{Type: "FeatureCollection", Features: append([]*geoJSONFeature{}, features...)}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func runGeoJSON(t *testing.T, filenames ...string) map[string]interface{} {
+	t.Helper()
+	var b bytes.Buffer
+	if err := run(nil, &b, "geojson", false, filenames); err != nil {
+		t.Fatalf("run error: %s", err)
+	}
+	var v map[string]interface{}
+	if err := json.Unmarshal(b.Bytes(), &v); err != nil {
+		t.Fatalf("Could not decode json: %s", err)
+	}
+	if v["type"] != "FeatureCollection" {
+		t.Fatalf("type wants FeatureCollection but %v", v["type"])
+	}
+	return v
+}
+
+func TestRun_GeoJSON(t *testing.T) {
+	v := runGeoJSON(t, "testdata/ii.jpg")
+	features, ok := v["features"].([]interface{})
+	if !ok || len(features) != 1 {
+		t.Fatalf("features wants 1 feature but %v", v["features"])
+	}
+	f := features[0].(map[string]interface{})
+	geometry := f["geometry"].(map[string]interface{})
+	if geometry["type"] != "Point" {
+		t.Errorf("geometry type wants Point but %v", geometry["type"])
+	}
+	coordinates := geometry["coordinates"].([]interface{})
+	if len(coordinates) != 3 {
+		t.Fatalf("coordinates wants longitude, latitude and altitude but %v", coordinates)
+	}
+	if lng, lat := coordinates[0].(float64), coordinates[1].(float64); lng < 139.75 || lng > 139.76 || lat < 35.67 || lat > 35.68 {
+		t.Errorf("coordinates wants about 139.754, 35.675 but %v", coordinates)
+	}
+	if properties := f["properties"].(map[string]interface{}); properties["file"] != "testdata/ii.jpg" {
+		t.Errorf("file wants testdata/ii.jpg but %v", properties["file"])
+	}
+}
+
+func TestRun_GeoJSON_NoGPS(t *testing.T) {
+	v := runGeoJSON(t, "testdata/nothumb.jpg", "testdata/noexif.jpg")
+	if features, ok := v["features"].([]interface{}); !ok || len(features) != 0 {
+		t.Errorf("features wants empty but %v", v["features"])
+	}
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	tagGPSVersionID         = 0x0000
+	tagGPSLatitudeRef       = 0x0001
+	tagGPSLatitude          = 0x0002
+	tagGPSLongitudeRef      = 0x0003
+	tagGPSLongitude         = 0x0004
+	tagGPSAltitudeRef       = 0x0005
+	tagGPSAltitude          = 0x0006
+	tagGPSTimeStamp         = 0x0007
+	tagGPSSatellites        = 0x0008
+	tagGPSStatus            = 0x0009
+	tagGPSMeasureMode       = 0x000A
+	tagGPSSpeedRef          = 0x000C
+	tagGPSSpeed             = 0x000D
+	tagGPSImgDirectionRef   = 0x0010
+	tagGPSImgDirection      = 0x0011
+	tagGPSMapDatum          = 0x0012
+	tagGPSDestLatitudeRef   = 0x0013
+	tagGPSDestLatitude      = 0x0014
+	tagGPSDestLongitudeRef  = 0x0015
+	tagGPSDestLongitude     = 0x0016
+	tagGPSDestBearingRef    = 0x0017
+	tagGPSDestBearing       = 0x0018
+	tagGPSProcessingMethod  = 0x001B
+	tagGPSAreaInformation   = 0x001C
+	tagGPSDateStamp         = 0x001D
+	tagGPSDifferential      = 0x001E
+	tagGPSHPositioningError = 0x001F
+)
+
+// GPSVersionID returns the version of the GPS IFD such as "2.3.0.0".
+func (a *APP1) GPSVersionID() (string, bool) {
+	b, ok := findBytes(a.GPSIFD, tagGPSVersionID)
+	if !ok || len(b) != 4 {
+		return "", false
+	}
+	return fmt.Sprintf("%d.%d.%d.%d", b[0], b[1], b[2], b[3]), true
+}
+
+// LatLng returns the latitude and longitude in degrees.
+// South and west are negative.
+func (a *APP1) LatLng() (lat, lng float64, ok bool) {
+	lat, ok = a.gpsCoordinate(tagGPSLatitude, tagGPSLatitudeRef, "S")
+	if !ok {
+		return 0, 0, false
+	}
+	lng, ok = a.gpsCoordinate(tagGPSLongitude, tagGPSLongitudeRef, "W")
+	if !ok {
+		return 0, 0, false
+	}
+	return lat, lng, true
+}
+
+// gpsCoordinate returns the degrees of the degrees, minutes and seconds tag.
+// It is negative if the reference tag is the negative reference.
+func (a *APP1) gpsCoordinate(tag, refTag uint16, negativeRef string) (float64, bool) {
+	e := a.GPSIFD.Find(tag)
+	if e == nil {
+		return 0, false
+	}
+	dms, err := e.Rationals(a.Endian)
+	if err != nil || len(dms) != 3 {
+		return 0, false
+	}
+	for _, r := range dms {
+		if r.Denominator == 0 {
+			return 0, false
+		}
+	}
+	v := dms[0].Float64() + dms[1].Float64()/60 + dms[2].Float64()/3600
+	if ref, ok := findASCII(a.GPSIFD, refTag); ok && strings.TrimSpace(ref) == negativeRef {
+		v = -v
+	}
+	return v, true
+}
+
+// Altitude returns the altitude in meters.
+// It is negative if GPSAltitudeRef indicates below the sea level.
+func (a *APP1) Altitude() (float64, bool) {
+	r, ok := findRational(a.GPSIFD, tagGPSAltitude, a.Endian)
+	if !ok || r.Denominator == 0 {
+		return 0, false
+	}
+	v := r.Float64()
+	if ref, ok := findBytes(a.GPSIFD, tagGPSAltitudeRef); ok && ref[0] == 1 {
+		v = -v
+	}
+	return v, true
+}
+
+// GPSTime returns the time in UTC from GPSDateStamp and GPSTimeStamp.
+func (a *APP1) GPSTime() (time.Time, bool) {
+	date, ok := findASCII(a.GPSIFD, tagGPSDateStamp)
+	if !ok {
+		return time.Time{}, false
+	}
+	t, err := time.ParseInLocation("2006:01:02", strings.TrimSpace(date), time.UTC)
+	if err != nil {
+		return time.Time{}, false
+	}
+	e := a.GPSIFD.Find(tagGPSTimeStamp)
+	if e == nil {
+		return time.Time{}, false
+	}
+	hms, err := e.Rationals(a.Endian)
+	if err != nil || len(hms) != 3 {
+		return time.Time{}, false
+	}
+	seconds := hms[0].Float64()*3600 + hms[1].Float64()*60 + hms[2].Float64()
+	return t.Add(time.Duration(math.Round(seconds * float64(time.Second)))), true
+}
+
+// GPSImgDirection returns the direction of the image in degrees,
+// and the reference which is "T" for true direction or "M" for magnetic direction.
+func (a *APP1) GPSImgDirection() (direction float64, ref string, ok bool) {
+	r, ok := findRational(a.GPSIFD, tagGPSImgDirection, a.Endian)
+	if !ok || r.Denominator == 0 {
+		return 0, "", false
+	}
+	ref, _ = findTrimmedASCII(a.GPSIFD, tagGPSImgDirectionRef)
+	return r.Float64(), ref, true
+}
+
+// DestLatLng returns the latitude and longitude of the destination in degrees.
+// South and west are negative.
+func (a *APP1) DestLatLng() (lat, lng float64, ok bool) {
+	lat, ok = a.gpsCoordinate(tagGPSDestLatitude, tagGPSDestLatitudeRef, "S")
+	if !ok {
+		return 0, 0, false
+	}
+	lng, ok = a.gpsCoordinate(tagGPSDestLongitude, tagGPSDestLongitudeRef, "W")
+	if !ok {
+		return 0, 0, false
+	}
+	return lat, lng, true
+}
+
+// GPSDestBearing returns the bearing to the destination in degrees,
+// and the reference which is "T" for true direction or "M" for magnetic direction.
+func (a *APP1) GPSDestBearing() (bearing float64, ref string, ok bool) {
+	r, ok := findRational(a.GPSIFD, tagGPSDestBearing, a.Endian)
+	if !ok || r.Denominator == 0 {
+		return 0, "", false
+	}
+	ref, _ = findTrimmedASCII(a.GPSIFD, tagGPSDestBearingRef)
+	return r.Float64(), ref, true
+}
+
+// GPSProcessingMethod returns the name of the method used for location finding, e.g. "GPS" or "CELLID".
+func (a *APP1) GPSProcessingMethod() (string, bool) {
+	return findEncodedString(a.GPSIFD, tagGPSProcessingMethod, a.Endian)
+}
+
+// GPSAreaInformation returns the name of the GPS area.
+func (a *APP1) GPSAreaInformation() (string, bool) {
+	return findEncodedString(a.GPSIFD, tagGPSAreaInformation, a.Endian)
+}
+
+// GPSSatellites returns the satellites used for measurement, e.g. the number of satellites.
+func (a *APP1) GPSSatellites() (string, bool) {
+	return findTrimmedASCII(a.GPSIFD, tagGPSSatellites)
+}
+
+// GPSStatus returns "A" if the measurement is in progress or "V" if it is interrupted.
+func (a *APP1) GPSStatus() (string, bool) {
+	s, ok := findTrimmedASCII(a.GPSIFD, tagGPSStatus)
+	if !ok || (s != "A" && s != "V") {
+		return "", false
+	}
+	return s, true
+}
+
+// GPSMeasureMode returns 2 for 2-dimensional or 3 for 3-dimensional measurement.
+func (a *APP1) GPSMeasureMode() (int, bool) {
+	s, ok := findTrimmedASCII(a.GPSIFD, tagGPSMeasureMode)
+	switch {
+	case !ok:
+		return 0, false
+	case s == "2":
+		return 2, true
+	case s == "3":
+		return 3, true
+	}
+	return 0, false
+}
+
+// GPSDifferential returns true if the differential correction is applied.
+func (a *APP1) GPSDifferential() (applied bool, ok bool) {
+	v, ok := findUint16(a.GPSIFD, tagGPSDifferential, a.Endian)
+	if !ok || v > 1 {
+		return false, false
+	}
+	return v == 1, true
+}
+
+// GPSHorizontalError returns the horizontal positioning error in meters, i.e. GPSHPositioningError.
+func (a *APP1) GPSHorizontalError() (float64, bool) {
+	r, ok := findRational(a.GPSIFD, tagGPSHPositioningError, a.Endian)
+	if !ok || r.Denominator == 0 {
+		return 0, false
+	}
+	return r.Float64(), true
+}
+
+// GPSSpeed returns the speed of the receiver,
+// and the unit which is "K" for km/h, "M" for mph or "N" for knots.
+func (a *APP1) GPSSpeed() (speed float64, ref string, ok bool) {
+	r, ok := findRational(a.GPSIFD, tagGPSSpeed, a.Endian)
+	if !ok || r.Denominator == 0 {
+		return 0, "", false
+	}
+	ref, _ = findTrimmedASCII(a.GPSIFD, tagGPSSpeedRef)
+	return r.Float64(), ref, true
+}
+
+// GPSMapDatum returns the geodetic survey data such as "WGS-84".
+func (a *APP1) GPSMapDatum() (string, bool) {
+	return findTrimmedASCII(a.GPSIFD, tagGPSMapDatum)
+}
+
+// GPSInfo represents the GPS IFD.
+// A field is nil or empty if the tag is not present.
+type GPSInfo struct {
+	Latitude     *float64
+	Longitude    *float64
+	Altitude     *float64
+	Time         *time.Time
+	Speed        *float64
+	SpeedRef     string
+	Direction    *float64
+	DirectionRef string
+	MapDatum     string
+}
+
+// GPS returns the GPS IFD as a struct.
+// It returns false if the GPS IFD is not present.
+func (a *APP1) GPS() (*GPSInfo, bool) {
+	if a.GPSIFD == nil {
+		return nil, false
+	}
+	var g GPSInfo
+	if lat, lng, ok := a.LatLng(); ok {
+		g.Latitude, g.Longitude = &lat, &lng
+	}
+	if altitude, ok := a.Altitude(); ok {
+		g.Altitude = &altitude
+	}
+	if t, ok := a.GPSTime(); ok {
+		g.Time = &t
+	}
+	if speed, ref, ok := a.GPSSpeed(); ok {
+		g.Speed, g.SpeedRef = &speed, ref
+	}
+	if direction, ref, ok := a.GPSImgDirection(); ok {
+		g.Direction, g.DirectionRef = &direction, ref
+	}
+	g.MapDatum, _ = a.GPSMapDatum()
+	return &g, true
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAPP1_GPSVersionID(t *testing.T) {
+	a := newTestAPP1(nil)
+	if _, ok := a.GPSVersionID(); ok {
+		t.Errorf("GPSVersionID wants false if not present")
+	}
+	a.SetElement(GPSIFDKind, newElement(tagGPSVersionID, 1, 4, []byte{2, 3, 0, 0}))
+	if v, ok := a.GPSVersionID(); !ok || v != "2.3.0.0" {
+		t.Errorf("GPSVersionID wants 2.3.0.0 but %q, %v", v, ok)
+	}
+	a.SetElement(GPSIFDKind, newElement(tagGPSVersionID, 1, 3, []byte{2, 3, 0}))
+	if v, ok := a.GPSVersionID(); ok {
+		t.Errorf("GPSVersionID of 3 bytes wants false but %q", v)
+	}
+}
+
+func TestAPP1_Altitude(t *testing.T) {
+	a := decodeTestdata(t, "ii.jpg").APP1
+	if v, ok := a.Altitude(); !ok || v != 40 {
+		t.Errorf("Altitude wants 40 but %f, %v", v, ok)
+	}
+	a.SetElement(GPSIFDKind, newElement(tagGPSAltitudeRef, 1, 1, []byte{1}))
+	if v, ok := a.Altitude(); !ok || v != -40 {
+		t.Errorf("Altitude below the sea level wants -40 but %f, %v", v, ok)
+	}
+}
+
+func TestAPP1_DestLatLng(t *testing.T) {
+	a := newTestAPP1(nil)
+	if _, _, ok := a.DestLatLng(); ok {
+		t.Errorf("DestLatLng wants false if not present")
+	}
+	a.SetElement(GPSIFDKind, newASCIIElement(tagGPSDestLatitudeRef, "S"))
+	a.SetElement(GPSIFDKind, newRationalElement(tagGPSDestLatitude, []Rational{{33, 1}, {52, 1}, {0, 1}}, a.Endian))
+	if _, _, ok := a.DestLatLng(); ok {
+		t.Errorf("DestLatLng without longitude wants false")
+	}
+	a.SetElement(GPSIFDKind, newASCIIElement(tagGPSDestLongitudeRef, "W"))
+	a.SetElement(GPSIFDKind, newRationalElement(tagGPSDestLongitude, []Rational{{151, 1}, {12, 1}, {36, 1}}, a.Endian))
+	if lat, lng, ok := a.DestLatLng(); !ok || math.Abs(lat+33.866667) > 1e-6 || math.Abs(lng+151.21) > 1e-6 {
+		t.Errorf("DestLatLng wants south and west but %f, %f, %v", lat, lng, ok)
+	}
+}
+
+func TestAPP1_GPSDestBearing(t *testing.T) {
+	a := newTestAPP1(nil)
+	if _, _, ok := a.GPSDestBearing(); ok {
+		t.Errorf("GPSDestBearing wants false if not present")
+	}
+	a.SetElement(GPSIFDKind, newASCIIElement(tagGPSDestBearingRef, "M"))
+	a.SetElement(GPSIFDKind, newRationalElement(tagGPSDestBearing, []Rational{{2705, 10}}, a.Endian))
+	if v, ref, ok := a.GPSDestBearing(); !ok || v != 270.5 || ref != "M" {
+		t.Errorf("GPSDestBearing wants 270.5 M but %f %s, %v", v, ref, ok)
+	}
+	a.SetElement(GPSIFDKind, newRationalElement(tagGPSDestBearing, []Rational{{1, 0}}, a.Endian))
+	if _, _, ok := a.GPSDestBearing(); ok {
+		t.Errorf("GPSDestBearing of zero denominator wants false")
+	}
+}
+
+func TestAPP1_GPSStatus(t *testing.T) {
+	a := newTestAPP1(nil)
+	a.SetElement(GPSIFDKind, newASCIIElement(tagGPSSatellites, "12 "))
+	a.SetElement(GPSIFDKind, newASCIIElement(tagGPSStatus, "A"))
+	a.SetElement(GPSIFDKind, newASCIIElement(tagGPSMeasureMode, "3"))
+	a.SetElement(GPSIFDKind, newShortElement(tagGPSDifferential, 1, a.Endian))
+	if v, ok := a.GPSSatellites(); !ok || v != "12" {
+		t.Errorf("GPSSatellites wants 12 but %q, %v", v, ok)
+	}
+	if v, ok := a.GPSStatus(); !ok || v != "A" {
+		t.Errorf("GPSStatus wants A but %q, %v", v, ok)
+	}
+	if v, ok := a.GPSMeasureMode(); !ok || v != 3 {
+		t.Errorf("GPSMeasureMode wants 3 but %d, %v", v, ok)
+	}
+	if v, ok := a.GPSDifferential(); !ok || !v {
+		t.Errorf("GPSDifferential wants true but %v, %v", v, ok)
+	}
+}
+
+func TestAPP1_GPSStatus_Invalid(t *testing.T) {
+	a := newTestAPP1(nil)
+	a.SetElement(GPSIFDKind, newASCIIElement(tagGPSStatus, "X"))
+	a.SetElement(GPSIFDKind, newASCIIElement(tagGPSMeasureMode, "4"))
+	a.SetElement(GPSIFDKind, newShortElement(tagGPSDifferential, 2, a.Endian))
+	if v, ok := a.GPSStatus(); ok {
+		t.Errorf("GPSStatus of X wants false but %q", v)
+	}
+	if v, ok := a.GPSMeasureMode(); ok {
+		t.Errorf("GPSMeasureMode of 4 wants false but %d", v)
+	}
+	if v, ok := a.GPSDifferential(); ok {
+		t.Errorf("GPSDifferential of 2 wants false but %v", v)
+	}
+}
+
+func TestAPP1_GPS(t *testing.T) {
+	a := decodeTestdata(t, "ii.jpg").APP1
+	a.SetElement(GPSIFDKind, newASCIIElement(tagGPSMapDatum, "WGS-84"))
+	g, ok := a.GPS()
+	if !ok {
+		t.Fatalf("GPS wants true")
+	}
+	if g.Latitude == nil || *g.Latitude != 35.675 || g.Longitude == nil || math.Abs(*g.Longitude-139.754167) > 1e-6 {
+		t.Errorf("Latitude and Longitude want 35.675, 139.754167 but %v, %v", g.Latitude, g.Longitude)
+	}
+	if g.Altitude == nil || *g.Altitude != 40 {
+		t.Errorf("Altitude wants 40 but %v", g.Altitude)
+	}
+	if g.Time != nil || g.Speed != nil || g.Direction != nil {
+		t.Errorf("Time, Speed and Direction want nil but %v, %v, %v", g.Time, g.Speed, g.Direction)
+	}
+	if g.MapDatum != "WGS-84" {
+		t.Errorf("MapDatum wants WGS-84 but %q", g.MapDatum)
+	}
+	if g, ok := decodeTestdata(t, "nothumb.jpg").APP1.GPS(); ok {
+		t.Errorf("GPS wants false without the GPS IFD but %+v", g)
+	}
+}
+
+func TestAPP1_GPSHorizontalError(t *testing.T) {
+	a := newTestAPP1(nil)
+	if _, ok := a.GPSHorizontalError(); ok {
+		t.Errorf("GPSHorizontalError wants false if not present")
+	}
+	a.SetElement(GPSIFDKind, newRationalElement(tagGPSHPositioningError, []Rational{{125, 10}}, a.Endian))
+	if v, ok := a.GPSHorizontalError(); !ok || v != 12.5 {
+		t.Errorf("GPSHorizontalError wants 12.5 but %f, %v", v, ok)
+	}
+	a.SetElement(GPSIFDKind, newRationalElement(tagGPSHPositioningError, []Rational{{1, 0}}, a.Endian))
+	if _, ok := a.GPSHorizontalError(); ok {
+		t.Errorf("GPSHorizontalError of zero denominator wants false")
+	}
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// box is a box of ISO BMFF, i.e. HEIF or AVIF.
+type box struct {
+	typ  string
+	data []byte // without the size and type
+}
+
+// parseBoxes parses the sequence of boxes.
+func parseBoxes(b []byte) ([]box, error) {
+	var boxes []box
+	c, err := newCursor(b, 0, binary.BigEndian)
+	if err != nil {
+		return nil, err
+	}
+	for c.Offset() < len(b) {
+		start := c.Offset()
+		size32, err := c.ReadUint32()
+		if err != nil {
+			return nil, fmt.Errorf("Could not read box size: %s", err)
+		}
+		typ, err := c.ReadBytes(4)
+		if err != nil {
+			return nil, fmt.Errorf("Could not read box type: %s", err)
+		}
+		size := uint64(size32)
+		switch size {
+		case 0:
+			// the box extends to the end
+			size = uint64(len(b) - start)
+		case 1:
+			largeSize, err := readUintN(c, 8)
+			if err != nil {
+				return nil, fmt.Errorf("Could not read large size of box %s: %s", typ, err)
+			}
+			size = largeSize
+		}
+		header := uint64(c.Offset() - start)
+		if size < header || size-header > uint64(len(b)-c.Offset()) {
+			return nil, fmt.Errorf("Box %s at 0x%x has invalid size %d", typ, start, size)
+		}
+		data, err := c.ReadBytes(int(size - header))
+		if err != nil {
+			return nil, err
+		}
+		boxes = append(boxes, box{typ: string(typ), data: data})
+	}
+	return boxes, nil
+}
+
+func findBox(boxes []box, typ string) (box, bool) {
+	for _, b := range boxes {
+		if b.typ == typ {
+			return b, true
+		}
+	}
+	return box{}, false
+}
+
+// readUintN reads the big endian unsigned integer of 0, 2, 4 or 8 bytes.
+func readUintN(c *cursor, n int) (uint64, error) {
+	b, err := c.ReadBytes(n)
+	if err != nil {
+		return 0, err
+	}
+	switch n {
+	case 0:
+		return 0, nil
+	case 2:
+		return uint64(binary.BigEndian.Uint16(b)), nil
+	case 4:
+		return uint64(binary.BigEndian.Uint32(b)), nil
+	case 8:
+		return binary.BigEndian.Uint64(b), nil
+	}
+	return 0, fmt.Errorf("Integer size must be 0, 2, 4 or 8 but got %d", n)
+}
+
+// findExifItemID returns the ID of the item of type "Exif" in the iinf box.
+func findExifItemID(iinf []byte) (uint32, error) {
+	c, err := newCursor(iinf, 0, binary.BigEndian)
+	if err != nil {
+		return 0, err
+	}
+	versionFlags, err := c.ReadUint32()
+	if err != nil {
+		return 0, err
+	}
+	countSize := 2
+	if versionFlags>>24 != 0 {
+		countSize = 4
+	}
+	if _, err := readUintN(c, countSize); err != nil {
+		return 0, fmt.Errorf("Could not read entry count: %s", err)
+	}
+	entries, err := parseBoxes(iinf[c.Offset():])
+	if err != nil {
+		return 0, fmt.Errorf("Could not parse item info entries: %s", err)
+	}
+	for _, e := range entries {
+		if e.typ != "infe" {
+			continue
+		}
+		ec, err := newCursor(e.data, 0, binary.BigEndian)
+		if err != nil {
+			return 0, err
+		}
+		versionFlags, err := ec.ReadUint32()
+		if err != nil {
+			return 0, err
+		}
+		version := versionFlags >> 24
+		if version < 2 {
+			// item_type is available since version 2
+			continue
+		}
+		idSize := 2
+		if version >= 3 {
+			idSize = 4
+		}
+		id, err := readUintN(ec, idSize)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := ec.ReadUint16(); err != nil {
+			return 0, err
+		}
+		itemType, err := ec.ReadBytes(4)
+		if err != nil {
+			return 0, err
+		}
+		if string(itemType) == "Exif" {
+			return uint32(id), nil
+		}
+	}
+	return 0, fmt.Errorf("Exif item not found")
+}
+
+// findItemExtents returns the file offsets and lengths of the extents of the item in the iloc box.
+func findItemExtents(iloc []byte, itemID uint32) ([][2]uint64, error) {
+	c, err := newCursor(iloc, 0, binary.BigEndian)
+	if err != nil {
+		return nil, err
+	}
+	versionFlags, err := c.ReadUint32()
+	if err != nil {
+		return nil, err
+	}
+	version := versionFlags >> 24
+	sizes, err := c.ReadUint16()
+	if err != nil {
+		return nil, err
+	}
+	offsetSize, lengthSize, baseOffsetSize := int(sizes>>12), int(sizes>>8&0xf), int(sizes>>4&0xf)
+	var indexSize int
+	if version == 1 || version == 2 {
+		indexSize = int(sizes & 0xf)
+	}
+	countSize := 2
+	if version == 2 {
+		countSize = 4
+	}
+	count, err := readUintN(c, countSize)
+	if err != nil {
+		return nil, err
+	}
+	for i := uint64(0); i < count; i++ {
+		id, err := readUintN(c, countSize)
+		if err != nil {
+			return nil, err
+		}
+		var constructionMethod uint16
+		if version == 1 || version == 2 {
+			if constructionMethod, err = c.ReadUint16(); err != nil {
+				return nil, err
+			}
+			constructionMethod &= 0xf
+		}
+		if _, err := c.ReadUint16(); err != nil {
+			return nil, err
+		}
+		baseOffset, err := readUintN(c, baseOffsetSize)
+		if err != nil {
+			return nil, err
+		}
+		extentCount, err := c.ReadUint16()
+		if err != nil {
+			return nil, err
+		}
+		var extents [][2]uint64
+		for j := 0; j < int(extentCount); j++ {
+			if _, err := readUintN(c, indexSize); err != nil {
+				return nil, err
+			}
+			offset, err := readUintN(c, offsetSize)
+			if err != nil {
+				return nil, err
+			}
+			length, err := readUintN(c, lengthSize)
+			if err != nil {
+				return nil, err
+			}
+			extents = append(extents, [2]uint64{baseOffset + offset, length})
+		}
+		if uint32(id) == itemID {
+			if constructionMethod != 0 {
+				return nil, fmt.Errorf("Construction method %d is not supported", constructionMethod)
+			}
+			return extents, nil
+		}
+	}
+	return nil, fmt.Errorf("Location of item %d not found", itemID)
+}
+
+// DecodeHEIF parses the Exif item in a HEIF or AVIF file.
+// The item starts with the 4 bytes offset to the TIFF header, which is usually after "Exif\0\0".
+func DecodeHEIF(r io.Reader) (*APP1, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("Could not read: %s", err)
+	}
+	boxes, err := parseBoxes(b)
+	if err != nil {
+		return nil, fmt.Errorf("Could not parse boxes: %s", err)
+	}
+	meta, ok := findBox(boxes, "meta")
+	if !ok {
+		return nil, fmt.Errorf("meta box not found")
+	}
+	if len(meta.data) < 4 {
+		return nil, fmt.Errorf("meta box is too short")
+	}
+	// meta is a full box with the version and flags
+	metaBoxes, err := parseBoxes(meta.data[4:])
+	if err != nil {
+		return nil, fmt.Errorf("Could not parse meta box: %s", err)
+	}
+	iinf, ok := findBox(metaBoxes, "iinf")
+	if !ok {
+		return nil, fmt.Errorf("iinf box not found")
+	}
+	iloc, ok := findBox(metaBoxes, "iloc")
+	if !ok {
+		return nil, fmt.Errorf("iloc box not found")
+	}
+	itemID, err := findExifItemID(iinf.data)
+	if err != nil {
+		return nil, fmt.Errorf("Could not parse iinf box: %s", err)
+	}
+	extents, err := findItemExtents(iloc.data, itemID)
+	if err != nil {
+		return nil, fmt.Errorf("Could not parse iloc box: %s", err)
+	}
+	var item bytes.Buffer
+	for _, extent := range extents {
+		offset, length := extent[0], extent[1]
+		if offset > uint64(len(b)) || length > uint64(len(b))-offset {
+			return nil, fmt.Errorf("Extent at 0x%x (%d bytes) is out of file", offset, length)
+		}
+		item.Write(b[offset : offset+length])
+	}
+	data := item.Bytes()
+	if len(data) < 4 {
+		return nil, fmt.Errorf("Exif item is too short")
+	}
+	tiffOffset := uint64(binary.BigEndian.Uint32(data[0:4]))
+	if tiffOffset > uint64(len(data)-4) {
+		return nil, fmt.Errorf("TIFF header offset %d is out of Exif item", tiffOffset)
+	}
+	return parseTIFF(data[4+tiffOffset:], DecodeOptions{})
+}
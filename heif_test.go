@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDecodeHEIF(t *testing.T) {
+	a, err := DecodeHEIF(bytes.NewReader(loadTestdata(t, "exif.heic")))
+	if err != nil {
+		t.Fatalf("DecodeHEIF error: %s", err)
+	}
+	if v, ok := a.Model(); !ok || v != "Canon EOS 5D Mark III" {
+		t.Errorf("Model wants Canon EOS 5D Mark III but %q", v)
+	}
+	if o, ok := a.Orientation(); !ok || o != 6 {
+		t.Errorf("Orientation wants 6 but %d", o)
+	}
+}
+
+func TestDecodeHEIF_Invalid(t *testing.T) {
+	heic := loadTestdata(t, "exif.heic")
+	for _, c := range []struct {
+		name string
+		b    []byte
+		want string
+	}{
+		{"JPEG", loadTestdata(t, "ii.jpg"), "Could not parse boxes"},
+		{"no meta", []byte("\x00\x00\x00\x10ftypheic\x00\x00\x00\x00"), "meta box not found"},
+		{"truncated", heic[:len(heic)-1], ""},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := DecodeHEIF(bytes.NewReader(c.b))
+			if err == nil || !strings.Contains(err.Error(), c.want) {
+				t.Errorf("DecodeHEIF wants error %q but %v", c.want, err)
+			}
+		})
+	}
+}
+
+func TestParseBoxes(t *testing.T) {
+	// a box of size 0 extends to the end, and a box of size 1 has the large size
+	b := []byte("\x00\x00\x00\x01free\x00\x00\x00\x00\x00\x00\x00\x12ab" + "\x00\x00\x00\x00skipxyz")
+	boxes, err := parseBoxes(b)
+	if err != nil {
+		t.Fatalf("parseBoxes error: %s", err)
+	}
+	if len(boxes) != 2 || boxes[0].typ != "free" || string(boxes[0].data) != "ab" || boxes[1].typ != "skip" || string(boxes[1].data) != "xyz" {
+		t.Errorf("parseBoxes wants free and skip but %+v", boxes)
+	}
+	if _, err := parseBoxes([]byte("\x00\x00\x00\x04free")); err == nil {
+		t.Errorf("parseBoxes of too small size wants error")
+	}
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	tagMake                  = 0x010F
+	tagModel                 = 0x0110
+	tagOrientation           = 0x0112
+	tagXResolution           = 0x011A
+	tagYResolution           = 0x011B
+	tagResolutionUnit        = 0x0128
+	tagArtist                = 0x013B
+	tagHostComputer          = 0x013C
+	tagWhitePoint            = 0x013E
+	tagPrimaryChromaticities = 0x013F
+	tagYCbCrSubSampling      = 0x0212
+	tagYCbCrPositioning      = 0x0213
+	tagRating                = 0x4746
+	tagRatingPercent         = 0x4749
+	tagCopyright             = 0x8298
+)
+
+// Make returns the manufacturer of the camera.
+func (a *APP1) Make() (string, bool) {
+	return findTrimmedASCII(a.IFD0, tagMake)
+}
+
+// Model returns the model name of the camera.
+func (a *APP1) Model() (string, bool) {
+	return findTrimmedASCII(a.IFD0, tagModel)
+}
+
+// Rating returns the star rating (0-5) written by Windows and photo managers.
+func (a *APP1) Rating() (int, bool) {
+	v, ok := findUint16(a.IFD0, tagRating, a.Endian)
+	return int(v), ok
+}
+
+// RatingPercent returns the rating in percent (0-100).
+func (a *APP1) RatingPercent() (int, bool) {
+	v, ok := findUint16(a.IFD0, tagRatingPercent, a.Endian)
+	return int(v), ok
+}
+
+// YCbCrSubSampling returns the horizontal and vertical subsampling factors of chroma,
+// e.g. 2 and 1 for 4:2:2.
+func (a *APP1) YCbCrSubSampling() (horizontal, vertical int, ok bool) {
+	e := a.IFD0.Find(tagYCbCrSubSampling)
+	if e == nil {
+		return 0, 0, false
+	}
+	values, err := e.Uint16s(a.Endian)
+	if err != nil || len(values) != 2 {
+		return 0, 0, false
+	}
+	return int(values[0]), int(values[1]), true
+}
+
+// Artist returns the name of the camera owner, photographer or image creator.
+func (a *APP1) Artist() (string, bool) {
+	return findTrimmedASCII(a.IFD0, tagArtist)
+}
+
+// HostComputer returns the computer or operating system used to create the image.
+func (a *APP1) HostComputer() (string, bool) {
+	return findTrimmedASCII(a.IFD0, tagHostComputer)
+}
+
+// Copyright returns the photographer and editor copyright.
+// The value consists of two NUL separated parts and either may be empty.
+func (a *APP1) Copyright() (photographer, editor string, ok bool) {
+	e := a.IFD0.Find(tagCopyright)
+	if e == nil || e.Count == 0 {
+		return "", "", false
+	}
+	b, err := e.asciiBytes()
+	if err != nil {
+		return "", "", false
+	}
+	parts := strings.SplitN(strings.TrimRight(string(b), "\x00"), "\x00", 2)
+	photographer = strings.TrimSpace(parts[0])
+	if len(parts) > 1 {
+		editor = strings.TrimSpace(strings.TrimRight(parts[1], "\x00"))
+	}
+	return photographer, editor, true
+}
+
+// ResolutionUnit is the unit of XResolution and YResolution.
+type ResolutionUnit uint16
+
+const (
+	ResolutionUnitNone       ResolutionUnit = 1
+	ResolutionUnitInch       ResolutionUnit = 2
+	ResolutionUnitCentimeter ResolutionUnit = 3
+)
+
+var resolutionUnitNames = map[ResolutionUnit]string{
+	ResolutionUnitNone:       "None",
+	ResolutionUnitInch:       "Inch",
+	ResolutionUnitCentimeter: "Centimeter",
+}
+
+func (u ResolutionUnit) String() string {
+	if s, ok := resolutionUnitNames[u]; ok {
+		return s
+	}
+	return fmt.Sprintf("ResolutionUnit(%d)", uint16(u))
+}
+
+// XResolution returns the number of pixels per ResolutionUnit in the width direction.
+// It returns false if the denominator is zero.
+func (a *APP1) XResolution() (float64, bool) {
+	r, ok := findRational(a.IFD0, tagXResolution, a.Endian)
+	if !ok || r.Denominator == 0 {
+		return 0, false
+	}
+	return r.Float64(), true
+}
+
+// YResolution returns the number of pixels per ResolutionUnit in the height direction.
+// It returns false if the denominator is zero.
+func (a *APP1) YResolution() (float64, bool) {
+	r, ok := findRational(a.IFD0, tagYResolution, a.Endian)
+	if !ok || r.Denominator == 0 {
+		return 0, false
+	}
+	return r.Float64(), true
+}
+
+// ResolutionUnit returns the unit of the resolution.
+// It returns inch if the tag is not present, which is the default in the spec.
+func (a *APP1) ResolutionUnit() ResolutionUnit {
+	if v, ok := findUint16(a.IFD0, tagResolutionUnit, a.Endian); ok {
+		return ResolutionUnit(v)
+	}
+	return ResolutionUnitInch
+}
+
+// YCbCrPositioning is the position of chrominance components relative to luminance samples.
+type YCbCrPositioning uint16
+
+const (
+	YCbCrPositioningCentered YCbCrPositioning = 1
+	YCbCrPositioningCoSited  YCbCrPositioning = 2
+)
+
+var ycbcrPositioningNames = map[YCbCrPositioning]string{
+	YCbCrPositioningCentered: "Centered",
+	YCbCrPositioningCoSited:  "Co-sited",
+}
+
+func (p YCbCrPositioning) String() string {
+	if s, ok := ycbcrPositioningNames[p]; ok {
+		return s
+	}
+	return fmt.Sprintf("YCbCrPositioning(%d)", uint16(p))
+}
+
+// YCbCrPositioning returns the YCbCrPositioning tag.
+func (a *APP1) YCbCrPositioning() (YCbCrPositioning, bool) {
+	v, ok := findUint16(a.IFD0, tagYCbCrPositioning, a.Endian)
+	return YCbCrPositioning(v), ok
+}
+
+// Orientation is the orientation of the image with respect to the rows and columns.
+type Orientation uint16
+
+var orientationNames = map[Orientation]string{
+	1: "Normal",
+	2: "Mirror horizontal",
+	3: "Rotate 180",
+	4: "Mirror vertical",
+	5: "Mirror horizontal and rotate 270 CW",
+	6: "Rotate 90 CW",
+	7: "Mirror horizontal and rotate 90 CW",
+	8: "Rotate 270 CW",
+}
+
+func (o Orientation) String() string {
+	if s, ok := orientationNames[o]; ok {
+		return s
+	}
+	return fmt.Sprintf("Orientation(%d)", uint16(o))
+}
+
+// Orientation returns the Orientation tag in the 0th IFD.
+func (a *APP1) Orientation() (Orientation, bool) {
+	v, ok := findUint16(a.IFD0, tagOrientation, a.Endian)
+	return Orientation(v), ok
+}
+
+// WhitePoint returns the chromaticity x and y of the white point.
+func (a *APP1) WhitePoint() (x, y float64, ok bool) {
+	v, ok := findFloat64s(a.IFD0, tagWhitePoint, 2, a.Endian)
+	if !ok {
+		return 0, 0, false
+	}
+	return v[0], v[1], true
+}
+
+// PrimaryChromaticities returns the chromaticities x and y of red, green and blue,
+// i.e. [red x, red y, green x, green y, blue x, blue y].
+func (a *APP1) PrimaryChromaticities() ([]float64, bool) {
+	return findFloat64s(a.IFD0, tagPrimaryChromaticities, 6, a.Endian)
+}
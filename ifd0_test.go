@@ -0,0 +1,170 @@
+package main
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+func TestAPP1_XResolution(t *testing.T) {
+	h := decodeTestdata(t, "ii.jpg")
+	if v, ok := h.APP1.XResolution(); !ok || v != 72 {
+		t.Errorf("XResolution wants 72 but %v, %v", v, ok)
+	}
+	if v, ok := h.APP1.YResolution(); !ok || v != 72 {
+		t.Errorf("YResolution wants 72 but %v, %v", v, ok)
+	}
+}
+
+func TestAPP1_XResolution_ZeroDenominator(t *testing.T) {
+	h := decodeTestdata(t, "ii.jpg")
+	h.APP1.IFD0.Set(newRationalElement(tagXResolution, []Rational{{72, 0}}, h.APP1.Endian))
+	h.APP1.IFD0.Set(newRationalElement(tagYResolution, []Rational{{72, 0}}, h.APP1.Endian))
+	if v, ok := h.APP1.XResolution(); ok {
+		t.Errorf("XResolution wants false but %v", v)
+	}
+	if v, ok := h.APP1.YResolution(); ok {
+		t.Errorf("YResolution wants false but %v", v)
+	}
+}
+
+func TestAPP1_ResolutionUnit(t *testing.T) {
+	h := decodeTestdata(t, "ii.jpg")
+	if u := h.APP1.ResolutionUnit(); u != ResolutionUnitInch {
+		t.Errorf("ResolutionUnit wants inch but %s", u)
+	}
+}
+
+func TestAPP1_Rating(t *testing.T) {
+	a := newTestAPP1(nil)
+	if _, ok := a.Rating(); ok {
+		t.Errorf("Rating wants false if not present")
+	}
+	a.IFD0.Set(newShortElement(tagRating, 4, a.Endian))
+	a.IFD0.Set(newShortElement(tagRatingPercent, 75, a.Endian))
+	if v, ok := a.Rating(); !ok || v != 4 {
+		t.Errorf("Rating wants 4 but %d", v)
+	}
+	if v, ok := a.RatingPercent(); !ok || v != 75 {
+		t.Errorf("RatingPercent wants 75 but %d", v)
+	}
+}
+
+func TestAPP1_YCbCrSubSampling(t *testing.T) {
+	for _, c := range []struct {
+		name   string
+		endian binary.ByteOrder
+		value  []byte
+	}{
+		{"II", binary.LittleEndian, []byte{2, 0, 1, 0}},
+		{"MM", binary.BigEndian, []byte{0, 2, 0, 1}},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			a := &APP1{Endian: c.endian, IFD0: &IFD{}}
+			a.IFD0.Set(newElement(tagYCbCrSubSampling, 3, 2, c.value))
+			if h, v, ok := a.YCbCrSubSampling(); !ok || h != 2 || v != 1 {
+				t.Errorf("YCbCrSubSampling wants 2, 1 but %d, %d, %v", h, v, ok)
+			}
+		})
+	}
+}
+
+func TestAPP1_YCbCrSubSampling_Invalid(t *testing.T) {
+	a := newTestAPP1(nil)
+	if _, _, ok := a.YCbCrSubSampling(); ok {
+		t.Errorf("YCbCrSubSampling wants false if not present")
+	}
+	a.IFD0.Set(newShortElement(tagYCbCrSubSampling, 2, a.Endian))
+	if _, _, ok := a.YCbCrSubSampling(); ok {
+		t.Errorf("YCbCrSubSampling of a single value wants false")
+	}
+}
+
+func TestAPP1_YCbCrSubSampling_Parsed(t *testing.T) {
+	for _, name := range []string{"ii.jpg", "mm.jpg"} {
+		t.Run(name, func(t *testing.T) {
+			h := decodeTestdata(t, name)
+			b := make([]byte, 4)
+			h.APP1.Endian.PutUint16(b[0:], 2)
+			h.APP1.Endian.PutUint16(b[2:], 2)
+			h.APP1.IFD0.Set(newElement(tagYCbCrSubSampling, 3, 2, b))
+			decoded := reencode(t, h)
+			if h, v, ok := decoded.APP1.YCbCrSubSampling(); !ok || h != 2 || v != 2 {
+				t.Errorf("YCbCrSubSampling wants 2, 2 but %d, %d, %v", h, v, ok)
+			}
+		})
+	}
+}
+
+func TestAPP1_Artist(t *testing.T) {
+	a := newTestAPP1(map[uint16]string{
+		tagArtist:       " Jane Doe ",
+		tagHostComputer: "Mac OS X",
+		tagCopyright:    "Jane Doe\x00Editor Inc.",
+	})
+	if v, ok := a.Artist(); !ok || v != "Jane Doe" {
+		t.Errorf("Artist wants Jane Doe but %q", v)
+	}
+	if v, ok := a.HostComputer(); !ok || v != "Mac OS X" {
+		t.Errorf("HostComputer wants Mac OS X but %q", v)
+	}
+	if p, e, ok := a.Copyright(); !ok || p != "Jane Doe" || e != "Editor Inc." {
+		t.Errorf("Copyright wants Jane Doe, Editor Inc. but %q, %q", p, e)
+	}
+}
+
+func TestAPP1_Copyright(t *testing.T) {
+	for _, c := range []struct {
+		value                string
+		photographer, editor string
+	}{
+		{"Jane Doe", "Jane Doe", ""},
+		{" \x00Editor Inc.", "", "Editor Inc."},
+		{"Jane Doe\x00", "Jane Doe", ""},
+	} {
+		p, e, ok := newTestAPP1(map[uint16]string{tagCopyright: c.value}).Copyright()
+		if !ok || p != c.photographer || e != c.editor {
+			t.Errorf("Copyright of %q wants %q, %q but %q, %q", c.value, c.photographer, c.editor, p, e)
+		}
+	}
+	if _, _, ok := newTestAPP1(nil).Copyright(); ok {
+		t.Errorf("Copyright wants false if not present")
+	}
+}
+
+func TestAPP1_YCbCrPositioning(t *testing.T) {
+	a := newTestAPP1(nil)
+	if _, ok := a.YCbCrPositioning(); ok {
+		t.Errorf("YCbCrPositioning wants false if not present")
+	}
+	a.IFD0.Set(newShortElement(tagYCbCrPositioning, 2, a.Endian))
+	if v, ok := a.YCbCrPositioning(); !ok || v != YCbCrPositioningCoSited {
+		t.Errorf("YCbCrPositioning wants co-sited but %s", v)
+	}
+	if s := YCbCrPositioning(9).String(); s != "YCbCrPositioning(9)" {
+		t.Errorf("String of unknown value wants YCbCrPositioning(9) but %s", s)
+	}
+}
+
+func TestAPP1_WhitePoint(t *testing.T) {
+	a := newTestAPP1(nil)
+	if _, _, ok := a.WhitePoint(); ok {
+		t.Errorf("WhitePoint wants false if not present")
+	}
+	a.SetElement(IFD0Kind, newRationalElement(tagWhitePoint, []Rational{{3127, 10000}, {329, 1000}}, a.Endian))
+	if x, y, ok := a.WhitePoint(); !ok || x != 0.3127 || y != 0.329 {
+		t.Errorf("WhitePoint wants D65 but %f, %f, %v", x, y, ok)
+	}
+	a.SetElement(IFD0Kind, newRationalElement(tagPrimaryChromaticities, []Rational{{64, 100}, {33, 100}, {30, 100}, {60, 100}, {15, 100}, {6, 100}}, a.Endian))
+	if v, ok := a.PrimaryChromaticities(); !ok || !reflect.DeepEqual(v, []float64{0.64, 0.33, 0.3, 0.6, 0.15, 0.06}) {
+		t.Errorf("PrimaryChromaticities wants sRGB but %v, %v", v, ok)
+	}
+	a.SetElement(IFD0Kind, newRationalElement(tagPrimaryChromaticities, []Rational{{64, 100}, {33, 0}}, a.Endian))
+	if v, ok := a.PrimaryChromaticities(); ok {
+		t.Errorf("PrimaryChromaticities of 2 values wants false but %v", v)
+	}
+	a.SetElement(IFD0Kind, newRationalElement(tagWhitePoint, []Rational{{3127, 10000}, {329, 0}}, a.Endian))
+	if _, _, ok := a.WhitePoint(); ok {
+		t.Errorf("WhitePoint of zero denominator wants false")
+	}
+}
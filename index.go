@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+// sliceReader reads the bytes by slicing without copying.
+type sliceReader struct {
+	b      []byte
+	offset int
+}
+
+func (s *sliceReader) Read(p []byte) (int, error) {
+	if len(p) > 0 && s.offset >= len(s.b) {
+		return 0, io.EOF
+	}
+	n := copy(p, s.b[s.offset:])
+	s.offset += n
+	return n, nil
+}
+
+// readSlice is a readFunc which returns a slice of the backing bytes.
+// The capacity is limited so that appending to the slice does not overwrite the bytes.
+func (s *sliceReader) readSlice(r io.Reader, length int) ([]byte, error) {
+	if remaining := len(s.b) - s.offset; length > remaining {
+		return nil, fmt.Errorf("Could not read %d bytes: got %d bytes", length, remaining)
+	}
+	b := s.b[s.offset : s.offset+length : s.offset+length]
+	s.offset += length
+	return b, nil
+}
+
+// NewFromBytes parses the header in the bytes and builds the index of elements for Lookup.
+// The header refers to the bytes without copying, so the caller must not modify them.
+// This is suitable for keeping the metadata of a file in memory and querying it many times.
+func NewFromBytes(b []byte) (*JPEGHeader, error) {
+	s := &sliceReader{b: b}
+	h, err := parseJPEGHeader(s, s.readSlice, DecodeOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("Could not parse JPEG header: %s", err)
+	}
+	h.buildIndex()
+	return h, nil
+}
+
+// indexKey returns the key of the index for the IFD and tag.
+func indexKey(kind IFDKind, tag uint16) uint32 {
+	return uint32(kind)<<16 | uint32(tag)
+}
+
+// buildIndex indexes the elements by IFD and tag.
+// The first element wins if a tag appears more than once, as same as IFD.Find.
+func (h *JPEGHeader) buildIndex() {
+	h.index = make(map[uint32]*IFDElement)
+	h.APP1.Walk(func(kind IFDKind, e *IFDElement) {
+		if _, ok := h.index[indexKey(kind, e.Tag)]; !ok {
+			h.index[indexKey(kind, e.Tag)] = e
+		}
+	})
+}
+
+// Lookup returns the element of the tag in the IFD, or nil if not found.
+// It uses the index if the header is parsed by NewFromBytes, otherwise scans the IFD.
+// The index is not updated when the elements are changed.
+func (h *JPEGHeader) Lookup(kind IFDKind, tag uint16) *IFDElement {
+	if h.index != nil {
+		return h.index[indexKey(kind, tag)]
+	}
+	if h.APP1 == nil {
+		return nil
+	}
+	return h.APP1.IFD(kind).Find(tag)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestNewFromBytes(t *testing.T) {
+	b := loadTestdata(t, "ii.jpg")
+	h, err := NewFromBytes(b)
+	if err != nil {
+		t.Fatalf("NewFromBytes error: %s", err)
+	}
+	decoded := decodeTestdata(t, "ii.jpg")
+	decoded.APP1.Walk(func(kind IFDKind, e *IFDElement) {
+		got := h.Lookup(kind, e.Tag)
+		if got == nil || got.Tag != e.Tag || string(got.Value) != string(e.Value) {
+			t.Errorf("Lookup(%s, 0x%04X) wants %+v but %+v", kind, e.Tag, e, got)
+		}
+		if want := decoded.Lookup(kind, e.Tag); want == nil || want.Tag != e.Tag {
+			t.Errorf("Lookup without index (%s, 0x%04X) wants %+v but %+v", kind, e.Tag, e, want)
+		}
+	})
+	if e := h.Lookup(GPSIFDKind, 0x9999); e != nil {
+		t.Errorf("Lookup of missing tag wants nil but %+v", e)
+	}
+}
+
+func TestNewFromBytes_NoExif(t *testing.T) {
+	h, err := NewFromBytes(loadTestdata(t, "noexif.jpg"))
+	if err != nil {
+		t.Fatalf("NewFromBytes error: %s", err)
+	}
+	if e := h.Lookup(IFD0Kind, tagMake); e != nil {
+		t.Errorf("Lookup wants nil but %+v", e)
+	}
+}
+
+func BenchmarkJPEGHeader_Lookup(b *testing.B) {
+	for _, n := range []int{0, 100, 1000} {
+		h, err := NewFromBytes(loadTestdata(b, "ii.jpg"))
+		if err != nil {
+			b.Fatal(err)
+		}
+		// pad the Exif IFD with unknown tags before the tag to find
+		for i := 0; i < n; i++ {
+			h.APP1.ExifIFD.Set(newShortElement(uint16(0xc000+i), 0, h.APP1.Endian))
+		}
+		tag := uint16(0xffff)
+		h.APP1.ExifIFD.Set(newShortElement(tag, 0, h.APP1.Endian))
+		h.buildIndex()
+		b.Run(fmt.Sprintf("Lookup/%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if h.Lookup(ExifIFDKind, tag) == nil {
+					b.Fatal("tag not found")
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("Find/%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if h.APP1.ExifIFD.Find(tag) == nil {
+					b.Fatal("tag not found")
+				}
+			}
+		})
+	}
+}
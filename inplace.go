@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// editKinds are the IFDs searched for a tag to edit.
+var editKinds = []IFDKind{IFD0Kind, ExifIFDKind, GPSIFDKind, InteroperabilityIFDKind}
+
+// EditInPlace changes the values of the tags in the file.
+// A value is the raw bytes in the byte order of the file, e.g. 2 bytes of SHORT for Orientation.
+// The type and count are taken from the existing element, or the tag table for a new tag.
+// A tag is searched in order of 0th IFD, Exif IFD, GPS IFD and Interoperability IFD.
+//
+// If all tags exist and have an inline value of the same length, the bytes are overwritten in place.
+// Otherwise the header is re-encoded and the whole file is rewritten.
+// A tag which does not exist is added to the IFD in the tag table, or the 0th IFD as UNDEFINED if unknown.
+// The file is truncated if it shrinks and the writer has Truncate(int64) error.
+func EditInPlace(rws io.ReadWriteSeeker, changes map[uint16][]byte) error {
+	if _, err := rws.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("Could not seek: %s", err)
+	}
+	h, err := parseJPEGHeader(rws, readBytes, DecodeOptions{})
+	if err != nil {
+		return fmt.Errorf("Could not parse JPEG header: %s", err)
+	}
+	if h.APP1 == nil {
+		return fmt.Errorf("Exif not found")
+	}
+	if offsets, ok := inlineValueOffsets(h, changes); ok {
+		for tag, offset := range offsets {
+			if _, err := rws.Seek(offset, io.SeekStart); err != nil {
+				return fmt.Errorf("Could not seek: %s", err)
+			}
+			if err := writeBytes(rws, changes[tag]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return rewriteFile(rws, h, changes)
+}
+
+// tiffFileOffset returns the file offset of the TIFF header in the Exif APP1.
+func tiffFileOffset(h *JPEGHeader) (int64, bool) {
+	offset := int64(len(soiMarker))
+	for _, s := range h.segments {
+		if s.exif {
+			tiffOffset, _, _ := exifTIFFOffset(s.data)
+			return offset + int64(s.fill) + 4 + int64(tiffOffset), true
+		}
+		offset += int64(s.size())
+	}
+	return 0, false
+}
+
+// ifdOffsets returns the offsets of the IFDs in the TIFF.
+func ifdOffsets(a *APP1) map[IFDKind]uint32 {
+	offsets := map[IFDKind]uint32{IFD0Kind: uint32(8 + len(a.rawPreIFD))}
+	if len(a.RawTIFF) >= 8 {
+		offsets[IFD0Kind] = a.Endian.Uint32(a.RawTIFF[4:8])
+	}
+	if e := a.IFD0.Find(tagExifIFDPointer); e != nil && a.ExifIFD != nil {
+		offsets[ExifIFDKind] = e.Uint32(a.Endian)
+	}
+	if e := a.IFD0.Find(tagGPSInfoIFDPointer); e != nil && a.GPSIFD != nil {
+		offsets[GPSIFDKind] = e.Uint32(a.Endian)
+	}
+	if e := a.ExifIFD.Find(tagInteroperabilityIFDPointer); e != nil && a.InteroperabilityIFD != nil {
+		offsets[InteroperabilityIFDKind] = e.Uint32(a.Endian)
+	}
+	if a.IFD1 != nil {
+		offsets[IFD1Kind] = a.IFD0.NextIFDOffset
+	}
+	return offsets
+}
+
+// inlineValueOffsets returns the file offsets of the inline values of the tags.
+// It returns false if any tag does not exist, or the value does not fit in the inline value or has another length.
+func inlineValueOffsets(h *JPEGHeader, changes map[uint16][]byte) (map[uint16]int64, bool) {
+	base, ok := tiffFileOffset(h)
+	if !ok {
+		return nil, false
+	}
+	ifds := ifdOffsets(h.APP1)
+	offsets := make(map[uint16]int64)
+	for tag, value := range changes {
+		found := false
+		for _, kind := range editKinds {
+			d := h.APP1.IFD(kind)
+			if d == nil {
+				continue
+			}
+			for i, e := range d.Elements {
+				if e.Tag != tag {
+					continue
+				}
+				if e.Length() > 4 || len(value) != e.Length() {
+					return nil, false
+				}
+				// count, then tag, type and count of the element
+				offsets[tag] = base + int64(ifds[kind]) + 2 + 12*int64(i) + 8
+				found = true
+				break
+			}
+			if found {
+				break
+			}
+		}
+		if !found {
+			return nil, false
+		}
+	}
+	return offsets, true
+}
+
+// rewriteFile applies the changes to the APP1 and rewrites the whole file.
+// The reader must be positioned at the image data.
+func rewriteFile(rws io.ReadWriteSeeker, h *JPEGHeader, changes map[uint16][]byte) error {
+	imageData, err := ioutil.ReadAll(rws)
+	if err != nil {
+		return fmt.Errorf("Could not read image data: %s", err)
+	}
+	for tag, value := range changes {
+		if err := setRawValue(h.APP1, tag, value); err != nil {
+			return err
+		}
+	}
+	var b bytes.Buffer
+	if err := writeJPEGHeader(&b, h); err != nil {
+		return fmt.Errorf("Could not write JPEG header: %s", err)
+	}
+	b.Write(imageData)
+	if _, err := rws.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("Could not seek: %s", err)
+	}
+	if err := writeBytes(rws, b.Bytes()); err != nil {
+		return err
+	}
+	if t, ok := rws.(interface{ Truncate(int64) error }); ok {
+		if err := t.Truncate(int64(b.Len())); err != nil {
+			return fmt.Errorf("Could not truncate: %s", err)
+		}
+	}
+	return nil
+}
+
+// setRawValue replaces the value of the existing element with the raw bytes,
+// or adds an element of the type in the tag table, or UNDEFINED in the 0th IFD if the tag is unknown.
+func setRawValue(a *APP1, tag uint16, value []byte) error {
+	for _, kind := range editKinds {
+		if e := a.IFD(kind).Find(tag); e != nil {
+			return setRawElement(a, kind, tag, e.Type, value)
+		}
+	}
+	for _, kind := range editKinds {
+		if def, ok := tagDefs[kind][tag]; ok {
+			return setRawElement(a, kind, tag, def.typ, value)
+		}
+	}
+	return setRawElement(a, IFD0Kind, tag, 7, value)
+}
+
+// setRawElement sets an element of the raw bytes, whose count is determined by the type.
+func setRawElement(a *APP1, kind IFDKind, tag uint16, typ IFDElementType, value []byte) error {
+	size := (&IFDElement{Type: typ, Count: 1}).Length()
+	if size == 0 || len(value) == 0 || len(value)%size != 0 {
+		return fmt.Errorf("Value of tag 0x%04X is %d bytes which is not a multiple of %s", tag, len(value), typ)
+	}
+	return a.SetElement(kind, newElement(tag, typ, uint32(len(value)/size), append([]byte{}, value...)))
+}
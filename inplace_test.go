@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// createTestdataCopy returns a temporary file of the copy of the sample file.
+func createTestdataCopy(t *testing.T, name string) *os.File {
+	t.Helper()
+	f, err := os.Create(filepath.Join(t.TempDir(), name))
+	if err != nil {
+		t.Fatalf("Could not create file: %s", err)
+	}
+	t.Cleanup(func() { f.Close() })
+	if _, err := f.Write(loadTestdata(t, name)); err != nil {
+		t.Fatalf("Could not write file: %s", err)
+	}
+	return f
+}
+
+func readTempFile(t *testing.T, f *os.File) []byte {
+	t.Helper()
+	b, err := os.ReadFile(f.Name())
+	if err != nil {
+		t.Fatalf("Could not read file: %s", err)
+	}
+	return b
+}
+
+func TestEditInPlace_InPlace(t *testing.T) {
+	for _, c := range []struct {
+		name   string
+		endian binary.ByteOrder
+	}{
+		{"ii.jpg", binary.LittleEndian},
+		{"mm.jpg", binary.BigEndian},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			f := createTestdataCopy(t, c.name)
+			original := loadTestdata(t, c.name)
+			value := make([]byte, 2)
+			c.endian.PutUint16(value, 3)
+			if err := EditInPlace(f, map[uint16][]byte{tagOrientation: value}); err != nil {
+				t.Fatalf("EditInPlace error: %s", err)
+			}
+			b := readTempFile(t, f)
+			if len(b) != len(original) {
+				t.Fatalf("Length wants %d but %d", len(original), len(b))
+			}
+			var diff int
+			for i := range b {
+				if b[i] != original[i] {
+					diff++
+				}
+			}
+			if diff != 1 {
+				t.Errorf("Changed bytes wants 1 but %d", diff)
+			}
+			h, err := Decode(b)
+			if err != nil {
+				t.Fatalf("Decode error: %s", err)
+			}
+			if o, ok := h.APP1.Orientation(); !ok || o != 3 {
+				t.Errorf("Orientation wants 3 but %v", o)
+			}
+		})
+	}
+}
+
+func TestEditInPlace_Rewrite(t *testing.T) {
+	f := createTestdataCopy(t, "ii.jpg")
+	changes := map[uint16][]byte{
+		tagOrientation: {8, 0},
+		tagRating:      {4, 0},
+		0x0131:         []byte("exif-study\x00"),
+		0xFF00:         {1, 2, 3, 4, 5},
+	}
+	if err := EditInPlace(f, changes); err != nil {
+		t.Fatalf("EditInPlace error: %s", err)
+	}
+	b := readTempFile(t, f)
+	if bytes.Equal(b, loadTestdata(t, "ii.jpg")) {
+		t.Fatalf("File wants rewritten")
+	}
+	h, err := Decode(b)
+	if err != nil {
+		t.Fatalf("Decode error: %s", err)
+	}
+	if o, ok := h.APP1.Orientation(); !ok || o != 8 {
+		t.Errorf("Orientation wants 8 but %v", o)
+	}
+	if r, ok := h.APP1.Rating(); !ok || r != 4 {
+		t.Errorf("Rating wants 4 but %d", r)
+	}
+	if e := h.APP1.IFD0.Find(tagRating); e == nil || e.Type != 3 || e.Count != 1 {
+		t.Errorf("Rating wants SHORT x 1 but %+v", e)
+	}
+	if s, err := h.APP1.IFD0.Find(0x0131).ASCII(); err != nil || s != "exif-study" {
+		t.Errorf("Software wants exif-study but %q", s)
+	}
+	if e := h.APP1.IFD0.Find(0xFF00); e == nil || e.Type != 7 || e.Count != 5 {
+		t.Errorf("Unknown tag wants UNDEFINED x 5 but %+v", e)
+	}
+	if m, ok := h.APP1.Make(); !ok || m != "Canon" {
+		t.Errorf("Make wants Canon but %q", m)
+	}
+}
+
+func TestEditInPlace_ShortValue(t *testing.T) {
+	f := createTestdataCopy(t, "ii.jpg")
+	if err := EditInPlace(f, map[uint16][]byte{tagOrientation: {1, 0, 0}}); err == nil {
+		t.Errorf("EditInPlace of 3 bytes of SHORT wants error")
+	}
+}
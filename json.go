@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// endianName returns "II" for little endian or "MM" for big endian.
+func endianName(endian binary.ByteOrder) (string, error) {
+	switch endian {
+	case binary.LittleEndian:
+		return "II", nil
+	case binary.BigEndian:
+		return "MM", nil
+	}
+	return "", fmt.Errorf("Invalid endian: %v", endian)
+}
+
+func parseEndianName(s string) (binary.ByteOrder, error) {
+	switch s {
+	case "II":
+		return binary.LittleEndian, nil
+	case "MM":
+		return binary.BigEndian, nil
+	}
+	return nil, fmt.Errorf("Endian expects II or MM but got %q", s)
+}
+
+type app1Alias APP1
+
+// MarshalJSON encodes the APP1 with Endian as "II" or "MM" and the thumbnail.
+// Byte slices are encoded in base64, or in hex if jsonHexBytes is set.
+// The fields are the same in both encodings.
+func (a *APP1) MarshalJSON() ([]byte, error) {
+	endian, err := endianName(a.Endian)
+	if err != nil {
+		return nil, err
+	}
+	if a.jsonHexBytes {
+		var pages []*hexIFD
+		for _, page := range a.Pages {
+			pages = append(pages, newHexIFD(page))
+		}
+		return json.Marshal(struct {
+			Endian              string
+			IFD0                *hexIFD
+			ExifIFD             *hexIFD
+			GPSIFD              *hexIFD
+			InteroperabilityIFD *hexIFD
+			IFD1                *hexIFD
+			Pages               []*hexIFD `json:",omitempty"`
+			Thumbnail           hexBytes  `json:",omitempty"`
+		}{
+			endian,
+			newHexIFD(a.IFD0),
+			newHexIFD(a.ExifIFD),
+			newHexIFD(a.GPSIFD),
+			newHexIFD(a.InteroperabilityIFD),
+			newHexIFD(a.IFD1),
+			pages,
+			a.thumbnail,
+		})
+	}
+	return json.Marshal(struct {
+		Endian string
+		*app1Alias
+		Thumbnail []byte `json:",omitempty"`
+	}{endian, (*app1Alias)(a), a.thumbnail})
+}
+
+// UnmarshalJSON decodes the APP1 encoded by MarshalJSON.
+// Offsets are recomputed when it is written.
+func (a *APP1) UnmarshalJSON(b []byte) error {
+	v := struct {
+		Endian string
+		*app1Alias
+		Thumbnail []byte
+	}{app1Alias: (*app1Alias)(a)}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	endian, err := parseEndianName(v.Endian)
+	if err != nil {
+		return err
+	}
+	a.Endian = endian
+	a.thumbnail = v.Thumbnail
+	return nil
+}
+
+// hexBytes is encoded as a lowercase hex string in JSON.
+type hexBytes []byte
+
+func (b hexBytes) MarshalJSON() ([]byte, error) {
+	return json.Marshal(hex.EncodeToString(b))
+}
+
+// hexIFD is an IFD whose values are encoded in hex.
+// It is only for reading, i.e. UnmarshalJSON does not accept it.
+type hexIFD struct {
+	Elements      []hexElement
+	NextIFDOffset uint32
+}
+
+type hexElement struct {
+	Tag     uint16
+	Type    IFDElementType
+	Count   uint32
+	Value   hexBytes
+	Decoded interface{} `json:",omitempty"`
+}
+
+func newHexIFD(d *IFD) *hexIFD {
+	if d == nil {
+		return nil
+	}
+	h := &hexIFD{NextIFDOffset: d.NextIFDOffset}
+	for _, e := range d.Elements {
+		h.Elements = append(h.Elements, hexElement{e.Tag, e.Type, e.Count, e.Value, e.Decoded})
+	}
+	return h
+}
+
+type ifdElementAlias IFDElement
+
+// UnmarshalJSON decodes the element and restores the raw value of an inline value.
+func (e *IFDElement) UnmarshalJSON(b []byte) error {
+	if err := json.Unmarshal(b, (*ifdElementAlias)(e)); err != nil {
+		return err
+	}
+	e.rawValue = make([]byte, 4)
+	if e.Length() <= 4 {
+		copy(e.rawValue, e.Value)
+	}
+	return nil
+}
+
+// WriteTo writes the header segments with the Exif APP1 re-encoded.
+// The image data after the header is not written.
+// If the header has no segment, e.g. it is decoded from JSON, EOI is written after the APP1.
+func (h *JPEGHeader) WriteTo(w io.Writer) (int64, error) {
+	var b bytes.Buffer
+	if err := writeJPEGHeader(&b, h); err != nil {
+		return 0, err
+	}
+	if len(h.segments) == 0 {
+		if err := writeSegment(&b, &segment{marker: markerEOI}); err != nil {
+			return 0, err
+		}
+	}
+	return b.WriteTo(w)
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestAPP1_JSONRoundTrip(t *testing.T) {
+	for _, name := range []string{"ii.jpg", "mm.jpg"} {
+		t.Run(name, func(t *testing.T) {
+			want := decodeTestdata(t, name).APP1
+			b, err := json.Marshal(want)
+			if err != nil {
+				t.Fatalf("Marshal error: %s", err)
+			}
+			var got APP1
+			if err := json.Unmarshal(b, &got); err != nil {
+				t.Fatalf("Unmarshal error: %s", err)
+			}
+			if got.Endian != want.Endian {
+				t.Errorf("Endian wants %s but %s", want.Endian, got.Endian)
+			}
+			assertSameElements(t, want, &got)
+			if !bytes.Equal(got.Thumbnail(), want.Thumbnail()) {
+				t.Errorf("Thumbnail wants %d bytes but %d bytes", len(want.Thumbnail()), len(got.Thumbnail()))
+			}
+
+			decoded := reencode(t, &JPEGHeader{APP1: &got})
+			assertSameElements(t, want, decoded.APP1)
+		})
+	}
+}
+
+func TestAPP1_UnmarshalJSON_InvalidEndian(t *testing.T) {
+	var a APP1
+	if err := json.Unmarshal([]byte(`{"Endian":"XX"}`), &a); err == nil || !strings.Contains(err.Error(), "II or MM") {
+		t.Errorf("Unmarshal wants error of endian but %v", err)
+	}
+	if _, err := json.Marshal(&APP1{}); err == nil {
+		t.Errorf("Marshal without endian wants error")
+	}
+}
+
+func TestJPEGHeader_WriteTo_NoSegments(t *testing.T) {
+	h := &JPEGHeader{APP1: decodeTestdata(t, "ii.jpg").APP1}
+	var b bytes.Buffer
+	if _, err := h.WriteTo(&b); err != nil {
+		t.Fatalf("WriteTo error: %s", err)
+	}
+	if !bytes.HasPrefix(b.Bytes(), []byte{0xff, 0xd8, 0xff, markerAPP1}) || !bytes.HasSuffix(b.Bytes(), []byte{0xff, markerEOI}) {
+		t.Errorf("WriteTo wants SOI, APP1 and EOI but % x", b.Bytes()[:4])
+	}
+}
+
+func TestRun_HexBytes(t *testing.T) {
+	var b bytes.Buffer
+	if err := run(nil, &b, "json", true, []string{"testdata/ii.jpg"}); err != nil {
+		t.Fatalf("run error: %s", err)
+	}
+	var v struct {
+		APP1 struct {
+			Endian string
+			IFD0   struct {
+				Elements []struct {
+					Tag   uint16
+					Value string
+				}
+			}
+			Thumbnail string
+		}
+	}
+	if err := json.Unmarshal(b.Bytes(), &v); err != nil {
+		t.Fatalf("Could not decode json: %s", err)
+	}
+	if v.APP1.Endian != "II" {
+		t.Errorf("Endian wants II but %s", v.APP1.Endian)
+	}
+	var found bool
+	for _, e := range v.APP1.IFD0.Elements {
+		if e.Tag == tagMake {
+			found = true
+			if e.Value != "43616e6f6e00" {
+				t.Errorf("Value of Make wants hex of Canon but %s", e.Value)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("IFD0 wants Make")
+	}
+	if !strings.HasPrefix(v.APP1.Thumbnail, "ffd8") {
+		t.Errorf("Thumbnail wants hex of SOI but %.8s", v.APP1.Thumbnail)
+	}
+}
+
+func TestAPP1_MarshalJSON_SameFields(t *testing.T) {
+	a, err := DecodeTIFFBytes(loadTestdata(t, "exif.tiff"))
+	if err != nil {
+		t.Fatalf("DecodeTIFFBytes error: %s", err)
+	}
+	keys := func(hex bool) []string {
+		a.jsonHexBytes = hex
+		b, err := json.Marshal(a)
+		if err != nil {
+			t.Fatalf("Marshal error: %s", err)
+		}
+		var v map[string]json.RawMessage
+		if err := json.Unmarshal(b, &v); err != nil {
+			t.Fatalf("Could not decode json: %s", err)
+		}
+		var keys []string
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		return keys
+	}
+	base64Keys, hexKeys := keys(false), keys(true)
+	if !reflect.DeepEqual(base64Keys, hexKeys) {
+		t.Errorf("Keys of hex wants %v but %v", base64Keys, hexKeys)
+	}
+	for _, k := range []string{"Pages", "Thumbnail"} {
+		if sort.SearchStrings(hexKeys, k) == len(hexKeys) || hexKeys[sort.SearchStrings(hexKeys, k)] != k {
+			t.Errorf("Keys wants %s but %v", k, hexKeys)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import "sort"
+
+// Region represents a byte range in the TIFF.
+type Region struct {
+	Name  string
+	Start int
+	End   int
+}
+
+// Layout returns the regions of the TIFF header, IFD tables, their values and the thumbnail
+// in order of the offset, then the end and name for the same offset. Offsets are relative to the TIFF header.
+// It returns nil if the APP1 is not parsed from bytes.
+func (a *APP1) Layout() []Region {
+	if a.RawTIFF == nil || a.IFD0 == nil {
+		return nil
+	}
+	regions := []Region{{Name: "TIFF header", Start: 0, End: 8}}
+	if len(a.rawPreIFD) > 0 {
+		regions = append(regions, Region{Name: "rawPreIFD", Start: 8, End: 8 + len(a.rawPreIFD)})
+	}
+	offsets := ifdOffsets(a)
+	for _, kind := range []IFDKind{IFD0Kind, ExifIFDKind, GPSIFDKind, InteroperabilityIFDKind, IFD1Kind} {
+		offset, ok := offsets[kind]
+		if !ok {
+			continue
+		}
+		d := a.IFD(kind)
+		start := int(offset)
+		end := start + 2 + 12*len(d.Elements) + 4
+		regions = append(regions, Region{Name: kind.String() + " table", Start: start, End: end})
+		if len(d.rawValues) > 0 {
+			regions = append(regions, Region{Name: kind.String() + " values", Start: end, End: end + len(d.rawValues)})
+		}
+	}
+	if a.thumbnail != nil {
+		if offset, ok := findUint32(a.IFD1, tagJPEGInterchangeFormat, a.Endian); ok {
+			regions = append(regions, Region{Name: "Thumbnail", Start: int(offset), End: int(offset) + len(a.thumbnail)})
+		}
+	}
+	sort.SliceStable(regions, func(i, j int) bool {
+		if regions[i].Start != regions[j].Start {
+			return regions[i].Start < regions[j].Start
+		}
+		if regions[i].End != regions[j].End {
+			return regions[i].End < regions[j].End
+		}
+		return regions[i].Name < regions[j].Name
+	})
+	return regions
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAPP1_Layout(t *testing.T) {
+	a := decodeTestdata(t, "ii.jpg").APP1
+	regions := a.Layout()
+	if len(regions) == 0 || regions[0] != (Region{Name: "TIFF header", Start: 0, End: 8}) {
+		t.Fatalf("Layout wants the TIFF header first but %+v", regions)
+	}
+	if r := regionOf(t, a, "IFD0 table"); r.Start != 8 || r.End != 8+2+12*9+4 {
+		t.Errorf("IFD0 table wants 8-122 but %+v", r)
+	}
+	names := map[string]bool{}
+	for i, r := range regions {
+		names[r.Name] = true
+		if r.Start > r.End || r.End > len(a.RawTIFF) {
+			t.Errorf("%s wants within the TIFF but %+v", r.Name, r)
+		}
+		if i > 0 && regions[i-1].Start > r.Start {
+			t.Errorf("Layout wants sorted but %+v before %+v", regions[i-1], r)
+		}
+	}
+	for _, kind := range []IFDKind{IFD0Kind, ExifIFDKind, GPSIFDKind, InteroperabilityIFDKind, IFD1Kind} {
+		if !names[kind.String()+" table"] {
+			t.Errorf("Layout wants the table of %s", kind)
+		}
+	}
+	if r := regionOf(t, a, "Thumbnail"); r.End-r.Start != len(a.Thumbnail()) {
+		t.Errorf("Thumbnail wants %d bytes but %+v", len(a.Thumbnail()), r)
+	}
+}
+
+func TestAPP1_Layout_NotParsed(t *testing.T) {
+	if regions := NewAPP1(newTestAPP1(nil).Endian).Layout(); regions != nil {
+		t.Errorf("Layout of a new APP1 wants nil but %+v", regions)
+	}
+}
+
+func TestAPP1_Layout_SharedOffset(t *testing.T) {
+	b := loadTestdata(t, "exif.tiff")
+	a, err := DecodeTIFFBytes(b)
+	if err != nil {
+		t.Fatalf("DecodeTIFFBytes error: %s", err)
+	}
+	exif := ifdOffsets(a)[ExifIFDKind]
+	a, err = DecodeTIFFBytes(patchValueOffset(t, b, IFD0Kind, tagGPSInfoIFDPointer, exif))
+	if err != nil {
+		t.Fatalf("DecodeTIFFBytes error: %s", err)
+	}
+	want := a.Layout()
+	var names []string
+	for _, r := range want {
+		if r.Start == int(exif) || r.Start == regionOf(t, a, "Exif values").Start {
+			names = append(names, r.Name)
+		}
+	}
+	if wantNames := []string{"Exif table", "GPS table", "Exif values", "GPS values"}; !reflect.DeepEqual(names, wantNames) {
+		t.Fatalf("Layout wants %v for the same offset but %v", wantNames, names)
+	}
+	for i := 0; i < 20; i++ {
+		if got := a.Layout(); !reflect.DeepEqual(got, want) {
+			t.Fatalf("Layout wants the same order but %+v", got)
+		}
+	}
+}
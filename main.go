@@ -2,163 +2,440 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
+	"encoding/csv"
 	"encoding/hex"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"io"
 	"log"
+	"log/slog"
 	"os"
+	"sort"
+	"sync"
 )
 
 type JPEGHeader struct {
-	APP1 *APP1
+	APP1     *APP1
+	SOF      *SOF
+	segments []*segment
+	index    map[uint32]*IFDElement
+	// maxSegmentSize is DecodeOptions.MaxSegmentSize used on parsing.
+	maxSegmentSize int
+
+	// ImageDataOffset is the byte offset just after the last parsed segment,
+	// i.e. the entropy-coded data after the SOS header, or after EOI.
+	ImageDataOffset int64 `json:"-"`
 }
 
 var soiMarker = []byte{0xff, 0xd8}
 
-func parseJPEGHeader(r io.Reader) (*JPEGHeader, error) {
-	b, err := readBytes(r, 2)
+// parseJPEGHeader parses the segments until SOS or EOI, including SOF, DQT and DHT.
+// The rest of the reader is the entropy-coded image data.
+// Segments are read by the read function.
+func parseJPEGHeader(r io.Reader, read readFunc, opts DecodeOptions) (*JPEGHeader, error) {
+	read = limitRead(read, opts.maxSegmentSize())
+	b, err := read(r, 2)
 	if err != nil {
 		return nil, err
 	}
 	if bytes.Compare(b, soiMarker) != 0 {
 		return nil, fmt.Errorf("SOI not found")
 	}
-	app1, err := parseAPP1(r)
-	if err != nil {
-		return nil, fmt.Errorf("Could not parse APP1: %s", err)
+	h := JPEGHeader{ImageDataOffset: int64(len(soiMarker)), maxSegmentSize: opts.maxSegmentSize()}
+	for {
+		s, err := parseSegment(r, read)
+		if err != nil {
+			return nil, fmt.Errorf("Could not parse segment: %s", err)
+		}
+		h.segments = append(h.segments, s)
+		h.ImageDataOffset += int64(s.size())
+		switch {
+		case s.marker == markerAPP1 && h.APP1 == nil && isExif(s.data):
+			h.APP1, err = parseAPP1(s.data, opts)
+			if err != nil {
+				return nil, fmt.Errorf("Could not parse APP1: %s", err)
+			}
+			s.exif = true
+		case isSOFMarker(s.marker):
+			h.SOF, err = parseSOF(s)
+			if err != nil {
+				return nil, fmt.Errorf("Could not parse SOF: %s", err)
+			}
+		case s.marker == markerSOS, s.marker == markerEOI:
+			return &h, nil
+		}
 	}
-	return &JPEGHeader{app1}, nil
 }
 
-func writeJPEGHeader(w io.Writer) error {
+// writeJPEGHeader writes the segments with the Exif APP1 re-encoded.
+// If the Exif APP1 did not exist, it is written after SOI.
+func writeJPEGHeader(w io.Writer, h *JPEGHeader) error {
 	if err := writeBytes(w, soiMarker); err != nil {
 		return err
 	}
-	//TODO
+	exifWritten := false
+	writeExif := func() error {
+		if exifWritten || h.APP1 == nil {
+			return nil
+		}
+		exifWritten = true
+		if err := writeAPP1(w, h.APP1); err != nil {
+			return fmt.Errorf("Could not write APP1: %s", err)
+		}
+		return nil
+	}
+	hasExif := false
+	for _, s := range h.segments {
+		hasExif = hasExif || s.exif
+	}
+	if !hasExif {
+		if err := writeExif(); err != nil {
+			return err
+		}
+	}
+	for _, s := range h.segments {
+		if s.exif {
+			if err := writeExif(); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := writeSegment(w, s); err != nil {
+			return fmt.Errorf("Could not write segment 0x%02x: %s", s.marker, err)
+		}
+	}
 	return nil
 }
 
 type APP1 struct {
-	Endian              binary.ByteOrder
+	Endian binary.ByteOrder
+	// RawTIFF is the TIFF block from the byte order mark, without the APP1 marker, length and Exif marker.
+	RawTIFF             []byte `json:"-"`
 	rawPreIFD           []byte
 	IFD0                *IFD
 	ExifIFD             *IFD
 	GPSIFD              *IFD
 	InteroperabilityIFD *IFD
 	IFD1                *IFD
+	thumbnail           []byte
+
+	// Pages are the IFDs in the chain from the 0th IFD, i.e. the pages of a multi-page TIFF.
+	// The first and second pages are the 0th and 1st IFD.
+	// It is set only by DecodeTIFFBytes and is not written.
+	Pages []*IFD `json:",omitempty"`
+
+	// jsonHexBytes encodes byte slices in hex instead of base64 in MarshalJSON.
+	jsonHexBytes bool
 }
 
 var app1marker = []byte{0xff, 0xe1}
 var exifMarker = []byte{0x45, 0x78, 0x69, 0x66, 0x00, 0x00}
 
-func parseAPP1(r io.Reader) (*APP1, error) {
-	b, err := readBytes(r, 2)
-	if err != nil {
-		return nil, err
+// isExif returns true if the data of APP1 segment is Exif.
+func isExif(b []byte) bool {
+	_, _, ok := exifTIFFOffset(b)
+	return ok
+}
+
+// exifTIFFOffset returns the offset of the TIFF header in the data of APP1 segment.
+// It accepts the canonical "Exif\0\0" and deviations of non-conformant writers
+// such as "Exif\0" or a stray byte, by finding the byte order mark within a few bytes after "Exif".
+func exifTIFFOffset(b []byte) (offset int, canonical bool, ok bool) {
+	if bytes.HasPrefix(b, exifMarker) {
+		return len(exifMarker), true, true
 	}
-	if bytes.Compare(b, app1marker) != 0 {
-		return nil, fmt.Errorf("APP1 marker not found")
+	if !bytes.HasPrefix(b, exifMarker[:4]) {
+		return 0, false, false
 	}
-
-	b, err = readBytes(r, 2)
-	if err != nil {
-		return nil, err
+	for i := 4; i <= 8 && i+4 <= len(b); i++ {
+		if bytes.HasPrefix(b[i:], []byte{0x49, 0x49, 0x2a, 0x00}) || bytes.HasPrefix(b[i:], []byte{0x4d, 0x4d, 0x00, 0x2a}) {
+			return i, false, true
+		}
 	}
-	app1Length := binary.BigEndian.Uint16(b)
-	tiffLength := app1Length - 10
+	return 0, false, false
+}
 
-	b, err = readBytes(r, 6)
-	if err != nil {
-		return nil, err
-	}
-	if bytes.Compare(b, exifMarker) != 0 {
+// parseAPP1 parses the data of APP1 segment, i.e. the Exif marker and TIFF.
+func parseAPP1(b []byte, opts DecodeOptions) (*APP1, error) {
+	offset, canonical, ok := exifTIFFOffset(b)
+	if !ok {
 		return nil, fmt.Errorf("Exif marker not found")
 	}
+	if !canonical {
+		opts.log().Warn(fmt.Sprintf("Exif marker is not canonical: % x", b[:offset]))
+	}
+	app1, err := parseTIFF(b[offset:], opts)
+	if err != nil {
+		return nil, fmt.Errorf("Could not parse TIFF: %s", err)
+	}
+	return app1, err
+}
 
-	b, err = readBytes(r, int(tiffLength))
+// DecodeTIFFBytes parses a TIFF block which begins with the byte order mark.
+// This is useful for a container such as HEIF or CR3, which embeds Exif at an arbitrary offset.
+// It also follows the chain of IFDs into Pages for a multi-page TIFF.
+func DecodeTIFFBytes(b []byte) (*APP1, error) {
+	app1, err := parseTIFF(b, DecodeOptions{})
 	if err != nil {
 		return nil, err
 	}
-	app1, err := parseTIFF(b)
+	app1.Pages, err = parsePages(b, app1)
 	if err != nil {
-		return nil, fmt.Errorf("Could not parse TIFF: %s", err)
+		return nil, fmt.Errorf("Could not parse pages: %s", err)
 	}
-	return app1, err
+	return app1, nil
 }
 
-func parseTIFF(b []byte) (*APP1, error) {
-	var app1 APP1
+// parseTIFFHeader parses the 8 bytes header and returns the endian and offset of 0th IFD.
+func parseTIFFHeader(b []byte) (binary.ByteOrder, uint32, error) {
+	if len(b) < 8 {
+		return nil, 0, fmt.Errorf("TIFF header expects 8 bytes but got %d bytes: % x", len(b), b)
+	}
+	var endian binary.ByteOrder
 	switch {
 	case bytes.Compare(b[0:2], []byte{0x4d, 0x4d}) == 0:
-		app1.Endian = binary.BigEndian
+		endian = binary.BigEndian
 	case bytes.Compare(b[0:2], []byte{0x49, 0x49}) == 0:
-		app1.Endian = binary.LittleEndian
+		endian = binary.LittleEndian
 	default:
-		return nil, fmt.Errorf("Invalid endian: %x", b[0:2])
+		return nil, 0, fmt.Errorf("Invalid endian: header is % x but expected 4d 4d 00 2a (MM) or 49 49 2a 00 (II)", b[0:8])
+	}
+	if endian.Uint16(b[2:4]) != 0x002a {
+		return nil, 0, fmt.Errorf("Invalid TIFF version: header is % x but expected 4d 4d 00 2a (MM) or 49 49 2a 00 (II)", b[0:8])
+	}
+	return endian, endian.Uint32(b[4:8]), nil
+}
+
+func parseTIFF(b []byte, opts DecodeOptions) (*APP1, error) {
+	endian, ifdOffset, err := parseTIFFHeader(b)
+	if err != nil {
+		return nil, err
+	}
+	if opts.DetectEndianMismatch {
+		if warning, ok := detectEndianMismatch(b, endian); ok {
+			opts.log().Warn(warning)
+		}
 	}
-	if app1.Endian.Uint16(b[2:4]) != 0x002a {
-		return nil, fmt.Errorf("Invalid TIFF version: %x", b[2:4])
+	app1 := APP1{Endian: endian, RawTIFF: b}
+	if ifdOffset < 8 || int64(ifdOffset) > int64(len(b)) {
+		return nil, fmt.Errorf("Offset of 0th IFD 0x%x is out of TIFF (%d bytes)", ifdOffset, len(b))
 	}
-	ifdOffset := app1.Endian.Uint32(b[4:8])
 	app1.rawPreIFD = b[8:ifdOffset]
 
-	var err error
-	app1.IFD0, err = parseIFD(b[ifdOffset:], app1.Endian)
+	app1.IFD0, err = parseIFD(b, 0, ifdOffset, app1.Endian)
 	if err != nil {
 		return nil, fmt.Errorf("Could not parse 0th IFD: %s", err)
 	}
-	app1.ExifIFD, err = app1.IFD0.FindLinkedIFD(0x8769, b, app1.Endian)
+	app1.ExifIFD, err = app1.IFD0.FindLinkedIFD(tagExifIFDPointer, b, app1.Endian)
 	if err != nil {
 		return nil, fmt.Errorf("Could not parse Exif IFD: %s", err)
 	}
-	app1.GPSIFD, err = app1.IFD0.FindLinkedIFD(0x8825, b, app1.Endian)
+	app1.GPSIFD, err = app1.IFD0.FindLinkedIFD(tagGPSInfoIFDPointer, b, app1.Endian)
 	if err != nil {
 		return nil, fmt.Errorf("Could not parse GPS IFD: %s", err)
 	}
-	app1.InteroperabilityIFD, err = app1.IFD0.FindLinkedIFD(0xA005, b, app1.Endian)
-	if err != nil {
-		return nil, fmt.Errorf("Could not parse Interoperability IFD: %s", err)
+	if app1.ExifIFD != nil {
+		// Interoperability IFD is linked from Exif IFD, not 0th IFD.
+		app1.InteroperabilityIFD, err = app1.ExifIFD.FindLinkedIFD(tagInteroperabilityIFDPointer, b, app1.Endian)
+		if err != nil {
+			return nil, fmt.Errorf("Could not parse Interoperability IFD: %s", err)
+		}
 	}
-	app1.IFD1, err = parseIFD(b[int(ifdOffset)+len(app1.IFD0.rawValues):], app1.Endian)
-	if err != nil {
-		return nil, fmt.Errorf("Could not parse 1st IFD: %s", err)
+	if app1.IFD0.NextIFDOffset != 0 {
+		app1.IFD1, err = parseIFD(b, 0, app1.IFD0.NextIFDOffset, app1.Endian)
+		if err != nil {
+			return nil, fmt.Errorf("Could not parse 1st IFD: %s", err)
+		}
+		app1.thumbnail, err = app1.IFD1.findThumbnail(b, app1.Endian)
+		if err != nil {
+			return nil, fmt.Errorf("Could not find thumbnail: %s", err)
+		}
+	}
+	if opts.DuplicateTags == LastWins {
+		for _, kind := range []IFDKind{IFD0Kind, ExifIFDKind, GPSIFDKind, InteroperabilityIFDKind, IFD1Kind} {
+			app1.IFD(kind).keepLastDuplicates()
+		}
+	}
+	if opts.DecodeValues {
+		app1.Walk(func(kind IFDKind, e *IFDElement) {
+			e.Decoded = decodeValue(e, app1.Endian)
+		})
 	}
 	return &app1, nil
 }
 
+// Thumbnail returns the JPEG thumbnail stored in the 1st IFD, or nil if not present.
+func (a *APP1) Thumbnail() []byte {
+	return a.thumbnail
+}
+
+// SetThumbnail replaces the JPEG thumbnail in the 1st IFD.
+// The 1st IFD is created if it does not exist.
+// The offset of the thumbnail is recomputed when the APP1 is written.
+func (a *APP1) SetThumbnail(jpeg []byte) {
+	if a.IFD1 == nil {
+		a.IFD1 = &IFD{}
+		a.IFD1.Set(newShortElement(tagCompression, 6, a.Endian))
+	}
+	a.IFD1.Set(newLongElement(tagJPEGInterchangeFormat, 0, a.Endian))
+	a.IFD1.Set(newLongElement(tagJPEGInterchangeFormatLength, uint32(len(jpeg)), a.Endian))
+	a.thumbnail = jpeg
+}
+
+const (
+	tagCompression                 = 0x0103
+	tagJPEGInterchangeFormat       = 0x0201
+	tagJPEGInterchangeFormatLength = 0x0202
+	tagExifIFDPointer              = 0x8769
+	tagGPSInfoIFDPointer           = 0x8825
+	tagInteroperabilityIFDPointer  = 0xA005
+)
+
 type IFD struct {
-	Elements  []*IFDElement
-	rawValues []byte
+	Elements      []*IFDElement
+	NextIFDOffset uint32
+	rawValues     []byte
 }
 
+// FindLinkedIFD parses the IFD pointed by the link tag, or returns nil if the tag is not present.
 func (d *IFD) FindLinkedIFD(tag uint16, b []byte, endian binary.ByteOrder) (*IFD, error) {
 	for _, e := range d.Elements {
 		if e.Tag == tag {
 			offset := e.Uint32(endian)
-			return parseIFD(b[offset:], endian)
+			if int64(offset)+2 > int64(len(b)) {
+				return nil, fmt.Errorf("Link 0x%04X points to 0x%x beyond the TIFF of %d bytes", tag, offset, len(b))
+			}
+			return parseIFD(b, 0, offset, endian)
 		}
 	}
 	return nil, nil
 }
 
-func parseIFD(b []byte, endian binary.ByteOrder) (*IFD, error) {
-	elementCount := endian.Uint16(b[0:2])
-	valuesOffset := int(2 + elementCount*12 + 4)
-	valuesLength := int(endian.Uint32(b[2+elementCount*12 : 2+elementCount*12+4]))
-	ifd := &IFD{
-		Elements:  make([]*IFDElement, elementCount),
-		rawValues: b[valuesOffset : valuesOffset+valuesLength],
+// Find returns the first element with the tag, or nil if not found.
+// It returns nil if the IFD is nil.
+// See DuplicateTagPolicy for a tag appearing more than once.
+func (d *IFD) Find(tag uint16) *IFDElement {
+	if d == nil {
+		return nil
 	}
-	for i := 0; i < int(elementCount); i++ {
-		offset := 2 + i*12
-		var err error
-		ifd.Elements[i], err = parseIFDElement(b[offset:offset+12], b, endian)
+	for _, e := range d.Elements {
+		if e.Tag == tag {
+			return e
+		}
+	}
+	return nil
+}
+
+// SortedElements returns a copy of the elements sorted by tag.
+// The elements of the IFD are kept in the original order for round-trip.
+func (d *IFD) SortedElements() []*IFDElement {
+	elements := make([]*IFDElement, len(d.Elements))
+	copy(elements, d.Elements)
+	sort.SliceStable(elements, func(i, j int) bool {
+		return elements[i].Tag < elements[j].Tag
+	})
+	return elements
+}
+
+// Set replaces the element with the same tag, or inserts it in ascending order of tag.
+func (d *IFD) Set(element *IFDElement) {
+	for i, e := range d.Elements {
+		if e.Tag == element.Tag {
+			d.Elements[i] = element
+			return
+		}
+	}
+	for i, e := range d.Elements {
+		if e.Tag > element.Tag {
+			d.Elements = append(d.Elements[:i], append([]*IFDElement{element}, d.Elements[i:]...)...)
+			return
+		}
+	}
+	d.Elements = append(d.Elements, element)
+}
+
+// Remove removes the element of the tag and returns true if it existed.
+func (d *IFD) Remove(tag uint16) bool {
+	if d == nil {
+		return false
+	}
+	for i, e := range d.Elements {
+		if e.Tag == tag {
+			d.Elements = append(d.Elements[:i], d.Elements[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (d *IFD) findThumbnail(b []byte, endian binary.ByteOrder) ([]byte, error) {
+	var offset, length uint32
+	for _, e := range d.Elements {
+		switch e.Tag {
+		case tagJPEGInterchangeFormat:
+			offset = e.Uint32(endian)
+		case tagJPEGInterchangeFormatLength:
+			length = e.Uint32(endian)
+		}
+	}
+	if offset == 0 || length == 0 {
+		return nil, nil
+	}
+	// check in uint64 not to overflow int on 32-bit platforms
+	if uint64(offset) > uint64(len(b)) || uint64(length) > uint64(len(b))-uint64(offset) {
+		return nil, fmt.Errorf("Thumbnail at 0x%x (%d bytes) is out of TIFF", offset, length)
+	}
+	return b[offset : offset+length], nil
+}
+
+// parseIFD parses the IFD at the offset in the TIFF.
+// The offset, value offsets and next IFD offset are relative to the base in b.
+// The base is 0 for standard Exif, but a MakerNote may have offsets relative to itself.
+func parseIFD(b []byte, base int, offset uint32, endian binary.ByteOrder) (*IFD, error) {
+	c, err := newCursor(b, base+int(offset), endian)
+	if err != nil {
+		return nil, err
+	}
+	elementCount, err := c.ReadUint16()
+	if err != nil {
+		return nil, fmt.Errorf("Could not read element count: %s", err)
+	}
+	// each element needs 12 bytes and the next IFD offset needs 4 bytes
+	if need, remaining := 12*int(elementCount)+4, len(b)-c.Offset(); need > remaining {
+		return nil, fmt.Errorf("IFD has %d elements which need %d bytes but only %d bytes remain", elementCount, need, remaining)
+	}
+	ifd := &IFD{Elements: make([]*IFDElement, elementCount)}
+	var valuesEnd int
+	for i := range ifd.Elements {
+		elementOffset := c.Offset()
+		eb, err := c.ReadBytes(12)
+		if err != nil {
+			return nil, fmt.Errorf("Could not read IFD element #%d: %s", i, err)
+		}
+		ifd.Elements[i], err = parseIFDElement(eb, b, base, endian)
 		if err != nil {
-			return nil, fmt.Errorf("Could not parse IFD element #%d at 0x%x", i, offset)
+			return nil, fmt.Errorf("Could not parse IFD element #%d at 0x%x: %s", i, elementOffset, err)
 		}
+		if e := ifd.Elements[i]; e.Length() > 4 {
+			if end := base + int(e.Uint32(endian)) + e.Length(); end > valuesEnd {
+				valuesEnd = end
+			}
+		}
+	}
+	ifd.NextIFDOffset, err = c.ReadUint32()
+	if err != nil {
+		return nil, fmt.Errorf("Could not read next IFD offset: %s", err)
 	}
+	// rawValues are the out-of-line values following the table.
+	// Padding after the values is not included.
+	valuesOffset := c.Offset()
+	if valuesEnd < valuesOffset || valuesEnd > len(b) {
+		valuesEnd = valuesOffset
+	}
+	ifd.rawValues = b[valuesOffset:valuesEnd]
 	return ifd, nil
 }
 
@@ -170,41 +447,71 @@ type IFDElement struct {
 	Count    uint32
 	Value    []byte
 	rawValue []byte
+
+	// Decoded is the value decoded on parse if DecodeOptions.DecodeValues is set.
+	// It is not updated when the value is changed.
+	Decoded interface{} `json:",omitempty"`
+	// lazyValue is the value decoded on the first access by JPEGHeader.Value.
+	// lazyOnce guards it for concurrent calls of JPEGHeader.Value.
+	lazyValue interface{}
+	lazyErr   error
+	lazyOnce  sync.Once
 }
 
 func (e *IFDElement) Length() int {
+	return int(e.length64())
+}
+
+// length64 returns the length in bytes without overflow of int on 32-bit platforms,
+// since Count is up to 2^32-1 and a value is up to 8 bytes.
+func (e *IFDElement) length64() int64 {
 	switch e.Type {
-	case 3:
-		return int(e.Count) * 2
-	case 4:
-		return int(e.Count) * 4
-	case 5:
-		return int(e.Count) * 8
-	case 9:
-		return int(e.Count) * 8
-	case 10:
-		return int(e.Count) * 16
+	case 3, 8:
+		return int64(e.Count) * 2
+	case 4, 9, 11, 13:
+		return int64(e.Count) * 4
+	case 5, 10, 12:
+		return int64(e.Count) * 8
 	}
-	return int(e.Count)
+	return int64(e.Count)
 }
 
 func (e *IFDElement) Uint32(endian binary.ByteOrder) uint32 {
 	return endian.Uint32(e.rawValue)
 }
 
-func parseIFDElement(b []byte, ifd []byte, endian binary.ByteOrder) (*IFDElement, error) {
+// parseIFDElement parses the 12 bytes element.
+// Out-of-line value is read from the TIFF at the offset relative to the base.
+func parseIFDElement(b []byte, tiff []byte, base int, endian binary.ByteOrder) (*IFDElement, error) {
 	if len(b) != 12 {
 		return nil, fmt.Errorf("IFDElement expects 12 bytes but got %d bytes", len(b))
 	}
-	e := &IFDElement{
-		Tag:      endian.Uint16(b[0:2]),
-		Type:     IFDElementType(endian.Uint16(b[2:4])),
-		Count:    endian.Uint32(b[4:8]),
-		rawValue: b[8:12],
+	c, err := newCursor(b, 0, endian)
+	if err != nil {
+		return nil, err
+	}
+	e := &IFDElement{}
+	if e.Tag, err = c.ReadUint16(); err != nil {
+		return nil, err
+	}
+	t, err := c.ReadUint16()
+	if err != nil {
+		return nil, err
 	}
-	if e.Length() > 4 {
+	e.Type = IFDElementType(t)
+	if e.Count, err = c.ReadUint32(); err != nil {
+		return nil, err
+	}
+	if e.rawValue, err = c.ReadBytes(4); err != nil {
+		return nil, err
+	}
+	if length := e.length64(); length > 4 {
 		offset := e.Uint32(endian)
-		e.Value = ifd[offset : offset+uint32(e.Length())]
+		if int64(base)+int64(offset)+length > int64(len(tiff)) {
+			return nil, fmt.Errorf("Value at 0x%x (%d bytes) is out of TIFF", offset, length)
+		}
+		start := base + int(offset)
+		e.Value = tiff[start : start+e.Length()]
 	} else {
 		e.Value = e.rawValue
 	}
@@ -212,27 +519,57 @@ func parseIFDElement(b []byte, ifd []byte, endian binary.ByteOrder) (*IFDElement
 }
 
 func parse(r io.Reader) (*JPEGHeader, error) {
-	h, err := parseJPEGHeader(r)
+	h, err := parseJPEGHeader(r, readBytes, DecodeOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("Could not parse JPEG header: %s", err)
 	}
 	return h, nil
 }
 
+// Decode parses the JPEG header in the bytes.
+// It never logs, exits or panics on any input, so it can be used as a fuzzing entry point.
+func Decode(b []byte) (*JPEGHeader, error) {
+	return parse(bytes.NewReader(b))
+}
+
+// readFunc reads the length bytes from the reader.
+type readFunc func(r io.Reader, length int) ([]byte, error)
+
+// limitRead returns the read function which refuses to allocate more than the max bytes.
+func limitRead(read readFunc, max int) readFunc {
+	return func(r io.Reader, length int) ([]byte, error) {
+		if length < 0 || length > max {
+			return nil, fmt.Errorf("Refused to read %d bytes exceeding the limit of %d bytes", length, max)
+		}
+		return read(r, length)
+	}
+}
+
 func readBytes(r io.Reader, length int) ([]byte, error) {
 	b := make([]byte, length)
-	log.Printf("Reading %d bytes", len(b))
 	if n, err := r.Read(b); err != nil {
 		return nil, fmt.Errorf("Could not read %d bytes: %s", len(b), err)
 	} else if n != len(b) {
 		return nil, fmt.Errorf("Could not read %d bytes: got %d bytes", len(b), n)
 	}
-	log.Printf("%d bytes:\n%s", len(b), hex.Dump(b))
 	return b, nil
 }
 
+// dumpReader logs the bytes read from the underlying reader at debug level.
+type dumpReader struct {
+	r      io.Reader
+	logger *slog.Logger
+}
+
+func (d *dumpReader) Read(b []byte) (int, error) {
+	n, err := d.r.Read(b)
+	if n > 0 && d.logger.Enabled(context.Background(), slog.LevelDebug) {
+		d.logger.Debug("Read bytes", "length", n, "dump", hex.Dump(b[:n]))
+	}
+	return n, err
+}
+
 func writeBytes(w io.Writer, b []byte) error {
-	log.Printf("Writing %d bytes", len(b))
 	if n, err := w.Write(b); err != nil {
 		return fmt.Errorf("Could not write %d bytes: %s", len(b), err)
 	} else if n != len(b) {
@@ -241,21 +578,224 @@ func writeBytes(w io.Writer, b []byte) error {
 	return nil
 }
 
-func main() {
-	filename := os.Args[1]
+// cliLogger receives the debug logs of the command, enabled by -debug.
+var cliLogger = discardLogger
+
+// parseFile parses the file.
+// If the filename is "-", it reads raw or base64 encoded bytes from stdin.
+func parseFile(filename string, stdin io.Reader) (*JPEGHeader, error) {
+	if filename == stdinFilename {
+		b, err := readStdin(stdin)
+		if err != nil {
+			return nil, err
+		}
+		return parse(bytes.NewReader(b))
+	}
 	r, err := os.Open(filename)
 	if err != nil {
-		log.Fatalf("Could not open file: %s", err)
+		return nil, fmt.Errorf("Could not open file: %s", err)
 	}
 	defer r.Close()
+	return parse(&dumpReader{r, cliLogger})
+}
+
+const (
+	exitOK         = 0
+	exitParseError = 1
+	exitUsageError = 2
+)
+
+// usageError represents an error of the command line arguments.
+type usageError string
+
+func (e usageError) Error() string {
+	return string(e)
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [options] FILE...\nFILE can be - to read raw or base64 encoded JPEG from stdin.\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	format := flag.String("format", "json", "Output format (json, csv, geojson, exiftool or tree)")
+	remove := flag.String("remove", "", "Comma separated tag names or ids to remove, e.g. GPSInfo,MakerNote,0x0131")
+	out := flag.String("out", "", "Output file of the rewritten JPEG with -remove, or of the output format (default stdout)")
+	rawTag := flag.String("raw-tag", "", "Write the raw value of the tag, e.g. exif:0x927C or IFD0:Make")
+	hexBytes := flag.Bool("hex-bytes", false, "Encode byte values in hex instead of base64 in JSON")
+	count := flag.Bool("count", false, "Print the number of elements of each IFD instead of the values")
+	debug := flag.Bool("debug", false, "Dump the bytes read from the files to stderr")
+	flag.Parse()
+	if *debug {
+		cliLogger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	}
+	if *count {
+		*format = "count"
+	}
+	if *remove != "" {
+		os.Exit(exitCode(runRemove(os.Stdin, *remove, *out, flag.Args())))
+	}
+	if *rawTag != "" {
+		os.Exit(exitCode(withOutput(*out, func(w io.Writer) error {
+			return runRawTag(os.Stdin, w, *rawTag, flag.Args())
+		})))
+	}
+	os.Exit(exitCode(withOutput(*out, func(w io.Writer) error {
+		return run(os.Stdin, w, *format, *hexBytes, flag.Args())
+	})))
+}
 
-	header, err := parse(r)
+// withOutput calls fn with the output file, which is created or truncated.
+// If the filename is empty, it calls fn with stdout.
+func withOutput(filename string, fn func(w io.Writer) error) error {
+	if filename == "" {
+		return fn(os.Stdout)
+	}
+	f, err := os.Create(filename)
 	if err != nil {
-		log.Fatalf("Error: %s", err)
+		return fmt.Errorf("Could not create output file: %s", err)
+	}
+	if err := fn(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("Could not close output file: %s", err)
+	}
+	return nil
+}
+
+// exitCode prints the error and returns the exit code for it.
+func exitCode(err error) int {
+	if err == nil {
+		return exitOK
 	}
-	e := json.NewEncoder(os.Stdout)
-	e.SetIndent("", " ")
-	if err := e.Encode(header); err != nil {
-		log.Fatalf("Could not encode to json: %s", err)
+	log.Printf("Error: %s", err)
+	if _, ok := err.(usageError); ok {
+		flag.Usage()
+		return exitUsageError
 	}
+	return exitParseError
+}
+
+// run writes the headers of the files in the format.
+// It continues on an error of a file and returns an error at the end.
+// If hexBytes is set, byte values are encoded in hex in JSON.
+func run(stdin io.Reader, w io.Writer, format string, hexBytes bool, filenames []string) error {
+	if len(filenames) == 0 {
+		return usageError("No file is given")
+	}
+	var failed int
+	parseFiles := func(fn func(filename string, header *JPEGHeader) error) error {
+		for _, filename := range filenames {
+			header, err := parseFile(filename, stdin)
+			if err != nil {
+				log.Printf("Error: %s: %s", filename, err)
+				failed++
+				continue
+			}
+			if err := fn(filename, header); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	switch format {
+	case "json":
+		headers := make(map[string]*JPEGHeader)
+		if err := parseFiles(func(filename string, header *JPEGHeader) error {
+			if header.APP1 != nil {
+				header.APP1.jsonHexBytes = hexBytes
+			}
+			headers[filename] = header
+			return nil
+		}); err != nil {
+			return err
+		}
+		e := json.NewEncoder(w)
+		e.SetIndent("", " ")
+		var v interface{} = headers
+		if len(filenames) == 1 {
+			header, ok := headers[filenames[0]]
+			if !ok {
+				break
+			}
+			v = header
+		}
+		if err := e.Encode(v); err != nil {
+			return fmt.Errorf("Could not encode to json: %s", err)
+		}
+	case "csv":
+		cw := csv.NewWriter(w)
+		if err := cw.Write(csvHeader); err != nil {
+			return fmt.Errorf("Could not write csv: %s", err)
+		}
+		if err := parseFiles(func(filename string, header *JPEGHeader) error {
+			return writeCSV(cw, filename, header)
+		}); err != nil {
+			return fmt.Errorf("Could not write csv: %s", err)
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return fmt.Errorf("Could not write csv: %s", err)
+		}
+	case "geojson":
+		var features []*geoJSONFeature
+		if err := parseFiles(func(filename string, header *JPEGHeader) error {
+			if f := newGeoJSONFeature(filename, header.APP1); f != nil {
+				features = append(features, f)
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+		if err := json.NewEncoder(w).Encode(newGeoJSON(features)); err != nil {
+			return fmt.Errorf("Could not encode to json: %s", err)
+		}
+	case "exiftool":
+		var objects []map[string]interface{}
+		if err := parseFiles(func(filename string, header *JPEGHeader) error {
+			objects = append(objects, newExifToolObject(filename, header))
+			return nil
+		}); err != nil {
+			return err
+		}
+		e := json.NewEncoder(w)
+		e.SetIndent("", "  ")
+		if err := e.Encode(objects); err != nil {
+			return fmt.Errorf("Could not encode to json: %s", err)
+		}
+	case "tree":
+		if err := parseFiles(func(filename string, header *JPEGHeader) error {
+			if len(filenames) > 1 {
+				if _, err := fmt.Fprintf(w, "%s:\n", filename); err != nil {
+					return err
+				}
+			}
+			_, err := io.WriteString(w, header.Tree())
+			return err
+		}); err != nil {
+			return fmt.Errorf("Could not write tree: %s", err)
+		}
+	case "count":
+		if err := parseFiles(func(filename string, header *JPEGHeader) error {
+			summary := "Exif: absent"
+			if header.APP1 != nil {
+				summary = header.APP1.Summary()
+			}
+			if len(filenames) > 1 {
+				summary = filename + ": " + summary
+			}
+			_, err := fmt.Fprintln(w, summary)
+			return err
+		}); err != nil {
+			return fmt.Errorf("Could not write summary: %s", err)
+		}
+	default:
+		return usageError(fmt.Sprintf("Unknown format: %s", format))
+	}
+	if failed > 0 {
+		return fmt.Errorf("Could not parse %d of %d files", failed, len(filenames))
+	}
+	return nil
 }
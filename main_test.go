@@ -0,0 +1,297 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"log"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	for _, c := range []struct {
+		name      string
+		endian    binary.ByteOrder
+		thumbnail bool
+	}{
+		{"ii.jpg", binary.LittleEndian, true},
+		{"mm.jpg", binary.BigEndian, true},
+		{"nothumb.jpg", binary.LittleEndian, false},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			h := decodeTestdata(t, c.name)
+			if h.APP1 == nil {
+				t.Fatalf("APP1 wants non-nil")
+			}
+			if h.APP1.Endian != c.endian {
+				t.Errorf("Endian wants %s but %s", c.endian, h.APP1.Endian)
+			}
+			if e := h.APP1.IFD0.Find(0x010f); e == nil {
+				t.Errorf("Make wants non-nil")
+			}
+			if got := h.APP1.Thumbnail() != nil; got != c.thumbnail {
+				t.Errorf("Thumbnail wants %v but %v", c.thumbnail, got)
+			}
+		})
+	}
+}
+
+func TestDecode_NoExif(t *testing.T) {
+	h := decodeTestdata(t, "noexif.jpg")
+	if h.APP1 != nil {
+		t.Errorf("APP1 wants nil but %+v", h.APP1)
+	}
+}
+
+func TestDecodeTIFFBytes(t *testing.T) {
+	a, err := DecodeTIFFBytes(loadTestdata(t, "exif.tiff"))
+	if err != nil {
+		t.Fatalf("DecodeTIFFBytes error: %s", err)
+	}
+	if v, ok := a.Make(); !ok || v != "Canon" {
+		t.Errorf("Make wants Canon but %q", v)
+	}
+}
+
+func TestDecode_ImageDataOffset(t *testing.T) {
+	for _, name := range []string{"ii.jpg", "mm.jpg", "nothumb.jpg", "noexif.jpg"} {
+		t.Run(name, func(t *testing.T) {
+			b := loadTestdata(t, name)
+			h := decodeTestdata(t, name)
+			sos := bytes.LastIndex(b[:h.ImageDataOffset], []byte{0xff, markerSOS})
+			if sos == -1 {
+				t.Fatalf("SOS wants before 0x%x", h.ImageDataOffset)
+			}
+			length := int64(binary.BigEndian.Uint16(b[sos+2:]))
+			if want := int64(sos) + 2 + length; h.ImageDataOffset != want {
+				t.Errorf("ImageDataOffset wants 0x%x just after SOS but 0x%x", want, h.ImageDataOffset)
+			}
+			if b[h.ImageDataOffset] == 0xff && b[h.ImageDataOffset+1] != 0x00 {
+				t.Errorf("ImageDataOffset wants entropy-coded data but marker 0x%02x", b[h.ImageDataOffset+1])
+			}
+			if h.SOF == nil {
+				t.Errorf("SOF wants non-nil")
+			}
+		})
+	}
+}
+
+func TestJPEGHeader_WriteTo_NoLog(t *testing.T) {
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+	h := decodeTestdata(t, "ii.jpg")
+	var b bytes.Buffer
+	if _, err := h.WriteTo(&b); err != nil {
+		t.Fatalf("WriteTo error: %s", err)
+	}
+	if logs.Len() > 0 {
+		t.Errorf("WriteTo wants no log but %q", logs.String())
+	}
+}
+
+func TestIFD_findThumbnail(t *testing.T) {
+	b := make([]byte, 16)
+	for _, c := range []struct {
+		name           string
+		offset, length uint32
+		wantErr        bool
+	}{
+		{"in TIFF", 8, 8, false},
+		{"past the end", 8, 9, true},
+		{"offset out of TIFF", 17, 1, true},
+		{"overflow", 0xfffffff8, 0x10, true},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			d := &IFD{Elements: []*IFDElement{
+				newLongElement(tagJPEGInterchangeFormat, c.offset, binary.LittleEndian),
+				newLongElement(tagJPEGInterchangeFormatLength, c.length, binary.LittleEndian),
+			}}
+			thumbnail, err := d.findThumbnail(b, binary.LittleEndian)
+			if c.wantErr {
+				if err == nil {
+					t.Errorf("findThumbnail wants error but %d bytes", len(thumbnail))
+				}
+				return
+			}
+			if err != nil || len(thumbnail) != int(c.length) {
+				t.Errorf("findThumbnail wants %d bytes but %d bytes, %v", c.length, len(thumbnail), err)
+			}
+		})
+	}
+}
+
+func TestParseTIFFHeader(t *testing.T) {
+	for _, c := range []struct {
+		name    string
+		b       []byte
+		endian  binary.ByteOrder
+		offset  uint32
+		wantErr string
+	}{
+		{"II", []byte{0x49, 0x49, 0x2a, 0x00, 0x08, 0x00, 0x00, 0x00}, binary.LittleEndian, 8, ""},
+		{"MM", []byte{0x4d, 0x4d, 0x00, 0x2a, 0x00, 0x00, 0x00, 0x0a}, binary.BigEndian, 10, ""},
+		{"short", []byte{0x49, 0x49, 0x2a}, nil, 0, "TIFF header expects 8 bytes but got 3 bytes"},
+		{"no byte order mark", []byte{0x00, 0x00, 0x2a, 0x00, 0x08, 0x00, 0x00, 0x00}, nil, 0, "Invalid endian: header is 00 00 2a 00"},
+		{"wrong version", []byte{0x49, 0x49, 0x00, 0x2a, 0x08, 0x00, 0x00, 0x00}, nil, 0, "Invalid TIFF version: header is 49 49 00 2a"},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			endian, offset, err := parseTIFFHeader(c.b)
+			if c.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+					t.Errorf("Error wants %q but %v", c.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseTIFFHeader error: %s", err)
+			}
+			if endian != c.endian || offset != c.offset {
+				t.Errorf("Header wants %s, %d but %s, %d", c.endian, c.offset, endian, offset)
+			}
+		})
+	}
+}
+
+func TestIFD_SortedElements(t *testing.T) {
+	d := &IFD{Elements: []*IFDElement{
+		newShortElement(0x0112, 1, binary.LittleEndian),
+		newShortElement(0x010f, 2, binary.LittleEndian),
+		newShortElement(0x0110, 3, binary.LittleEndian),
+		newShortElement(0x010f, 4, binary.LittleEndian),
+	}}
+	elements := d.SortedElements()
+	var got []uint16
+	for _, e := range elements {
+		got = append(got, e.Tag)
+	}
+	if want := []uint16{0x010f, 0x010f, 0x0110, 0x0112}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Tags wants %04x but %04x", want, got)
+	}
+	if elements[0] != d.Elements[1] || elements[1] != d.Elements[3] {
+		t.Errorf("Duplicate tags wants the order of the IFD")
+	}
+	if d.Elements[0].Tag != 0x0112 {
+		t.Errorf("Elements wants not modified")
+	}
+}
+
+func TestDecodeTIFFBytes_Embedded(t *testing.T) {
+	// a container which embeds the TIFF at an arbitrary offset
+	tiff := loadTestdata(t, "exif.tiff")
+	container := append([]byte("container header"), tiff...)
+	a, err := DecodeTIFFBytes(container[16:])
+	if err != nil {
+		t.Fatalf("DecodeTIFFBytes error: %s", err)
+	}
+	if v, ok := a.Model(); !ok || v != "Canon EOS 5D Mark III" {
+		t.Errorf("Model wants Canon EOS 5D Mark III but %q", v)
+	}
+	if _, err := DecodeTIFFBytes(container); err == nil {
+		t.Errorf("DecodeTIFFBytes of the container wants error")
+	}
+}
+
+func TestDecodeTIFFBytes_TrailingGarbage(t *testing.T) {
+	tiff := loadTestdata(t, "exif.tiff")
+	want, err := DecodeTIFFBytes(tiff)
+	if err != nil {
+		t.Fatalf("DecodeTIFFBytes error: %s", err)
+	}
+	a, err := DecodeTIFFBytes(append(append([]byte{}, tiff...), bytes.Repeat([]byte{0xff}, 100)...))
+	if err != nil {
+		t.Fatalf("DecodeTIFFBytes with trailing bytes error: %s", err)
+	}
+	for _, kind := range []IFDKind{IFD0Kind, ExifIFDKind, GPSIFDKind, InteroperabilityIFDKind, IFD1Kind} {
+		if got, want := a.IFD(kind).rawValues, want.IFD(kind).rawValues; !bytes.Equal(got, want) {
+			t.Errorf("rawValues of %s wants %d bytes but %d bytes", kind, len(want), len(got))
+		}
+	}
+	if !bytes.Equal(a.Thumbnail(), want.Thumbnail()) {
+		t.Errorf("Thumbnail wants %d bytes but %d bytes", len(want.Thumbnail()), len(a.Thumbnail()))
+	}
+}
+
+func TestAPP1_RawTIFF(t *testing.T) {
+	h := decodeTestdata(t, "ii.jpg")
+	if want := loadTestdata(t, "exif.tiff"); !bytes.Equal(h.APP1.RawTIFF, want) {
+		t.Errorf("RawTIFF wants %d bytes of the TIFF block but %d bytes", len(want), len(h.APP1.RawTIFF))
+	}
+	if !bytes.HasPrefix(h.APP1.RawTIFF, []byte("II*\x00")) {
+		t.Errorf("RawTIFF wants to begin with the byte order mark but % x", h.APP1.RawTIFF[:4])
+	}
+}
+
+func TestParseIFD_ElementCountTooLarge(t *testing.T) {
+	// 0xffff elements declared in 14 bytes
+	b := []byte("II\x2a\x00\x08\x00\x00\x00\xff\xff\x00\x00\x00\x00")
+	_, err := parseIFD(b, 0, 8, binary.LittleEndian)
+	if err == nil || !strings.Contains(err.Error(), "65535 elements") {
+		t.Errorf("parseIFD wants error of element count but %v", err)
+	}
+	if _, err := DecodeTIFFBytes(b); err == nil {
+		t.Errorf("DecodeTIFFBytes wants error")
+	}
+}
+
+func TestIFD_FindLinkedIFD(t *testing.T) {
+	tiff := loadTestdata(t, "exif.tiff")
+	a, err := DecodeTIFFBytes(tiff)
+	if err != nil {
+		t.Fatalf("DecodeTIFFBytes error: %s", err)
+	}
+	exif, err := a.IFD0.FindLinkedIFD(tagExifIFDPointer, tiff, a.Endian)
+	if err != nil || exif == nil || exif.Find(tagExifVersion) == nil {
+		t.Errorf("FindLinkedIFD wants the Exif IFD but %+v, %v", exif, err)
+	}
+	if d, err := a.IFD0.FindLinkedIFD(0xffff, tiff, a.Endian); d != nil || err != nil {
+		t.Errorf("FindLinkedIFD of no link wants nil but %+v, %v", d, err)
+	}
+	d := &IFD{Elements: []*IFDElement{newLongElement(tagExifIFDPointer, uint32(len(tiff)-1), a.Endian)}}
+	if _, err := d.FindLinkedIFD(tagExifIFDPointer, tiff, a.Endian); err == nil || !strings.Contains(err.Error(), "beyond the TIFF") {
+		t.Errorf("FindLinkedIFD beyond the TIFF wants error but %v", err)
+	}
+}
+
+func TestExifTIFFOffset(t *testing.T) {
+	for _, c := range []struct {
+		name          string
+		b             string
+		wantOffset    int
+		wantCanonical bool
+		wantOK        bool
+	}{
+		{"canonical", "Exif\x00\x00II\x2a\x00", 6, true, true},
+		{"single NUL", "Exif\x00II\x2a\x00", 5, false, true},
+		{"no NUL", "ExifMM\x00\x2a", 4, false, true},
+		{"stray byte", "Exif\x00\xffII\x2a\x00", 6, false, true},
+		{"no byte order", "Exif\x00\xff\x00\x00\x00\x00\x00\x00", 0, false, false},
+		{"XMP", "http://ns.adobe.com/xap/1.0/\x00", 0, false, false},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			offset, canonical, ok := exifTIFFOffset([]byte(c.b))
+			if offset != c.wantOffset || canonical != c.wantCanonical || ok != c.wantOK {
+				t.Errorf("exifTIFFOffset wants %d, %v, %v but %d, %v, %v", c.wantOffset, c.wantCanonical, c.wantOK, offset, canonical, ok)
+			}
+		})
+	}
+}
+
+func TestDecode_NonCanonicalExifMarker(t *testing.T) {
+	tiff := loadTestdata(t, "exif.tiff")
+	segment := []byte{0xff, markerAPP1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(2+5+len(tiff)))
+	segment = append(append(segment, "Exif\x00"...), tiff...)
+	h, err := Decode(insertSegments(loadTestdata(t, "noexif.jpg"), segment))
+	if err != nil {
+		t.Fatalf("Decode error: %s", err)
+	}
+	if h.APP1 == nil {
+		t.Fatalf("APP1 wants present")
+	}
+	if v, ok := h.APP1.Model(); !ok || v != "Canon EOS 5D Mark III" {
+		t.Errorf("Model wants Canon EOS 5D Mark III but %q", v)
+	}
+}
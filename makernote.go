@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+const tagMakerNote = 0x927C
+
+// MakerNote represents the vendor specific IFD in the MakerNote tag.
+type MakerNote struct {
+	Vendor string
+	Endian binary.ByteOrder
+	IFD    *IFD
+}
+
+// MakerNoteDecoder decodes the MakerNote of a vendor.
+type MakerNoteDecoder interface {
+	// Decode returns nil if the MakerNote is not of the vendor.
+	Decode(a *APP1, e *IFDElement) (*MakerNote, error)
+}
+
+// makerNoteDecoders are tried in order.
+// Decoders detecting a header come first and ones detecting Make come last.
+var makerNoteDecoders = []MakerNoteDecoder{
+	nikonMakerNoteDecoder{},
+	appleMakerNoteDecoder{},
+	canonMakerNoteDecoder{},
+}
+
+// RegisterMakerNoteDecoder adds the decoder, which is tried before the built-in decoders.
+func RegisterMakerNoteDecoder(d MakerNoteDecoder) {
+	makerNoteDecoders = append([]MakerNoteDecoder{d}, makerNoteDecoders...)
+}
+
+// MakerNote parses the MakerNote tag in the Exif IFD.
+// It returns nil if the tag is not present.
+//
+// Offsets in a MakerNote are relative to a vendor specific origin:
+// Canon uses offsets relative to the TIFF header,
+// Nikon embeds its own TIFF header and uses offsets relative to it,
+// and Apple uses offsets relative to the beginning of the MakerNote.
+func (a *APP1) MakerNote() (*MakerNote, error) {
+	e := a.ExifIFD.Find(tagMakerNote)
+	if e == nil {
+		return nil, nil
+	}
+	if e.Length() <= 4 {
+		return nil, fmt.Errorf("MakerNote is too short: %d bytes", e.Length())
+	}
+	if a.RawTIFF == nil {
+		return nil, fmt.Errorf("MakerNote requires the raw TIFF")
+	}
+	for _, d := range makerNoteDecoders {
+		m, err := d.Decode(a, e)
+		if err != nil {
+			return nil, err
+		}
+		if m != nil {
+			return m, nil
+		}
+	}
+	cameraMake, _ := findTrimmedASCII(a.IFD0, tagMake)
+	return nil, fmt.Errorf("Unknown MakerNote of %q", cameraMake)
+}
+
+var nikonMakerNoteMarker = []byte("Nikon\x00\x02")
+
+type nikonMakerNoteDecoder struct{}
+
+func (nikonMakerNoteDecoder) Decode(a *APP1, e *IFDElement) (*MakerNote, error) {
+	if !bytes.HasPrefix(e.Value, nikonMakerNoteMarker) {
+		return nil, nil
+	}
+	// Nikon type 3: "Nikon\0" 2 bytes version 2 bytes reserved and TIFF header
+	base := int(e.Uint32(a.Endian)) + 10
+	if base > len(a.RawTIFF) {
+		return nil, fmt.Errorf("Nikon MakerNote header is out of TIFF")
+	}
+	endian, ifdOffset, err := parseTIFFHeader(a.RawTIFF[base:])
+	if err != nil {
+		return nil, fmt.Errorf("Could not parse Nikon MakerNote header: %s", err)
+	}
+	ifd, err := parseIFD(a.RawTIFF, base, ifdOffset, endian)
+	if err != nil {
+		return nil, fmt.Errorf("Could not parse Nikon MakerNote IFD: %s", err)
+	}
+	return &MakerNote{Vendor: "Nikon", Endian: endian, IFD: ifd}, nil
+}
+
+type canonMakerNoteDecoder struct{}
+
+func (canonMakerNoteDecoder) Decode(a *APP1, e *IFDElement) (*MakerNote, error) {
+	if cameraMake, _ := findTrimmedASCII(a.IFD0, tagMake); !strings.HasPrefix(cameraMake, "Canon") {
+		return nil, nil
+	}
+	ifd, err := parseIFD(a.RawTIFF, 0, e.Uint32(a.Endian), a.Endian)
+	if err != nil {
+		return nil, fmt.Errorf("Could not parse Canon MakerNote IFD: %s", err)
+	}
+	return &MakerNote{Vendor: "Canon", Endian: a.Endian, IFD: ifd}, nil
+}
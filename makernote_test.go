@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// decodeWithMakerNote writes the APP1 with the MakerNote and decodes it again.
+func decodeWithMakerNote(t *testing.T, a *APP1, makerNote []byte) *APP1 {
+	t.Helper()
+	a.SetElement(ExifIFDKind, newUndefinedElement(tagMakerNote, makerNote))
+	var b bytes.Buffer
+	if err := writeTIFF(&b, a); err != nil {
+		t.Fatalf("writeTIFF error: %s", err)
+	}
+	decoded, err := DecodeTIFFBytes(b.Bytes())
+	if err != nil {
+		t.Fatalf("DecodeTIFFBytes error: %s", err)
+	}
+	return decoded
+}
+
+func TestAPP1_MakerNote_Nikon(t *testing.T) {
+	// a big endian TIFF header embedded in the little endian TIFF
+	makerNote := []byte("Nikon\x00\x02\x10\x00\x00" +
+		"MM\x00\x2a\x00\x00\x00\x08" +
+		"\x00\x01" +
+		"\x00\x01\x00\x07\x00\x00\x00\x04" + "0210" +
+		"\x00\x00\x00\x00")
+	a := decodeWithMakerNote(t, newTestAPP1(map[uint16]string{tagMake: "NIKON CORPORATION"}), makerNote)
+	m, err := a.MakerNote()
+	if err != nil {
+		t.Fatalf("MakerNote error: %s", err)
+	}
+	if m.Vendor != "Nikon" || m.Endian != binary.BigEndian {
+		t.Errorf("MakerNote wants Nikon of big endian but %s of %s", m.Vendor, m.Endian)
+	}
+	e := m.IFD.Find(0x0001)
+	if e == nil || string(e.Value[:e.Length()]) != "0210" {
+		t.Errorf("MakerNote 0x0001 wants 0210 but %+v", e)
+	}
+}
+
+func TestAPP1_MakerNote_Canon(t *testing.T) {
+	makerNote := []byte("\x01\x00" +
+		"\x01\x00\x03\x00\x01\x00\x00\x00\x07\x00\x00\x00" +
+		"\x00\x00\x00\x00")
+	a := decodeWithMakerNote(t, newTestAPP1(map[uint16]string{tagMake: "Canon"}), makerNote)
+	m, err := a.MakerNote()
+	if err != nil {
+		t.Fatalf("MakerNote error: %s", err)
+	}
+	if m.Vendor != "Canon" {
+		t.Errorf("MakerNote wants Canon but %s", m.Vendor)
+	}
+	if v, ok := findUint16(m.IFD, 0x0001, m.Endian); !ok || v != 7 {
+		t.Errorf("MakerNote 0x0001 wants 7 but %d, %v", v, ok)
+	}
+}
+
+func TestAPP1_MakerNote_Unknown(t *testing.T) {
+	a := decodeWithMakerNote(t, newTestAPP1(map[uint16]string{tagMake: "Example"}), []byte("unknown maker note"))
+	if m, err := a.MakerNote(); err == nil {
+		t.Errorf("MakerNote of unknown vendor wants error but %+v", m)
+	}
+}
+
+func TestAPP1_MakerNote_NotPresent(t *testing.T) {
+	a := decodeTestdata(t, "ii.jpg").APP1
+	if m, err := a.MakerNote(); m != nil || err != nil {
+		t.Errorf("MakerNote wants nil but %+v, %v", m, err)
+	}
+}
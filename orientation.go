@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// OrientationConsistency compares Orientation in the 0th IFD, the 1st IFD and XMP.
+// It returns true if all present values are the same, and details of the values.
+// Photo apps may render the image and thumbnail differently if they are inconsistent.
+func (h *JPEGHeader) OrientationConsistency() (consistent bool, details string) {
+	type source struct {
+		name        string
+		orientation Orientation
+	}
+	var sources []source
+	if h.APP1 != nil {
+		if o, ok := h.APP1.Orientation(); ok {
+			sources = append(sources, source{"IFD0", o})
+		}
+		if v, ok := findUint16(h.APP1.IFD1, tagOrientation, h.APP1.Endian); ok {
+			sources = append(sources, source{"IFD1", Orientation(v)})
+		}
+	}
+	if s, ok := xmpProperty(h.XMP(), "tiff:Orientation"); ok {
+		if v, err := strconv.ParseUint(s, 10, 16); err == nil {
+			sources = append(sources, source{"XMP", Orientation(v)})
+		}
+	}
+	if len(sources) == 0 {
+		return true, "no orientation"
+	}
+	consistent = true
+	var parts []string
+	for _, s := range sources {
+		consistent = consistent && s.orientation == sources[0].orientation
+		parts = append(parts, fmt.Sprintf("%s=%d (%s)", s.name, s.orientation, s.orientation))
+	}
+	return consistent, strings.Join(parts, ", ")
+}
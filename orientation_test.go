@@ -0,0 +1,41 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestJPEGHeader_OrientationConsistency(t *testing.T) {
+	h := decodeTestdata(t, "ii.jpg")
+	if consistent, details := h.OrientationConsistency(); !consistent || !strings.Contains(details, "IFD0=6 (Rotate 90 CW)") {
+		t.Errorf("OrientationConsistency wants consistent but %v, %s", consistent, details)
+	}
+	if err := h.SetXMP([]byte(`<rdf:Description tiff:Orientation="6"/>`), nil); err != nil {
+		t.Fatalf("SetXMP error: %s", err)
+	}
+	if consistent, details := h.OrientationConsistency(); !consistent || !strings.Contains(details, "XMP=6") {
+		t.Errorf("OrientationConsistency wants consistent with XMP but %v, %s", consistent, details)
+	}
+	if err := h.SetXMP([]byte(`<rdf:Description><tiff:Orientation>1</tiff:Orientation></rdf:Description>`), nil); err != nil {
+		t.Fatalf("SetXMP error: %s", err)
+	}
+	if consistent, details := h.OrientationConsistency(); consistent || !strings.Contains(details, "XMP=1 (Normal)") {
+		t.Errorf("OrientationConsistency wants inconsistent but %v, %s", consistent, details)
+	}
+}
+
+func TestJPEGHeader_OrientationConsistency_None(t *testing.T) {
+	h := decodeTestdata(t, "noexif.jpg")
+	if consistent, details := h.OrientationConsistency(); !consistent || details != "no orientation" {
+		t.Errorf("OrientationConsistency wants no orientation but %v, %s", consistent, details)
+	}
+}
+
+func TestOrientation_String(t *testing.T) {
+	if s := Orientation(8).String(); s != "Rotate 270 CW" {
+		t.Errorf("String wants Rotate 270 CW but %s", s)
+	}
+	if s := Orientation(9).String(); s != "Orientation(9)" {
+		t.Errorf("String of unknown value wants Orientation(9) but %s", s)
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import "fmt"
+
+// maxPages is the maximum number of IFDs followed in the chain of a multi-page TIFF.
+const maxPages = 1024
+
+// parsePages follows the chain of the next IFD offsets from the 0th IFD.
+// The 0th and 1st IFD are shared with the APP1.
+func parsePages(b []byte, a *APP1) ([]*IFD, error) {
+	pages := []*IFD{a.IFD0}
+	visited := map[uint32]bool{}
+	if len(b) >= 8 {
+		visited[a.Endian.Uint32(b[4:8])] = true
+	}
+	for d := a.IFD0; d.NextIFDOffset != 0; {
+		offset := d.NextIFDOffset
+		if visited[offset] {
+			return nil, fmt.Errorf("IFD at 0x%x of page %d is already visited", offset, len(pages))
+		}
+		visited[offset] = true
+		if len(pages) >= maxPages {
+			return nil, fmt.Errorf("Too many pages exceeding %d", maxPages)
+		}
+		if len(pages) == 1 && a.IFD1 != nil {
+			d = a.IFD1
+		} else {
+			next, err := parseIFD(b, 0, offset, a.Endian)
+			if err != nil {
+				return nil, fmt.Errorf("Could not parse IFD of page %d: %s", len(pages), err)
+			}
+			d = next
+		}
+		pages = append(pages, d)
+	}
+	return pages, nil
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+// threePageTIFF returns exif.tiff with a 2nd page chained after the 1st IFD.
+func threePageTIFF(t *testing.T) []byte {
+	b := loadTestdata(t, "exif.tiff")
+	a, err := DecodeTIFFBytes(b)
+	if err != nil {
+		t.Fatalf("DecodeTIFFBytes error: %s", err)
+	}
+	next := int(a.IFD0.NextIFDOffset) + 2 + len(a.IFD1.Elements)*12
+	if len(b)%2 != 0 {
+		b = append(b, 0)
+	}
+	binary.LittleEndian.PutUint32(b[next:], uint32(len(b)))
+	page := make([]byte, 2+12+4)
+	binary.LittleEndian.PutUint16(page[0:], 1)
+	binary.LittleEndian.PutUint16(page[2:], tagImageWidth)
+	binary.LittleEndian.PutUint16(page[4:], 3)
+	binary.LittleEndian.PutUint32(page[6:], 1)
+	binary.LittleEndian.PutUint16(page[10:], 640)
+	return append(b, page...)
+}
+
+func TestDecodeTIFFBytes_Pages(t *testing.T) {
+	a, err := DecodeTIFFBytes(loadTestdata(t, "exif.tiff"))
+	if err != nil {
+		t.Fatalf("DecodeTIFFBytes error: %s", err)
+	}
+	if len(a.Pages) != 2 || a.Pages[0] != a.IFD0 || a.Pages[1] != a.IFD1 {
+		t.Errorf("Pages wants the 0th and 1st IFD but %d pages", len(a.Pages))
+	}
+
+	a, err = DecodeTIFFBytes(threePageTIFF(t))
+	if err != nil {
+		t.Fatalf("DecodeTIFFBytes error: %s", err)
+	}
+	if len(a.Pages) != 3 {
+		t.Fatalf("Pages wants 3 pages but %d pages", len(a.Pages))
+	}
+	e := a.Pages[2].Find(tagImageWidth)
+	if e == nil {
+		t.Fatalf("ImageWidth of the 3rd page wants present")
+	}
+	if v, err := e.Uint16s(a.Endian); err != nil || len(v) != 1 || v[0] != 640 {
+		t.Errorf("ImageWidth of the 3rd page wants 640 but %v, %v", v, err)
+	}
+}
+
+func TestDecodeTIFFBytes_PagesLoop(t *testing.T) {
+	b := loadTestdata(t, "exif.tiff")
+	a, err := DecodeTIFFBytes(b)
+	if err != nil {
+		t.Fatalf("DecodeTIFFBytes error: %s", err)
+	}
+	next := int(a.IFD0.NextIFDOffset) + 2 + len(a.IFD1.Elements)*12
+	binary.LittleEndian.PutUint32(b[next:], binary.LittleEndian.Uint32(b[4:8]))
+	if _, err := DecodeTIFFBytes(b); err == nil {
+		t.Errorf("DecodeTIFFBytes wants error for the loop of IFDs")
+	}
+}
+
+func TestAPP1_ClonePages(t *testing.T) {
+	a, err := DecodeTIFFBytes(threePageTIFF(t))
+	if err != nil {
+		t.Fatalf("DecodeTIFFBytes error: %s", err)
+	}
+	c := a.Clone()
+	if len(c.Pages) != 3 || c.Pages[0] != c.IFD0 || c.Pages[1] != c.IFD1 {
+		t.Fatalf("Pages of the copy wants the 0th and 1st IFD of the copy")
+	}
+	if c.Pages[2] == a.Pages[2] {
+		t.Errorf("3rd page of the copy wants a clone")
+	}
+}
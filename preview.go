@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+)
+
+const (
+	tagImageWidth      = 0x0100
+	tagImageLength     = 0x0101
+	tagStripOffsets    = 0x0111
+	tagStripByteCounts = 0x0117
+	tagSubIFDs         = 0x014A
+)
+
+// Preview represents an embedded JPEG image such as the thumbnail or a preview in a SubIFD.
+// Offset is relative to the TIFF header.
+type Preview struct {
+	Source string // "IFD1" or "SubIFD0", "SubIFD1", ...
+	Width  int
+	Height int
+	Offset int
+	Length int
+}
+
+// SubIFDs parses the IFDs pointed by the SubIFDs tag in the 0th IFD, which DNG and raw files have.
+func (a *APP1) SubIFDs() ([]*IFD, error) {
+	e := a.IFD0.Find(tagSubIFDs)
+	if e == nil {
+		return nil, nil
+	}
+	if e.Type != 4 && e.Type != 13 {
+		return nil, fmt.Errorf("SubIFDs expects LONG or IFD but got %s", e.Type)
+	}
+	if err := e.checkLength(); err != nil {
+		return nil, err
+	}
+	var ifds []*IFD
+	for i := 0; i < int(e.Count); i++ {
+		offset := a.Endian.Uint32(e.Value[i*4 : i*4+4])
+		ifd, err := parseIFD(a.RawTIFF, 0, offset, a.Endian)
+		if err != nil {
+			return nil, fmt.Errorf("Could not parse SubIFD #%d: %s", i, err)
+		}
+		ifds = append(ifds, ifd)
+	}
+	return ifds, nil
+}
+
+// Previews returns the embedded JPEG images in the 1st IFD and SubIFDs.
+// An image is located by JPEGInterchangeFormat or a single strip of JPEG compression.
+// The dimensions are taken from the IFD, or the SOF of the image if not present.
+func (a *APP1) Previews() ([]Preview, error) {
+	if a.RawTIFF == nil {
+		return nil, fmt.Errorf("Previews requires the raw TIFF")
+	}
+	var previews []Preview
+	if p, ok := a.findPreview(a.IFD1); ok {
+		p.Source = IFD1Kind.String()
+		previews = append(previews, p)
+	}
+	subIFDs, err := a.SubIFDs()
+	if err != nil {
+		return nil, err
+	}
+	for i, d := range subIFDs {
+		if p, ok := a.findPreview(d); ok {
+			p.Source = fmt.Sprintf("SubIFD%d", i)
+			previews = append(previews, p)
+		}
+	}
+	return previews, nil
+}
+
+func (a *APP1) findPreview(d *IFD) (Preview, bool) {
+	if d == nil {
+		return Preview{}, false
+	}
+	var p Preview
+	offset, okOffset := findUint(d, tagJPEGInterchangeFormat, a.Endian)
+	length, okLength := findUint(d, tagJPEGInterchangeFormatLength, a.Endian)
+	if !okOffset || !okLength {
+		compression, _ := findUint16(d, tagCompression, a.Endian)
+		if compression != 6 && compression != 7 {
+			return Preview{}, false
+		}
+		strips := d.Find(tagStripOffsets)
+		counts := d.Find(tagStripByteCounts)
+		if strips == nil || counts == nil || strips.Count != 1 || counts.Count != 1 {
+			return Preview{}, false
+		}
+		offset, okOffset = findUint(d, tagStripOffsets, a.Endian)
+		length, okLength = findUint(d, tagStripByteCounts, a.Endian)
+		if !okOffset || !okLength {
+			return Preview{}, false
+		}
+	}
+	if int64(offset)+int64(length) > int64(len(a.RawTIFF)) {
+		return Preview{}, false
+	}
+	p.Offset, p.Length = int(offset), int(length)
+	width, okWidth := findUint(d, tagImageWidth, a.Endian)
+	height, okHeight := findUint(d, tagImageLength, a.Endian)
+	if okWidth && okHeight {
+		p.Width, p.Height = int(width), int(height)
+	} else if h, err := Decode(a.PreviewData(p)); err == nil && h.SOF != nil {
+		p.Width, p.Height = int(h.SOF.Width), int(h.SOF.Height)
+	}
+	return p, true
+}
+
+// PreviewData returns the bytes of the preview image,
+// or nil if the range is out of the raw TIFF, e.g. the preview is not found by Previews or RawTIFF is changed.
+func (a *APP1) PreviewData(p Preview) []byte {
+	if p.Offset < 0 || p.Length < 0 || int64(p.Offset)+int64(p.Length) > int64(len(a.RawTIFF)) {
+		return nil
+	}
+	return a.RawTIFF[p.Offset : p.Offset+p.Length]
+}
+
+// LargestEmbeddedImage returns the largest image by the dimensions among the previews
+// and the main image of the 0th IFD if it is JPEG compressed, such as a JPEG stored as TIFF.
+// The format is "jpeg" if the image begins with SOI, or "unknown" otherwise.
+func (a *APP1) LargestEmbeddedImage() ([]byte, string, error) {
+	previews, err := a.Previews()
+	if err != nil {
+		return nil, "", err
+	}
+	if p, ok := a.findPreview(a.IFD0); ok {
+		p.Source = IFD0Kind.String()
+		previews = append(previews, p)
+	}
+	if len(previews) == 0 {
+		return nil, "", fmt.Errorf("No embedded image found")
+	}
+	largest := previews[0]
+	for _, p := range previews[1:] {
+		area, largestArea := int64(p.Width)*int64(p.Height), int64(largest.Width)*int64(largest.Height)
+		if area > largestArea || (area == largestArea && p.Length > largest.Length) {
+			largest = p
+		}
+	}
+	b := a.PreviewData(largest)
+	if b == nil {
+		return nil, "", fmt.Errorf("%s is out of the TIFF", largest.Source)
+	}
+	if bytes.HasPrefix(b, soiMarker) {
+		return b, "jpeg", nil
+	}
+	return b, "unknown", nil
+}
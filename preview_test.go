@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// subIFDTIFF returns a TIFF of the 0th IFD with a SubIFD of the JPEG in a single strip.
+func subIFDTIFF(jpeg []byte) []byte {
+	e := binary.LittleEndian
+	var b bytes.Buffer
+	b.WriteString("II\x2a\x00")
+	for _, v := range []interface{}{
+		uint32(8),
+		// 0th IFD at 8
+		uint16(1),
+		uint16(tagSubIFDs), uint16(4), uint32(1), uint32(26),
+		uint32(0),
+		// SubIFD at 26
+		uint16(3),
+		uint16(tagCompression), uint16(3), uint32(1), uint32(6),
+		uint16(tagStripOffsets), uint16(4), uint32(1), uint32(68),
+		uint16(tagStripByteCounts), uint16(4), uint32(1), uint32(len(jpeg)),
+		uint32(0),
+	} {
+		binary.Write(&b, e, v)
+	}
+	b.Write(jpeg)
+	return b.Bytes()
+}
+
+func TestAPP1_SubIFDs(t *testing.T) {
+	jpeg := loadTestdata(t, "noexif.jpg")
+	a, err := DecodeTIFFBytes(subIFDTIFF(jpeg))
+	if err != nil {
+		t.Fatalf("DecodeTIFFBytes error: %s", err)
+	}
+	ifds, err := a.SubIFDs()
+	if err != nil || len(ifds) != 1 || len(ifds[0].Elements) != 3 {
+		t.Fatalf("SubIFDs wants an IFD of 3 elements but %+v, %v", ifds, err)
+	}
+	previews, err := a.Previews()
+	if err != nil {
+		t.Fatalf("Previews error: %s", err)
+	}
+	want := Preview{Source: "SubIFD0", Width: 2, Height: 2, Offset: 68, Length: len(jpeg)}
+	if len(previews) != 1 || previews[0] != want {
+		t.Fatalf("Previews wants %+v but %+v", want, previews)
+	}
+	if !bytes.Equal(a.PreviewData(previews[0]), jpeg) {
+		t.Errorf("PreviewData wants the JPEG")
+	}
+}
+
+func TestAPP1_Previews_Thumbnail(t *testing.T) {
+	a := decodeTestdata(t, "ii.jpg").APP1
+	if ifds, err := a.SubIFDs(); ifds != nil || err != nil {
+		t.Errorf("SubIFDs wants nil but %+v, %v", ifds, err)
+	}
+	previews, err := a.Previews()
+	if err != nil {
+		t.Fatalf("Previews error: %s", err)
+	}
+	if len(previews) != 1 || previews[0].Source != "IFD1" || previews[0].Width != 2 || previews[0].Height != 2 {
+		t.Fatalf("Previews wants the thumbnail of 2x2 but %+v", previews)
+	}
+	if !bytes.Equal(a.PreviewData(previews[0]), a.Thumbnail()) {
+		t.Errorf("PreviewData wants the thumbnail")
+	}
+	if _, err := NewAPP1(a.Endian).Previews(); err == nil {
+		t.Errorf("Previews without the raw TIFF wants error")
+	}
+}
+
+func TestAPP1_SubIFDs_InvalidType(t *testing.T) {
+	a := newTestAPP1(nil)
+	a.IFD0.Set(newShortElement(tagSubIFDs, 26, a.Endian))
+	if _, err := a.SubIFDs(); err == nil {
+		t.Errorf("SubIFDs of SHORT wants error")
+	}
+}
+
+func TestAPP1_LargestEmbeddedImage(t *testing.T) {
+	a := decodeTestdata(t, "ii.jpg").APP1
+	b, format, err := a.LargestEmbeddedImage()
+	if err != nil || format != "jpeg" || !bytes.Equal(b, a.Thumbnail()) {
+		t.Errorf("LargestEmbeddedImage wants the thumbnail of jpeg but %d bytes, %q, %v", len(b), format, err)
+	}
+
+	jpeg := loadTestdata(t, "noexif.jpg")
+	a, err = DecodeTIFFBytes(subIFDTIFF(jpeg))
+	if err != nil {
+		t.Fatalf("DecodeTIFFBytes error: %s", err)
+	}
+	b, format, err = a.LargestEmbeddedImage()
+	if err != nil || format != "jpeg" || !bytes.Equal(b, jpeg) {
+		t.Errorf("LargestEmbeddedImage wants the JPEG of SubIFD but %d bytes, %q, %v", len(b), format, err)
+	}
+
+	a = decodeTestdata(t, "nothumb.jpg").APP1
+	if _, _, err := a.LargestEmbeddedImage(); err == nil {
+		t.Errorf("LargestEmbeddedImage wants error if no image")
+	}
+}
+
+func TestAPP1_PreviewData_OutOfRange(t *testing.T) {
+	a := decodeTestdata(t, "ii.jpg").APP1
+	for _, p := range []Preview{
+		{Offset: len(a.RawTIFF), Length: 1},
+		{Offset: -1, Length: 1},
+		{Offset: 0, Length: -1},
+	} {
+		if b := a.PreviewData(p); b != nil {
+			t.Errorf("PreviewData of %+v wants nil but %d bytes", p, len(b))
+		}
+	}
+	previews, err := a.Previews()
+	if err != nil {
+		t.Fatalf("Previews error: %s", err)
+	}
+	a.RawTIFF = a.RawTIFF[:previews[0].Offset]
+	if b := a.PreviewData(previews[0]); b != nil {
+		t.Errorf("PreviewData after RawTIFF shrinks wants nil but %d bytes", len(b))
+	}
+}
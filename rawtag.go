@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// parseIFDKind returns the kind of the name such as "exif" or "IFD0", case insensitive.
+func parseIFDKind(s string) (IFDKind, error) {
+	for kind, name := range ifdKindNames {
+		if strings.EqualFold(name, s) {
+			return kind, nil
+		}
+	}
+	return 0, fmt.Errorf("Unknown IFD: %s", s)
+}
+
+// parseTagSelector parses the IFD and tag such as "exif:0x927C" or "IFD0:Make".
+func parseTagSelector(s string) (IFDKind, uint16, error) {
+	parts := strings.SplitN(s, ":", 2)
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("Tag must be IFD:TAG, e.g. exif:0x927C but got %s", s)
+	}
+	kind, err := parseIFDKind(parts[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	refs, err := resolveTag(parts[1])
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, ref := range refs {
+		if ref.kind == nil || *ref.kind == kind {
+			return kind, ref.id, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("Tag %s is not in %s IFD", parts[1], kind)
+}
+
+// runRawTag writes the raw value of the tag in the file to the writer.
+func runRawTag(stdin io.Reader, w io.Writer, selector string, filenames []string) error {
+	if len(filenames) != 1 {
+		return usageError("Exactly one file must be given with -raw-tag")
+	}
+	kind, tag, err := parseTagSelector(selector)
+	if err != nil {
+		return usageError(err.Error())
+	}
+	h, err := parseFile(filenames[0], stdin)
+	if err != nil {
+		return fmt.Errorf("Could not parse %s: %s", filenames[0], err)
+	}
+	if h.APP1 == nil {
+		return fmt.Errorf("Exif not found in %s", filenames[0])
+	}
+	e := h.APP1.IFD(kind).Find(tag)
+	if e == nil {
+		return fmt.Errorf("Tag 0x%04X not found in %s IFD", tag, kind)
+	}
+	if err := e.checkLength(); err != nil {
+		return err
+	}
+	return writeBytes(w, e.Value[:e.Length()])
+}
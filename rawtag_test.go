@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestParseTagSelector(t *testing.T) {
+	for _, c := range []struct {
+		s        string
+		wantKind IFDKind
+		wantTag  uint16
+	}{
+		{"IFD0:Make", IFD0Kind, tagMake},
+		{"exif:0x927C", ExifIFDKind, tagMakerNote},
+		{"GPS:GPSLatitude", GPSIFDKind, tagGPSLatitude},
+	} {
+		kind, tag, err := parseTagSelector(c.s)
+		if err != nil || kind != c.wantKind || tag != c.wantTag {
+			t.Errorf("parseTagSelector(%s) wants %s 0x%04X but %s 0x%04X, %v", c.s, c.wantKind, c.wantTag, kind, tag, err)
+		}
+	}
+	for _, s := range []string{"Make", "foo:Make", "IFD0:NoSuchTag", "exif:Make"} {
+		if _, _, err := parseTagSelector(s); err == nil {
+			t.Errorf("parseTagSelector(%s) wants error", s)
+		}
+	}
+}
+
+func TestRunRawTag(t *testing.T) {
+	var b bytes.Buffer
+	if err := runRawTag(nil, &b, "exif:ExifVersion", []string{"testdata/ii.jpg"}); err != nil {
+		t.Fatalf("runRawTag error: %s", err)
+	}
+	if b.String() != "0230" {
+		t.Errorf("runRawTag wants 0230 but %q", b.String())
+	}
+	b.Reset()
+	if err := runRawTag(nil, &b, "IFD0:Make", []string{"testdata/ii.jpg"}); err != nil || b.String() != "Canon\x00" {
+		t.Errorf("runRawTag wants Canon with NUL but %q, %v", b.String(), err)
+	}
+}
+
+func TestRunRawTag_Error(t *testing.T) {
+	for _, c := range []struct {
+		name      string
+		selector  string
+		filenames []string
+		usage     bool
+	}{
+		{"no file", "IFD0:Make", nil, true},
+		{"invalid selector", "Make", []string{"testdata/ii.jpg"}, true},
+		{"not found", "exif:MakerNote", []string{"testdata/ii.jpg"}, false},
+		{"no Exif", "IFD0:Make", []string{"testdata/noexif.jpg"}, false},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			err := runRawTag(nil, io.Discard, c.selector, c.filenames)
+			if err == nil {
+				t.Fatalf("runRawTag wants error")
+			}
+			if _, usage := err.(usageError); usage != c.usage {
+				t.Errorf("runRawTag wants usageError %v but %v", c.usage, err)
+			}
+		})
+	}
+}
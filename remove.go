@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+// tagRef is a tag resolved from a name or id.
+// If kind is nil, the tag is removed from any IFD.
+type tagRef struct {
+	kind *IFDKind
+	id   uint16
+}
+
+// resolveTag resolves the tag name such as "Software" or id such as "0x0131".
+// The suffix "IFDPointer" of a link tag can be omitted, e.g. "GPSInfo".
+func resolveTag(s string) ([]tagRef, error) {
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		id, err := strconv.ParseUint(s[2:], 16, 16)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid tag id %s: %s", s, err)
+		}
+		return []tagRef{{id: uint16(id)}}, nil
+	}
+	var refs []tagRef
+	for _, tag := range KnownTags() {
+		if tag.Name == s || strings.TrimSuffix(tag.Name, "IFDPointer") == s {
+			kind := tag.IFD
+			refs = append(refs, tagRef{kind: &kind, id: tag.ID})
+		}
+	}
+	if len(refs) == 0 {
+		return nil, fmt.Errorf("Unknown tag: %s", s)
+	}
+	return refs, nil
+}
+
+// removeTags removes the tags of the comma separated names or ids.
+func removeTags(a *APP1, names string) error {
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		refs, err := resolveTag(name)
+		if err != nil {
+			return err
+		}
+		for _, ref := range refs {
+			if ref.kind != nil {
+				a.RemoveElement(*ref.kind, ref.id)
+				continue
+			}
+			for _, kind := range []IFDKind{IFD0Kind, ExifIFDKind, GPSIFDKind, InteroperabilityIFDKind, IFD1Kind} {
+				a.RemoveElement(kind, ref.id)
+			}
+		}
+	}
+	return nil
+}
+
+// readFile reads the whole file.
+// If the filename is "-", it reads raw or base64 encoded bytes from stdin.
+func readFile(filename string, stdin io.Reader) ([]byte, error) {
+	if filename == stdinFilename {
+		return readStdin(stdin)
+	}
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("Could not read file: %s", err)
+	}
+	return b, nil
+}
+
+// runRemove removes the tags from the file and writes it to the output file.
+func runRemove(stdin io.Reader, names string, out string, filenames []string) error {
+	if len(filenames) != 1 {
+		return usageError("Exactly one file must be given with -remove")
+	}
+	if out == "" {
+		return usageError("-out must be given with -remove")
+	}
+	b, err := readFile(filenames[0], stdin)
+	if err != nil {
+		return err
+	}
+	h, err := Decode(b)
+	if err != nil {
+		return fmt.Errorf("Could not parse %s: %s", filenames[0], err)
+	}
+	if h.APP1 == nil {
+		return fmt.Errorf("Exif not found in %s", filenames[0])
+	}
+	if err := removeTags(h.APP1, names); err != nil {
+		return usageError(err.Error())
+	}
+	var w bytes.Buffer
+	if err := writeJPEGHeader(&w, h); err != nil {
+		return fmt.Errorf("Could not write JPEG: %s", err)
+	}
+	w.Write(b[h.ImageDataOffset:])
+	if err := ioutil.WriteFile(out, w.Bytes(), 0644); err != nil {
+		return fmt.Errorf("Could not write file: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveTag(t *testing.T) {
+	refs, err := resolveTag("GPSInfo")
+	if err != nil || len(refs) == 0 || *refs[0].kind != IFD0Kind {
+		t.Fatalf("resolveTag(GPSInfo) wants the pointer in IFD0 but %+v, %v", refs, err)
+	}
+	for _, ref := range refs {
+		if ref.id != tagGPSInfoIFDPointer {
+			t.Errorf("resolveTag(GPSInfo) wants 0x%04X but 0x%04X", tagGPSInfoIFDPointer, ref.id)
+		}
+	}
+	refs, err = resolveTag("0x0131")
+	if err != nil || len(refs) != 1 || refs[0].kind != nil || refs[0].id != 0x0131 {
+		t.Errorf("resolveTag(0x0131) wants the id of any IFD but %+v, %v", refs, err)
+	}
+	for _, s := range []string{"0xZZ", "NoSuchTag"} {
+		if _, err := resolveTag(s); err == nil {
+			t.Errorf("resolveTag(%s) wants error", s)
+		}
+	}
+}
+
+func TestRemoveTags(t *testing.T) {
+	a := decodeTestdata(t, "ii.jpg").APP1
+	if err := removeTags(a, "GPSInfo, Model,0x0110,"); err != nil {
+		t.Fatalf("removeTags error: %s", err)
+	}
+	if a.GPSIFD != nil || a.IFD0.Find(tagGPSInfoIFDPointer) != nil {
+		t.Errorf("removeTags wants the GPS IFD removed")
+	}
+	if _, ok := a.Model(); ok {
+		t.Errorf("removeTags wants Model removed")
+	}
+	if _, ok := a.Make(); !ok {
+		t.Errorf("removeTags wants Make kept")
+	}
+	if err := removeTags(a, "NoSuchTag"); err == nil {
+		t.Errorf("removeTags of unknown tag wants error")
+	}
+}
+
+func TestAPP1_RemoveElement(t *testing.T) {
+	a := decodeTestdata(t, "ii.jpg").APP1
+	if !a.RemoveElement(IFD1Kind, tagJPEGInterchangeFormat) || a.Thumbnail() != nil {
+		t.Errorf("RemoveElement wants the thumbnail removed")
+	}
+	if !a.RemoveElement(IFD0Kind, tagExifIFDPointer) || a.ExifIFD != nil || a.InteroperabilityIFD != nil {
+		t.Errorf("RemoveElement wants the Exif and Interoperability IFD removed")
+	}
+	if a.RemoveElement(IFD0Kind, tagExifIFDPointer) {
+		t.Errorf("RemoveElement of removed tag wants false")
+	}
+}
+
+func TestRunRemove(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out.jpg")
+	if err := runRemove(nil, "GPSInfo", out, []string{"testdata/ii.jpg"}); err != nil {
+		t.Fatalf("runRemove error: %s", err)
+	}
+	b, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("ReadFile error: %s", err)
+	}
+	h, err := Decode(b)
+	if err != nil {
+		t.Fatalf("Decode error: %s", err)
+	}
+	if _, _, ok := h.APP1.LatLng(); ok {
+		t.Errorf("LatLng wants removed")
+	}
+	if v, ok := h.APP1.Model(); !ok || v != "Canon EOS 5D Mark III" {
+		t.Errorf("Model wants kept but %q", v)
+	}
+}
+
+func TestRunRemove_UsageError(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out.jpg")
+	for _, c := range []struct {
+		name      string
+		names     string
+		out       string
+		filenames []string
+	}{
+		{"no out", "GPSInfo", "", []string{"testdata/ii.jpg"}},
+		{"no file", "GPSInfo", out, nil},
+		{"unknown tag", "NoSuchTag", out, []string{"testdata/ii.jpg"}},
+	} {
+		err := runRemove(nil, c.names, c.out, c.filenames)
+		if _, ok := err.(usageError); !ok {
+			t.Errorf("runRemove of %s wants usageError but %v", c.name, err)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRun_JSON(t *testing.T) {
+	var b bytes.Buffer
+	if err := run(nil, &b, "json", false, []string{"testdata/ii.jpg"}); err != nil {
+		t.Fatalf("run error: %s", err)
+	}
+	var v map[string]interface{}
+	if err := json.Unmarshal(b.Bytes(), &v); err != nil {
+		t.Fatalf("Could not decode json: %s", err)
+	}
+	if _, ok := v["APP1"]; !ok {
+		t.Errorf("JSON of a file wants the header but %v", v)
+	}
+}
+
+func TestRun_MultipleFiles(t *testing.T) {
+	var b bytes.Buffer
+	filenames := []string{"testdata/ii.jpg", "testdata/mm.jpg", "testdata/noexif.jpg"}
+	if err := run(nil, &b, "json", false, filenames); err != nil {
+		t.Fatalf("run error: %s", err)
+	}
+	var v map[string]map[string]interface{}
+	if err := json.Unmarshal(b.Bytes(), &v); err != nil {
+		t.Fatalf("Could not decode json: %s", err)
+	}
+	if len(v) != len(filenames) {
+		t.Errorf("JSON wants %d files but %d", len(filenames), len(v))
+	}
+	for _, filename := range filenames {
+		if _, ok := v[filename]; !ok {
+			t.Errorf("JSON wants the key %s", filename)
+		}
+	}
+}
+
+func TestRun_MissingFile(t *testing.T) {
+	var b bytes.Buffer
+	err := run(nil, &b, "json", false, []string{"testdata/ii.jpg", "testdata/missing.jpg"})
+	if err == nil {
+		t.Fatalf("run wants error")
+	}
+	if _, ok := err.(usageError); ok {
+		t.Errorf("Error wants a parse error but %T", err)
+	}
+	var v map[string]interface{}
+	if err := json.Unmarshal(b.Bytes(), &v); err != nil {
+		t.Fatalf("Could not decode json: %s", err)
+	}
+	if _, ok := v["testdata/ii.jpg"]; !ok || len(v) != 1 {
+		t.Errorf("JSON wants the valid file only but %v", v)
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	flag.CommandLine.SetOutput(io.Discard)
+	defer flag.CommandLine.SetOutput(nil)
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+	for _, c := range []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"ok", nil, exitOK},
+		{"usage", usageError("No file is given"), exitUsageError},
+		{"parse", fmt.Errorf("Could not parse 1 of 1 files"), exitParseError},
+	} {
+		if got := exitCode(c.err); got != c.want {
+			t.Errorf("exitCode of %s wants %d but %d", c.name, c.want, got)
+		}
+	}
+}
+
+func TestRun_UsageError(t *testing.T) {
+	for _, c := range []struct {
+		format    string
+		filenames []string
+	}{
+		{"json", nil},
+		{"unknown", []string{"testdata/ii.jpg"}},
+	} {
+		err := run(nil, io.Discard, c.format, false, c.filenames)
+		if _, ok := err.(usageError); !ok {
+			t.Errorf("run(%s, %v) wants usageError but %v", c.format, c.filenames, err)
+		}
+	}
+}
+
+func TestWithOutput(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out.json")
+	if err := withOutput(out, func(w io.Writer) error {
+		return run(nil, w, "json", false, []string{"testdata/ii.jpg"})
+	}); err != nil {
+		t.Fatalf("withOutput error: %s", err)
+	}
+	b, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("Could not read output file: %s", err)
+	}
+	var v map[string]interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		t.Fatalf("Could not decode json of output file: %s", err)
+	}
+
+	want := fmt.Errorf("error of fn")
+	if err := withOutput(out, func(w io.Writer) error { return want }); err != want {
+		t.Errorf("withOutput wants the error of fn but %v", err)
+	}
+	if err := withOutput(filepath.Join(out, "out.json"), func(w io.Writer) error { return nil }); err == nil {
+		t.Errorf("withOutput wants error if the file could not be created")
+	}
+}
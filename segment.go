@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+const (
+	markerAPP0 = 0xe0
+	markerAPP1 = 0xe1
+	markerSOS  = 0xda
+	markerEOI  = 0xd9
+)
+
+// segment is a marker segment of JPEG.
+type segment struct {
+	marker byte
+	data   []byte // without the marker and length
+	exif   bool   // true if this is the Exif APP1
+	fill   int    // number of fill bytes (0xff) before the marker
+}
+
+// isStandaloneMarker returns true if the marker has no length and data.
+func isStandaloneMarker(marker byte) bool {
+	return marker == 0x01 || (marker >= 0xd0 && marker <= 0xd9)
+}
+
+// isSOFMarker returns true if the marker is one of SOF0-SOF15.
+// DHT (0xc4), JPG (0xc8) and DAC (0xcc) are excluded.
+func isSOFMarker(marker byte) bool {
+	return marker >= 0xc0 && marker <= 0xcf && marker != 0xc4 && marker != 0xc8 && marker != 0xcc
+}
+
+func parseSegment(r io.Reader, read readFunc) (*segment, error) {
+	b, err := read(r, 2)
+	if err != nil {
+		return nil, err
+	}
+	if b[0] != 0xff {
+		return nil, fmt.Errorf("Marker expects 0xff but got 0x%02x", b[0])
+	}
+	s := &segment{marker: b[1]}
+	// a marker may be preceded by any number of fill bytes
+	for s.marker == 0xff {
+		b, err = read(r, 1)
+		if err != nil {
+			return nil, err
+		}
+		s.fill++
+		s.marker = b[0]
+	}
+	if isStandaloneMarker(s.marker) {
+		return s, nil
+	}
+	b, err = read(r, 2)
+	if err != nil {
+		return nil, err
+	}
+	length := binary.BigEndian.Uint16(b)
+	if length < 2 {
+		return nil, fmt.Errorf("Segment 0x%02x has invalid length %d", s.marker, length)
+	}
+	s.data, err = read(r, int(length)-2)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// size returns the number of bytes of the segment including the fill bytes, marker and length.
+func (s *segment) size() int {
+	if isStandaloneMarker(s.marker) {
+		return s.fill + 2
+	}
+	return s.fill + 4 + len(s.data)
+}
+
+func writeSegment(w io.Writer, s *segment) error {
+	if err := writeBytes(w, []byte{0xff, s.marker}); err != nil {
+		return err
+	}
+	if isStandaloneMarker(s.marker) {
+		return nil
+	}
+	length := make([]byte, 2)
+	binary.BigEndian.PutUint16(length, uint16(len(s.data)+2))
+	if err := writeBytes(w, length); err != nil {
+		return err
+	}
+	return writeBytes(w, s.data)
+}
+
+// SOF represents the frame header.
+type SOF struct {
+	Marker     byte
+	Precision  uint8
+	Height     uint16
+	Width      uint16
+	Components uint8
+}
+
+func parseSOF(s *segment) (*SOF, error) {
+	if len(s.data) < 6 {
+		return nil, fmt.Errorf("SOF expects 6 bytes but got %d bytes", len(s.data))
+	}
+	return &SOF{
+		Marker:     s.marker,
+		Precision:  s.data[0],
+		Height:     binary.BigEndian.Uint16(s.data[1:3]),
+		Width:      binary.BigEndian.Uint16(s.data[3:5]),
+		Components: s.data[5],
+	}, nil
+}
+
+// Dimensions returns the image size.
+// It prefers PixelXDimension and PixelYDimension in Exif and falls back to SOF.
+func (h *JPEGHeader) Dimensions() (width, height int, ok bool) {
+	if h.APP1 != nil {
+		if width, height, ok := h.APP1.PixelDimensions(); ok {
+			return width, height, true
+		}
+	}
+	if h.SOF != nil {
+		return int(h.SOF.Width), int(h.SOF.Height), true
+	}
+	return 0, 0, false
+}
+
+var (
+	extendedXMPMarker = []byte("http://ns.adobe.com/xmp/extension/\x00")
+	iccProfileMarker  = []byte("ICC_PROFILE\x00")
+)
+
+const markerAPP2 = 0xe2
+
+// isMetadata returns true if the segment is Exif, XMP, extended XMP or ICC profile.
+func (s *segment) isMetadata() bool {
+	switch s.marker {
+	case markerAPP1:
+		return s.exif || isExif(s.data) || bytes.HasPrefix(s.data, xmpMarker) || bytes.HasPrefix(s.data, extendedXMPMarker)
+	case markerAPP2:
+		return bytes.HasPrefix(s.data, iccProfileMarker)
+	}
+	return false
+}
+
+// MetadataSize returns the total bytes of Exif, XMP and ICC profile segments
+// including their markers and lengths, i.e. how many bytes would be saved by stripping them.
+func (h *JPEGHeader) MetadataSize() int {
+	var size int
+	for _, s := range h.segments {
+		if s.isMetadata() {
+			size += 4 + len(s.data)
+		}
+	}
+	return size
+}
+
+// AppSegment represents an application segment (APP0-APP15).
+type AppSegment struct {
+	Marker byte
+	Data   []byte // without the marker and length
+}
+
+// Name returns the name of the marker such as "APP1".
+func (s AppSegment) Name() string {
+	return fmt.Sprintf("APP%d", s.Marker-0xe0)
+}
+
+// AppSegments returns the application segments in order of the file.
+// They may appear in any order before SOF, e.g. APP1 after APP0 or APP14.
+func (h *JPEGHeader) AppSegments() []AppSegment {
+	var segments []AppSegment
+	for _, s := range h.segments {
+		if s.marker >= 0xe0 && s.marker <= 0xef {
+			segments = append(segments, AppSegment{Marker: s.marker, Data: s.data})
+		}
+	}
+	return segments
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestJPEGHeader_Dimensions(t *testing.T) {
+	for _, name := range []string{"ii.jpg", "noexif.jpg"} {
+		t.Run(name, func(t *testing.T) {
+			h := decodeTestdata(t, name)
+			if w, ht, ok := h.Dimensions(); !ok || w != 2 || ht != 2 {
+				t.Errorf("Dimensions wants 2, 2 of SOF but %d, %d, %v", w, ht, ok)
+			}
+		})
+	}
+}
+
+func TestJPEGHeader_Dimensions_PixelDimensions(t *testing.T) {
+	h := decodeTestdata(t, "ii.jpg")
+	h.APP1.ExifIFD.Set(newLongElement(tagPixelXDimension, 6000, h.APP1.Endian))
+	h.APP1.ExifIFD.Set(newShortElement(tagPixelYDimension, 4000, h.APP1.Endian))
+	if w, ht, ok := h.APP1.PixelDimensions(); !ok || w != 6000 || ht != 4000 {
+		t.Errorf("PixelDimensions wants 6000, 4000 but %d, %d, %v", w, ht, ok)
+	}
+	if w, ht, ok := h.Dimensions(); !ok || w != 6000 || ht != 4000 {
+		t.Errorf("Dimensions wants 6000, 4000 of Exif but %d, %d, %v", w, ht, ok)
+	}
+}
+
+func TestJPEGHeader_Dimensions_None(t *testing.T) {
+	if _, _, ok := (&JPEGHeader{}).Dimensions(); ok {
+		t.Errorf("Dimensions wants false without Exif and SOF")
+	}
+}
+
+func TestJPEGHeader_MetadataSize(t *testing.T) {
+	if size := decodeTestdata(t, "noexif.jpg").MetadataSize(); size != 0 {
+		t.Errorf("MetadataSize without metadata wants 0 but %d", size)
+	}
+	// the APP1 segment of 0x3c7 bytes and the marker
+	if size := decodeTestdata(t, "ii.jpg").MetadataSize(); size != 969 {
+		t.Errorf("MetadataSize wants 969 but %d", size)
+	}
+	icc := append([]byte{0xff, markerAPP2, 0x00, 0x14}, "ICC_PROFILE\x00\x01\x01abcd"...)
+	other := append([]byte{0xff, markerAPP2, 0x00, 0x06}, "FPXR"...)
+	h, err := Decode(insertSegments(loadTestdata(t, "ii.jpg"), icc, other))
+	if err != nil {
+		t.Fatalf("Decode error: %s", err)
+	}
+	if size := h.MetadataSize(); size != 969+len(icc) {
+		t.Errorf("MetadataSize with ICC profile wants %d but %d", 969+len(icc), size)
+	}
+}
+
+func TestDecode_FillBytes(t *testing.T) {
+	b := loadTestdata(t, "ii.jpg")
+	want, err := Decode(b)
+	if err != nil {
+		t.Fatalf("Decode error: %s", err)
+	}
+	filled := append([]byte{0xff, 0xd8, 0xff, 0xff}, b[2:]...)
+	h, err := Decode(filled)
+	if err != nil {
+		t.Fatalf("Decode with fill bytes error: %s", err)
+	}
+	if v, ok := h.APP1.Model(); !ok || v != "Canon EOS 5D Mark III" {
+		t.Errorf("Model wants Canon EOS 5D Mark III but %q", v)
+	}
+	if h.ImageDataOffset != want.ImageDataOffset+2 {
+		t.Errorf("ImageDataOffset wants %d but %d", want.ImageDataOffset+2, h.ImageDataOffset)
+	}
+}
+
+func TestJPEGHeader_AppSegments(t *testing.T) {
+	xmp := append([]byte{0xff, markerAPP1, 0x00, byte(2 + len(xmpMarker) + 3)}, xmpMarker...)
+	xmp = append(xmp, "<x>"...)
+	h, err := Decode(insertSegments(loadTestdata(t, "noexif.jpg"), xmp))
+	if err != nil {
+		t.Fatalf("Decode error: %s", err)
+	}
+	segments := h.AppSegments()
+	if len(segments) != 2 || segments[0].Name() != "APP1" || segments[1].Name() != "APP0" {
+		t.Fatalf("AppSegments wants APP1 and APP0 in order but %+v", segments)
+	}
+	if !bytes.HasPrefix(segments[1].Data, []byte("JFIF\x00")) {
+		t.Errorf("Data of APP0 wants JFIF but %q", segments[1].Data)
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+// stdinFilename is the filename to read from stdin.
+const stdinFilename = "-"
+
+// readStdin reads a JPEG from the reader.
+// It accepts raw bytes, base64 or a data URI such as "data:image/jpeg;base64,...".
+func readStdin(r io.Reader) ([]byte, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("Could not read stdin: %s", err)
+	}
+	if bytes.HasPrefix(b, soiMarker) {
+		return b, nil
+	}
+	s := strings.TrimSpace(string(b))
+	if strings.HasPrefix(s, "data:") {
+		i := strings.Index(s, ",")
+		if i == -1 || !strings.HasSuffix(s[:i], ";base64") {
+			return nil, fmt.Errorf("Data URI must be base64 encoded")
+		}
+		s = s[i+1:]
+	}
+	s = strings.Join(strings.Fields(s), "")
+	d, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("Could not decode base64: %s", err)
+	}
+	return d, nil
+}
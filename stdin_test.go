@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestReadStdin(t *testing.T) {
+	jpeg := loadTestdata(t, "ii.jpg")
+	encoded := base64.StdEncoding.EncodeToString(jpeg)
+	for _, c := range []struct {
+		name  string
+		input string
+	}{
+		{"raw", string(jpeg)},
+		{"base64", encoded + "\n"},
+		{"wrapped base64", encoded[:76] + "\n" + encoded[76:] + "\n"},
+		{"data URI", "data:image/jpeg;base64," + encoded},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			b, err := readStdin(strings.NewReader(c.input))
+			if err != nil {
+				t.Fatalf("readStdin error: %s", err)
+			}
+			if !bytes.Equal(b, jpeg) {
+				t.Errorf("readStdin wants %d bytes but %d bytes", len(jpeg), len(b))
+			}
+		})
+	}
+}
+
+func TestReadStdin_Invalid(t *testing.T) {
+	for _, input := range []string{"data:image/jpeg,raw", "not base64!"} {
+		if _, err := readStdin(strings.NewReader(input)); err == nil {
+			t.Errorf("readStdin(%q) wants error", input)
+		}
+	}
+}
+
+func TestRun_Stdin(t *testing.T) {
+	stdin := strings.NewReader(base64.StdEncoding.EncodeToString(loadTestdata(t, "ii.jpg")))
+	var b bytes.Buffer
+	if err := run(stdin, &b, "count", false, []string{stdinFilename}); err != nil {
+		t.Fatalf("run error: %s", err)
+	}
+	if !strings.Contains(b.String(), "IFD0") {
+		t.Errorf("Summary wants IFD0 but %q", b.String())
+	}
+}
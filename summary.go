@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Summary returns the number of elements of each IFD and presence of the thumbnail,
+// e.g. "IFD0: 12 elements, Exif: 34, GPS: 8, Interop: absent, IFD1: present(thumbnail 5KB)".
+func (a *APP1) Summary() string {
+	var parts []string
+	for _, kind := range []IFDKind{IFD0Kind, ExifIFDKind, GPSIFDKind, InteroperabilityIFDKind} {
+		d := a.IFD(kind)
+		switch {
+		case d == nil:
+			parts = append(parts, fmt.Sprintf("%s: absent", kind))
+		case kind == IFD0Kind:
+			parts = append(parts, fmt.Sprintf("%s: %d elements", kind, len(d.Elements)))
+		default:
+			parts = append(parts, fmt.Sprintf("%s: %d", kind, len(d.Elements)))
+		}
+	}
+	switch {
+	case a.IFD1 == nil:
+		parts = append(parts, fmt.Sprintf("%s: absent", IFD1Kind))
+	case a.thumbnail != nil:
+		parts = append(parts, fmt.Sprintf("%s: present(thumbnail %s)", IFD1Kind, formatSize(len(a.thumbnail))))
+	default:
+		parts = append(parts, fmt.Sprintf("%s: present", IFD1Kind))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatSize returns the size in bytes or KB.
+func formatSize(n int) string {
+	if n < 1024 {
+		return fmt.Sprintf("%dB", n)
+	}
+	return fmt.Sprintf("%dKB", (n+512)/1024)
+}
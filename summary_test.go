@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestAPP1_Summary(t *testing.T) {
+	for _, c := range []struct {
+		name string
+		want string
+	}{
+		{"ii.jpg", "IFD0: 9 elements, Exif: 11, GPS: 7, Interop: 2, IFD1: present(thumbnail 331B)"},
+		{"nothumb.jpg", "IFD0: 8 elements, Exif: 11, GPS: absent, Interop: 2, IFD1: absent"},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			if s := decodeTestdata(t, c.name).APP1.Summary(); s != c.want {
+				t.Errorf("Summary wants %q but %q", c.want, s)
+			}
+		})
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	for _, c := range []struct {
+		n    int
+		want string
+	}{
+		{1023, "1023B"},
+		{1024, "1KB"},
+		{5 * 1024, "5KB"},
+	} {
+		if s := formatSize(c.n); s != c.want {
+			t.Errorf("formatSize(%d) wants %s but %s", c.n, c.want, s)
+		}
+	}
+}
+
+func TestRun_Count(t *testing.T) {
+	var b bytes.Buffer
+	if err := run(nil, &b, "count", false, []string{"testdata/ii.jpg", "testdata/noexif.jpg"}); err != nil {
+		t.Fatalf("run error: %s", err)
+	}
+	want := "testdata/ii.jpg: IFD0: 9 elements, Exif: 11, GPS: 7, Interop: 2, IFD1: present(thumbnail 331B)\n" +
+		"testdata/noexif.jpg: Exif: absent\n"
+	if b.String() != want {
+		t.Errorf("run wants %q but %q", want, b.String())
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+const (
+	tagOECF                           = 0x8828
+	tagTIFFEPSpatialFrequencyResponse = 0x920C
+	tagSpatialFrequencyResponse       = 0xA20C
+)
+
+// MeasurementTable represents the table of OECF or SpatialFrequencyResponse.
+// Values are in rows, and each row has the values of the columns.
+type MeasurementTable struct {
+	Columns int
+	Rows    int
+	Names   []string
+	Values  [][]float64
+}
+
+// OECF returns the opto-electric conversion function table in the Exif IFD.
+// The raw bytes are returned even if the table could not be decoded, in which case the table is nil.
+func (a *APP1) OECF() (table *MeasurementTable, raw []byte, ok bool) {
+	return findMeasurementTable(a.ExifIFD, tagOECF, true, a.Endian)
+}
+
+// SpatialFrequencyResponse returns the spatial frequency table in the Exif IFD.
+// It falls back to the tag of TIFF/EP (0x920C) if the tag of Exif (0xA20C) is not present.
+// The raw bytes are returned even if the table could not be decoded, in which case the table is nil.
+func (a *APP1) SpatialFrequencyResponse() (table *MeasurementTable, raw []byte, ok bool) {
+	for _, tag := range []uint16{tagSpatialFrequencyResponse, tagTIFFEPSpatialFrequencyResponse} {
+		if table, raw, ok := findMeasurementTable(a.ExifIFD, tag, false, a.Endian); ok {
+			return table, raw, true
+		}
+	}
+	return nil, nil, false
+}
+
+func findMeasurementTable(d *IFD, tag uint16, signed bool, endian binary.ByteOrder) (*MeasurementTable, []byte, bool) {
+	e := d.Find(tag)
+	if e == nil || e.checkLength() != nil {
+		return nil, nil, false
+	}
+	raw := e.Value[:e.Length()]
+	table, err := parseMeasurementTable(raw, signed, endian)
+	if err != nil {
+		return nil, raw, true
+	}
+	return table, raw, true
+}
+
+// parseMeasurementTable parses the number of columns and rows, the column names and the values.
+// The values are SRATIONAL if signed, otherwise RATIONAL.
+func parseMeasurementTable(b []byte, signed bool, endian binary.ByteOrder) (*MeasurementTable, error) {
+	c, err := newCursor(b, 0, endian)
+	if err != nil {
+		return nil, err
+	}
+	columns, err := c.ReadUint16()
+	if err != nil {
+		return nil, fmt.Errorf("Could not read columns: %s", err)
+	}
+	rows, err := c.ReadUint16()
+	if err != nil {
+		return nil, fmt.Errorf("Could not read rows: %s", err)
+	}
+	t := &MeasurementTable{Columns: int(columns), Rows: int(rows)}
+	for i := 0; i < t.Columns; i++ {
+		rest := b[c.Offset():]
+		end := bytes.IndexByte(rest, 0)
+		if end == -1 {
+			return nil, fmt.Errorf("Could not find the end of column name #%d", i)
+		}
+		name, err := c.ReadBytes(end + 1)
+		if err != nil {
+			return nil, err
+		}
+		t.Names = append(t.Names, string(name[:end]))
+	}
+	// computed in int64 since 8 x 65535 x 65535 overflows int on 32-bit platforms
+	if need, remaining := 8*int64(t.Columns)*int64(t.Rows), int64(len(b)-c.Offset()); need > remaining {
+		return nil, fmt.Errorf("Values expect %d bytes but got %d bytes", need, remaining)
+	}
+	for i := 0; i < t.Rows; i++ {
+		row := make([]float64, t.Columns)
+		for j := range row {
+			n, err := c.ReadUint32()
+			if err != nil {
+				return nil, err
+			}
+			d, err := c.ReadUint32()
+			if err != nil {
+				return nil, err
+			}
+			if signed {
+				row[j] = SRational{int32(n), int32(d)}.Float64()
+			} else {
+				row[j] = Rational{n, d}.Float64()
+			}
+		}
+		t.Values = append(t.Values, row)
+	}
+	return t, nil
+}
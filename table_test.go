@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+// measurementTable returns the bytes of the table of 2 columns and 2 rows.
+func measurementTable(endian binary.ByteOrder) []byte {
+	var b bytes.Buffer
+	binary.Write(&b, endian, uint16(2))
+	binary.Write(&b, endian, uint16(2))
+	b.WriteString("ISO\x00Exposure\x00")
+	for _, v := range []int32{100, 1, -1, 2, 200, 1, 3, 4} {
+		binary.Write(&b, endian, v)
+	}
+	return b.Bytes()
+}
+
+func TestAPP1_OECF(t *testing.T) {
+	a := newTestAPP1(nil)
+	if _, _, ok := a.OECF(); ok {
+		t.Errorf("OECF wants false if not present")
+	}
+	a.SetElement(ExifIFDKind, newUndefinedElement(tagOECF, measurementTable(a.Endian)))
+	table, raw, ok := a.OECF()
+	if !ok || table == nil {
+		t.Fatalf("OECF wants the table but %+v, %v", table, ok)
+	}
+	if len(raw) != len(measurementTable(a.Endian)) {
+		t.Errorf("raw wants %d bytes but %d bytes", len(measurementTable(a.Endian)), len(raw))
+	}
+	want := &MeasurementTable{
+		Columns: 2,
+		Rows:    2,
+		Names:   []string{"ISO", "Exposure"},
+		Values:  [][]float64{{100, -0.5}, {200, 0.75}},
+	}
+	if !reflect.DeepEqual(table, want) {
+		t.Errorf("OECF wants %+v but %+v", want, table)
+	}
+}
+
+func TestAPP1_SpatialFrequencyResponse_Malformed(t *testing.T) {
+	a := newTestAPP1(nil)
+	b := measurementTable(a.Endian)
+	a.SetElement(ExifIFDKind, newUndefinedElement(tagSpatialFrequencyResponse, b[:len(b)-1]))
+	table, raw, ok := a.SpatialFrequencyResponse()
+	if !ok || table != nil || len(raw) != len(b)-1 {
+		t.Errorf("SpatialFrequencyResponse wants the raw bytes only but %+v, %d bytes, %v", table, len(raw), ok)
+	}
+}
+
+func TestAPP1_SpatialFrequencyResponse_TIFFEP(t *testing.T) {
+	a := newTestAPP1(nil)
+	if _, _, ok := a.SpatialFrequencyResponse(); ok {
+		t.Errorf("SpatialFrequencyResponse wants false if not present")
+	}
+	a.SetElement(ExifIFDKind, newUndefinedElement(tagTIFFEPSpatialFrequencyResponse, measurementTable(a.Endian)))
+	table, _, ok := a.SpatialFrequencyResponse()
+	if !ok || table == nil || table.Names[0] != "ISO" {
+		t.Fatalf("SpatialFrequencyResponse of TIFF/EP wants the table but %+v, %v", table, ok)
+	}
+	b := measurementTable(a.Endian)
+	a.SetElement(ExifIFDKind, newUndefinedElement(tagSpatialFrequencyResponse, b[:len(b)-1]))
+	if table, raw, ok := a.SpatialFrequencyResponse(); !ok || table != nil || len(raw) != len(b)-1 {
+		t.Errorf("SpatialFrequencyResponse wants the tag of Exif first but %+v, %d bytes, %v", table, len(raw), ok)
+	}
+}
+
+func TestParseMeasurementTable_Unsigned(t *testing.T) {
+	table, err := parseMeasurementTable(measurementTable(binary.BigEndian), false, binary.BigEndian)
+	if err != nil {
+		t.Fatalf("parseMeasurementTable error: %s", err)
+	}
+	// -1 is 0xffffffff in RATIONAL
+	if v := table.Values[0][1]; v != float64(0xffffffff)/2 {
+		t.Errorf("Values wants unsigned but %f", v)
+	}
+	if _, err := parseMeasurementTable([]byte{0, 1, 0, 1, 'I', 'S', 'O'}, false, binary.BigEndian); err == nil {
+		t.Errorf("parseMeasurementTable without the end of name wants error")
+	}
+}
+
+func TestParseMeasurementTable_Overflow(t *testing.T) {
+	// 8 x 65535 x 65535 bytes of values overflows on 32-bit platforms
+	var b bytes.Buffer
+	binary.Write(&b, binary.BigEndian, uint16(65535))
+	binary.Write(&b, binary.BigEndian, uint16(65535))
+	b.Write(bytes.Repeat([]byte{0}, 65535))
+	if _, err := parseMeasurementTable(b.Bytes(), false, binary.BigEndian); err == nil {
+		t.Errorf("parseMeasurementTable of 65535x65535 wants error")
+	}
+}
@@ -0,0 +1,319 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// IFDKind represents which IFD an element belongs to.
+type IFDKind int
+
+const (
+	IFD0Kind IFDKind = iota
+	ExifIFDKind
+	GPSIFDKind
+	InteroperabilityIFDKind
+	IFD1Kind
+)
+
+var ifdKindNames = map[IFDKind]string{
+	IFD0Kind:                "IFD0",
+	ExifIFDKind:             "Exif",
+	GPSIFDKind:              "GPS",
+	InteroperabilityIFDKind: "Interop",
+	IFD1Kind:                "IFD1",
+}
+
+func (k IFDKind) String() string {
+	if s, ok := ifdKindNames[k]; ok {
+		return s
+	}
+	return fmt.Sprintf("IFDKind(%d)", int(k))
+}
+
+// IFD returns the IFD of the kind, or nil if not present.
+func (a *APP1) IFD(kind IFDKind) *IFD {
+	switch kind {
+	case IFD0Kind:
+		return a.IFD0
+	case ExifIFDKind:
+		return a.ExifIFD
+	case GPSIFDKind:
+		return a.GPSIFD
+	case InteroperabilityIFDKind:
+		return a.InteroperabilityIFD
+	case IFD1Kind:
+		return a.IFD1
+	}
+	return nil
+}
+
+// Walk calls fn for each element in order of IFD0, Exif, GPS, Interop and IFD1.
+// It does nothing if the APP1 is nil.
+func (a *APP1) Walk(fn func(kind IFDKind, e *IFDElement)) {
+	if a == nil {
+		return
+	}
+	for _, kind := range []IFDKind{IFD0Kind, ExifIFDKind, GPSIFDKind, InteroperabilityIFDKind, IFD1Kind} {
+		d := a.IFD(kind)
+		if d == nil {
+			continue
+		}
+		for _, e := range d.Elements {
+			fn(kind, e)
+		}
+	}
+}
+
+type tagDef struct {
+	name string
+	typ  IFDElementType
+}
+
+// tiffTags are tags in IFD0 and IFD1.
+var tiffTags = map[uint16]tagDef{
+	0x00FE: {"NewSubfileType", 4},
+	0x0100: {"ImageWidth", 4},
+	0x0101: {"ImageLength", 4},
+	0x0102: {"BitsPerSample", 3},
+	0x0103: {"Compression", 3},
+	0x0106: {"PhotometricInterpretation", 3},
+	0x010E: {"ImageDescription", 2},
+	0x010F: {"Make", 2},
+	0x0110: {"Model", 2},
+	0x0111: {"StripOffsets", 4},
+	0x0112: {"Orientation", 3},
+	0x0115: {"SamplesPerPixel", 3},
+	0x0116: {"RowsPerStrip", 4},
+	0x0117: {"StripByteCounts", 4},
+	0x011A: {"XResolution", 5},
+	0x011B: {"YResolution", 5},
+	0x011C: {"PlanarConfiguration", 3},
+	0x0128: {"ResolutionUnit", 3},
+	0x012D: {"TransferFunction", 3},
+	0x0131: {"Software", 2},
+	0x0132: {"DateTime", 2},
+	0x013B: {"Artist", 2},
+	0x013C: {"HostComputer", 2},
+	0x013E: {"WhitePoint", 5},
+	0x013F: {"PrimaryChromaticities", 5},
+	0x014A: {"SubIFDs", 4},
+	0x0152: {"ExtraSamples", 3},
+	0x0201: {"JPEGInterchangeFormat", 4},
+	0x0202: {"JPEGInterchangeFormatLength", 4},
+	0x0211: {"YCbCrCoefficients", 5},
+	0x0212: {"YCbCrSubSampling", 3},
+	0x0213: {"YCbCrPositioning", 3},
+	0x0214: {"ReferenceBlackWhite", 5},
+	0x4746: {"Rating", 3},
+	0x4749: {"RatingPercent", 3},
+	0x8298: {"Copyright", 2},
+	0x8769: {"ExifIFDPointer", 4},
+	0x8825: {"GPSInfoIFDPointer", 4},
+	0xC612: {"DNGVersion", 1},
+	0xC613: {"DNGBackwardVersion", 1},
+	0xC614: {"UniqueCameraModel", 2},
+	0xC618: {"LinearizationTable", 3},
+	0xC740: {"OpcodeList1", 7},
+	0xC741: {"OpcodeList2", 7},
+	0xC74E: {"OpcodeList3", 7},
+	0xEA1C: {"Padding", 7},
+}
+
+var exifTags = map[uint16]tagDef{
+	0x829A: {"ExposureTime", 5},
+	0x829D: {"FNumber", 5},
+	0x8822: {"ExposureProgram", 3},
+	0x8824: {"SpectralSensitivity", 2},
+	0x8827: {"PhotographicSensitivity", 3},
+	0x8828: {"OECF", 7},
+	0x8830: {"SensitivityType", 3},
+	0x8831: {"StandardOutputSensitivity", 4},
+	0x8832: {"RecommendedExposureIndex", 4},
+	0x8833: {"ISOSpeed", 4},
+	0x8834: {"ISOSpeedLatitudeyyy", 4},
+	0x8835: {"ISOSpeedLatitudezzz", 4},
+	0x9000: {"ExifVersion", 7},
+	0x9003: {"DateTimeOriginal", 2},
+	0x9004: {"DateTimeDigitized", 2},
+	0x9010: {"OffsetTime", 2},
+	0x9011: {"OffsetTimeOriginal", 2},
+	0x9012: {"OffsetTimeDigitized", 2},
+	0x9101: {"ComponentsConfiguration", 7},
+	0x9102: {"CompressedBitsPerPixel", 5},
+	0x9201: {"ShutterSpeedValue", 10},
+	0x9202: {"ApertureValue", 5},
+	0x9203: {"BrightnessValue", 10},
+	0x9204: {"ExposureBiasValue", 10},
+	0x9205: {"MaxApertureValue", 5},
+	0x9206: {"SubjectDistance", 5},
+	0x9207: {"MeteringMode", 3},
+	0x9208: {"LightSource", 3},
+	0x9209: {"Flash", 3},
+	0x920A: {"FocalLength", 5},
+	0x9214: {"SubjectArea", 3},
+	0x927C: {"MakerNote", 7},
+	0x9286: {"UserComment", 7},
+	0x9290: {"SubSecTime", 2},
+	0x9291: {"SubSecTimeOriginal", 2},
+	0x9292: {"SubSecTimeDigitized", 2},
+	0x9400: {"Temperature", 10},
+	0x9401: {"Humidity", 5},
+	0x9402: {"Pressure", 5},
+	0x9403: {"WaterDepth", 10},
+	0x9404: {"Acceleration", 5},
+	0x9405: {"CameraElevationAngle", 10},
+	0xA000: {"FlashpixVersion", 7},
+	0xA001: {"ColorSpace", 3},
+	0xA002: {"PixelXDimension", 4},
+	0xA003: {"PixelYDimension", 4},
+	0xA004: {"RelatedSoundFile", 2},
+	0xA005: {"InteroperabilityIFDPointer", 4},
+	0xA20B: {"FlashEnergy", 5},
+	0xA20C: {"SpatialFrequencyResponse", 7},
+	0xA20E: {"FocalPlaneXResolution", 5},
+	0xA20F: {"FocalPlaneYResolution", 5},
+	0xA210: {"FocalPlaneResolutionUnit", 3},
+	0xA214: {"SubjectLocation", 3},
+	0xA215: {"ExposureIndex", 5},
+	0xA217: {"SensingMethod", 3},
+	0xA300: {"FileSource", 7},
+	0xA301: {"SceneType", 7},
+	0xA302: {"CFAPattern", 7},
+	0xA401: {"CustomRendered", 3},
+	0xA402: {"ExposureMode", 3},
+	0xA403: {"WhiteBalance", 3},
+	0xA404: {"DigitalZoomRatio", 5},
+	0xA405: {"FocalLengthIn35mmFilm", 3},
+	0xA406: {"SceneCaptureType", 3},
+	0xA407: {"GainControl", 3},
+	0xA408: {"Contrast", 3},
+	0xA409: {"Saturation", 3},
+	0xA40A: {"Sharpness", 3},
+	0xA40B: {"DeviceSettingDescription", 7},
+	0xA40C: {"SubjectDistanceRange", 3},
+	0xA420: {"ImageUniqueID", 2},
+	0xA430: {"CameraOwnerName", 2},
+	0xA431: {"BodySerialNumber", 2},
+	0xA432: {"LensSpecification", 5},
+	0xA433: {"LensMake", 2},
+	0xA434: {"LensModel", 2},
+	0xA435: {"LensSerialNumber", 2},
+	0xA460: {"CompositeImage", 3},
+	0xA461: {"SourceImageNumberOfCompositeImage", 3},
+	0xA462: {"SourceExposureTimesOfCompositeImage", 7},
+	0xA500: {"Gamma", 5},
+	0xEA1C: {"Padding", 7},
+	0xEA1D: {"OffsetSchema", 9},
+}
+
+var gpsTags = map[uint16]tagDef{
+	0x0000: {"GPSVersionID", 1},
+	0x0001: {"GPSLatitudeRef", 2},
+	0x0002: {"GPSLatitude", 5},
+	0x0003: {"GPSLongitudeRef", 2},
+	0x0004: {"GPSLongitude", 5},
+	0x0005: {"GPSAltitudeRef", 1},
+	0x0006: {"GPSAltitude", 5},
+	0x0007: {"GPSTimeStamp", 5},
+	0x0008: {"GPSSatellites", 2},
+	0x0009: {"GPSStatus", 2},
+	0x000A: {"GPSMeasureMode", 2},
+	0x000B: {"GPSDOP", 5},
+	0x000C: {"GPSSpeedRef", 2},
+	0x000D: {"GPSSpeed", 5},
+	0x000E: {"GPSTrackRef", 2},
+	0x000F: {"GPSTrack", 5},
+	0x0010: {"GPSImgDirectionRef", 2},
+	0x0011: {"GPSImgDirection", 5},
+	0x0012: {"GPSMapDatum", 2},
+	0x0013: {"GPSDestLatitudeRef", 2},
+	0x0014: {"GPSDestLatitude", 5},
+	0x0015: {"GPSDestLongitudeRef", 2},
+	0x0016: {"GPSDestLongitude", 5},
+	0x0017: {"GPSDestBearingRef", 2},
+	0x0018: {"GPSDestBearing", 5},
+	0x0019: {"GPSDestDistanceRef", 2},
+	0x001A: {"GPSDestDistance", 5},
+	0x001B: {"GPSProcessingMethod", 7},
+	0x001C: {"GPSAreaInformation", 7},
+	0x001D: {"GPSDateStamp", 2},
+	0x001E: {"GPSDifferential", 3},
+	0x001F: {"GPSHPositioningError", 5},
+}
+
+var interoperabilityTags = map[uint16]tagDef{
+	0x0001: {"InteroperabilityIndex", 2},
+	0x0002: {"InteroperabilityVersion", 7},
+}
+
+var tagDefs = map[IFDKind]map[uint16]tagDef{
+	IFD0Kind:                tiffTags,
+	ExifIFDKind:             exifTags,
+	GPSIFDKind:              gpsTags,
+	InteroperabilityIFDKind: interoperabilityTags,
+	IFD1Kind:                tiffTags,
+}
+
+// tagIDs is the index of the tag ids by name for each IFD, rebuilt by RegisterTag.
+var tagIDs = buildTagIDs()
+
+// buildTagIDs returns the index of tagDefs by name.
+// The smallest id wins if a name appears more than once in an IFD.
+func buildTagIDs() map[IFDKind]map[string]uint16 {
+	index := make(map[IFDKind]map[string]uint16)
+	for kind, defs := range tagDefs {
+		index[kind] = make(map[string]uint16)
+		for id, def := range defs {
+			if existing, ok := index[kind][def.name]; !ok || id < existing {
+				index[kind][def.name] = id
+			}
+		}
+	}
+	return index
+}
+
+// TagName returns the name of the tag in the IFD.
+func TagName(kind IFDKind, id uint16) (string, bool) {
+	def, ok := tagDefs[kind][id]
+	return def.name, ok
+}
+
+// RegisterTag adds or replaces the tag in the tag table,
+// which is used by TagName, KnownTags and lookup by name.
+// 0th IFD and 1st IFD share the table of TIFF tags.
+// It is not safe for concurrent use, so call it in init.
+func RegisterTag(kind IFDKind, id uint16, name string, typ IFDElementType) error {
+	defs, ok := tagDefs[kind]
+	if !ok {
+		return fmt.Errorf("Unknown IFD kind: %s", kind)
+	}
+	defs[id] = tagDef{name, typ}
+	tagIDs = buildTagIDs()
+	return nil
+}
+
+// TagInfo describes a tag known by the package.
+type TagInfo struct {
+	IFD  IFDKind
+	ID   uint16
+	Name string
+	Type IFDElementType
+}
+
+// KnownTags returns all known tags sorted by IFD and ID.
+func KnownTags() []TagInfo {
+	var tags []TagInfo
+	for kind, defs := range tagDefs {
+		for id, def := range defs {
+			tags = append(tags, TagInfo{IFD: kind, ID: id, Name: def.name, Type: def.typ})
+		}
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].IFD != tags[j].IFD {
+			return tags[i].IFD < tags[j].IFD
+		}
+		return tags[i].ID < tags[j].ID
+	})
+	return tags
+}
@@ -0,0 +1,69 @@
+package main
+
+import "testing"
+
+func TestKnownTags(t *testing.T) {
+	tags := KnownTags()
+	if len(tags) == 0 {
+		t.Fatalf("KnownTags wants non-empty")
+	}
+	for i := 1; i < len(tags); i++ {
+		p, c := tags[i-1], tags[i]
+		if p.IFD > c.IFD || p.IFD == c.IFD && p.ID >= c.ID {
+			t.Errorf("KnownTags wants sorted by IFD and ID but %v before %v", p, c)
+		}
+	}
+	var found bool
+	for _, tag := range tags {
+		if name, ok := TagName(tag.IFD, tag.ID); !ok || name != tag.Name {
+			t.Errorf("TagName(%s, 0x%04X) wants %s but %s", tag.IFD, tag.ID, tag.Name, name)
+		}
+		found = found || tag == TagInfo{IFD: IFD0Kind, ID: tagMake, Name: "Make", Type: 2}
+	}
+	if !found {
+		t.Errorf("KnownTags wants Make of IFD0")
+	}
+}
+
+func TestTagName_Padding(t *testing.T) {
+	for _, c := range []struct {
+		kind IFDKind
+		id   uint16
+		want string
+	}{
+		{IFD0Kind, tagPadding, "Padding"},
+		{ExifIFDKind, tagPadding, "Padding"},
+		{ExifIFDKind, 0xEA1D, "OffsetSchema"},
+	} {
+		if name, ok := TagName(c.kind, c.id); !ok || name != c.want {
+			t.Errorf("TagName(%s, 0x%04X) wants %s but %s", c.kind, c.id, c.want, name)
+		}
+	}
+}
+
+func TestRegisterTag(t *testing.T) {
+	const id = 0xFEDC
+	defer func() {
+		delete(tagDefs[ExifIFDKind], id)
+		tagIDs = buildTagIDs()
+	}()
+	if _, ok := TagName(ExifIFDKind, id); ok {
+		t.Fatalf("TagName wants false before RegisterTag")
+	}
+	if err := RegisterTag(ExifIFDKind, id, "VendorTag", 3); err != nil {
+		t.Fatalf("RegisterTag error: %s", err)
+	}
+	if name, ok := TagName(ExifIFDKind, id); !ok || name != "VendorTag" {
+		t.Errorf("TagName wants VendorTag but %s, %v", name, ok)
+	}
+	var found bool
+	for _, tag := range KnownTags() {
+		found = found || tag == TagInfo{IFD: ExifIFDKind, ID: id, Name: "VendorTag", Type: 3}
+	}
+	if !found {
+		t.Errorf("KnownTags wants the registered tag")
+	}
+	if err := RegisterTag(IFDKind(99), id, "VendorTag", 3); err == nil {
+		t.Errorf("RegisterTag of unknown kind wants error")
+	}
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// loadTestdata reads the sample file in the testdata directory.
+func loadTestdata(t testing.TB, name string) []byte {
+	t.Helper()
+	b, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("Could not read testdata: %s", err)
+	}
+	return b
+}
+
+// decodeTestdata decodes the sample file in the testdata directory.
+func decodeTestdata(t testing.TB, name string) *JPEGHeader {
+	t.Helper()
+	h, err := Decode(loadTestdata(t, name))
+	if err != nil {
+		t.Fatalf("Could not decode %s: %s", name, err)
+	}
+	return h
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// DecodeThumbnail extracts the JPEG thumbnail in the 1st IFD from the reader.
+// It reads the segments until the Exif APP1, and parses only the 1st IFD,
+// skipping the elements of the 0th IFD and the Exif, GPS and Interoperability IFDs.
+func DecodeThumbnail(r io.Reader) ([]byte, error) {
+	read := limitRead(readBytes, defaultMaxSegmentSize)
+	b, err := read(r, 2)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(b, soiMarker) {
+		return nil, fmt.Errorf("SOI not found")
+	}
+	for {
+		s, err := parseSegment(r, read)
+		if err != nil {
+			return nil, fmt.Errorf("Could not parse segment: %s", err)
+		}
+		if isSOFMarker(s.marker) || s.marker == markerSOS || s.marker == markerEOI {
+			return nil, fmt.Errorf("Exif not found")
+		}
+		if s.marker == markerAPP1 && isExif(s.data) {
+			offset, _, _ := exifTIFFOffset(s.data)
+			return findThumbnailInTIFF(s.data[offset:])
+		}
+	}
+}
+
+// findThumbnailInTIFF finds the 1st IFD by the next IFD offset of the 0th IFD and returns the thumbnail.
+func findThumbnailInTIFF(b []byte) ([]byte, error) {
+	endian, ifd0Offset, err := parseTIFFHeader(b)
+	if err != nil {
+		return nil, fmt.Errorf("Could not parse TIFF header: %s", err)
+	}
+	c, err := newCursor(b, int(ifd0Offset), endian)
+	if err != nil {
+		return nil, fmt.Errorf("Could not read 0th IFD: %s", err)
+	}
+	count, err := c.ReadUint16()
+	if err != nil {
+		return nil, fmt.Errorf("Could not read element count of 0th IFD: %s", err)
+	}
+	if _, err := c.ReadBytes(12 * int(count)); err != nil {
+		return nil, fmt.Errorf("Could not read elements of 0th IFD: %s", err)
+	}
+	ifd1Offset, err := c.ReadUint32()
+	if err != nil {
+		return nil, fmt.Errorf("Could not read next IFD offset of 0th IFD: %s", err)
+	}
+	if ifd1Offset == 0 {
+		return nil, fmt.Errorf("1st IFD not found")
+	}
+	ifd1, err := parseIFD(b, 0, ifd1Offset, endian)
+	if err != nil {
+		return nil, fmt.Errorf("Could not parse 1st IFD: %s", err)
+	}
+	thumbnail, err := ifd1.findThumbnail(b, endian)
+	if err != nil {
+		return nil, err
+	}
+	if thumbnail == nil {
+		return nil, fmt.Errorf("Thumbnail not found")
+	}
+	return thumbnail, nil
+}
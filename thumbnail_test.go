@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDecodeThumbnail(t *testing.T) {
+	for _, name := range []string{"ii.jpg", "mm.jpg"} {
+		t.Run(name, func(t *testing.T) {
+			thumbnail, err := DecodeThumbnail(bytes.NewReader(loadTestdata(t, name)))
+			if err != nil {
+				t.Fatalf("DecodeThumbnail error: %s", err)
+			}
+			if want := decodeTestdata(t, name).APP1.Thumbnail(); !bytes.Equal(thumbnail, want) {
+				t.Errorf("DecodeThumbnail wants %d bytes but %d bytes", len(want), len(thumbnail))
+			}
+		})
+	}
+}
+
+func TestDecodeThumbnail_NotFound(t *testing.T) {
+	for _, name := range []string{"nothumb.jpg", "noexif.jpg"} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := DecodeThumbnail(bytes.NewReader(loadTestdata(t, name))); err == nil {
+				t.Errorf("DecodeThumbnail wants error")
+			}
+		})
+	}
+}
+
+func BenchmarkDecodeThumbnail(b *testing.B) {
+	data := loadTestdata(b, "ii.jpg")
+	b.Run("DecodeThumbnail", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := DecodeThumbnail(bytes.NewReader(data)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Decode", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			h, err := Decode(data)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if h.APP1.Thumbnail() == nil {
+				b.Fatal("Thumbnail not found")
+			}
+		}
+	})
+}
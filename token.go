@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// TokenKind represents the kind of a parse event.
+type TokenKind int
+
+const (
+	SegmentStartToken TokenKind = iota
+	IFDStartToken
+	ElementToken
+	IFDEndToken
+)
+
+var tokenKindNames = map[TokenKind]string{
+	SegmentStartToken: "SegmentStart",
+	IFDStartToken:     "IFDStart",
+	ElementToken:      "Element",
+	IFDEndToken:       "IFDEnd",
+}
+
+func (k TokenKind) String() string {
+	if s, ok := tokenKindNames[k]; ok {
+		return s
+	}
+	return fmt.Sprintf("TokenKind(%d)", int(k))
+}
+
+// Token is a parse event.
+// Marker is set for SegmentStart, IFD is set for IFDStart, Element and IFDEnd,
+// and Element is set for Element.
+type Token struct {
+	Kind    TokenKind
+	Marker  byte
+	IFD     IFDKind
+	Element *IFDElement
+}
+
+// pendingIFD is an IFD found by a link but not read yet.
+type pendingIFD struct {
+	kind   IFDKind
+	offset uint32
+}
+
+// Tokenizer reads the JPEG header as a stream of tokens without building the tree.
+// Only the current segment is kept in memory.
+type Tokenizer struct {
+	r       io.Reader
+	read    readFunc
+	started bool
+	done    bool
+
+	tiff    []byte
+	endian  binary.ByteOrder
+	pending []pendingIFD
+	visited map[uint32]bool
+
+	ifd       *cursor
+	kind      IFDKind
+	remaining int
+}
+
+// NewTokenizer returns a Tokenizer which reads from the reader.
+func NewTokenizer(r io.Reader) *Tokenizer {
+	return &Tokenizer{r: r, read: limitRead(readBytes, defaultMaxSegmentSize)}
+}
+
+// Token returns the next token.
+// It returns io.EOF after SOF, SOS or EOI.
+// The elements of the Exif APP1 follow its SegmentStart.
+func (t *Tokenizer) Token() (Token, error) {
+	if !t.started {
+		t.started = true
+		b, err := t.read(t.r, 2)
+		if err != nil {
+			return Token{}, err
+		}
+		if bytes.Compare(b, soiMarker) != 0 {
+			return Token{}, fmt.Errorf("SOI not found")
+		}
+	}
+	if t.ifd != nil {
+		return t.nextElement()
+	}
+	if len(t.pending) > 0 {
+		return t.startIFD()
+	}
+	if t.done {
+		return Token{}, io.EOF
+	}
+	s, err := parseSegment(t.r, t.read)
+	if err != nil {
+		return Token{}, fmt.Errorf("Could not parse segment: %s", err)
+	}
+	switch {
+	case s.marker == markerAPP1 && t.tiff == nil && isExif(s.data):
+		tiffOffset, _, _ := exifTIFFOffset(s.data)
+		t.tiff = s.data[tiffOffset:]
+		endian, offset, err := parseTIFFHeader(t.tiff)
+		if err != nil {
+			return Token{}, fmt.Errorf("Could not parse TIFF header: %s", err)
+		}
+		t.endian = endian
+		t.visited = map[uint32]bool{}
+		t.pending = append(t.pending, pendingIFD{IFD0Kind, offset})
+	case isSOFMarker(s.marker), s.marker == markerSOS, s.marker == markerEOI:
+		t.done = true
+	}
+	return Token{Kind: SegmentStartToken, Marker: s.marker}, nil
+}
+
+func (t *Tokenizer) startIFD() (Token, error) {
+	p := t.pending[0]
+	t.pending = t.pending[1:]
+	if t.visited[p.offset] {
+		return Token{}, fmt.Errorf("%s IFD at 0x%x is already visited", p.kind, p.offset)
+	}
+	t.visited[p.offset] = true
+	c, err := newCursor(t.tiff, int(p.offset), t.endian)
+	if err != nil {
+		return Token{}, fmt.Errorf("Could not read %s IFD: %s", p.kind, err)
+	}
+	count, err := c.ReadUint16()
+	if err != nil {
+		return Token{}, fmt.Errorf("Could not read element count of %s IFD: %s", p.kind, err)
+	}
+	if need, remaining := 12*int(count)+4, len(t.tiff)-c.Offset(); need > remaining {
+		return Token{}, fmt.Errorf("%s IFD has %d elements which need %d bytes but only %d bytes remain", p.kind, count, need, remaining)
+	}
+	t.ifd, t.kind, t.remaining = c, p.kind, int(count)
+	return Token{Kind: IFDStartToken, IFD: p.kind}, nil
+}
+
+func (t *Tokenizer) nextElement() (Token, error) {
+	if t.remaining == 0 {
+		next, err := t.ifd.ReadUint32()
+		if err != nil {
+			return Token{}, fmt.Errorf("Could not read next IFD offset of %s IFD: %s", t.kind, err)
+		}
+		if t.kind == IFD0Kind && next != 0 {
+			t.pending = append(t.pending, pendingIFD{IFD1Kind, next})
+		}
+		kind := t.kind
+		t.ifd = nil
+		return Token{Kind: IFDEndToken, IFD: kind}, nil
+	}
+	t.remaining--
+	b, err := t.ifd.ReadBytes(12)
+	if err != nil {
+		return Token{}, fmt.Errorf("Could not read element of %s IFD: %s", t.kind, err)
+	}
+	e, err := parseIFDElement(b, t.tiff, 0, t.endian)
+	if err != nil {
+		return Token{}, fmt.Errorf("Could not parse element of %s IFD: %s", t.kind, err)
+	}
+	switch {
+	case t.kind == IFD0Kind && e.Tag == tagExifIFDPointer:
+		t.pending = append(t.pending, pendingIFD{ExifIFDKind, e.Uint32(t.endian)})
+	case t.kind == IFD0Kind && e.Tag == tagGPSInfoIFDPointer:
+		t.pending = append(t.pending, pendingIFD{GPSIFDKind, e.Uint32(t.endian)})
+	case t.kind == ExifIFDKind && e.Tag == tagInteroperabilityIFDPointer:
+		t.pending = append(t.pending, pendingIFD{InteroperabilityIFDKind, e.Uint32(t.endian)})
+	}
+	return Token{Kind: ElementToken, IFD: t.kind, Element: e}, nil
+}
+
+// ValidateOnly checks the structure of the JPEG header and Exif without building the tree.
+// It returns the first problem of bounds, cycles of IFDs, allocations or the thumbnail location.
+func ValidateOnly(r io.Reader) error {
+	t := NewTokenizer(r)
+	var thumbnailOffset, thumbnailLength uint32
+	for {
+		token, err := t.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch {
+		case token.Kind == ElementToken && token.IFD == IFD1Kind && token.Element.Tag == tagJPEGInterchangeFormat:
+			thumbnailOffset = token.Element.Uint32(t.endian)
+		case token.Kind == ElementToken && token.IFD == IFD1Kind && token.Element.Tag == tagJPEGInterchangeFormatLength:
+			thumbnailLength = token.Element.Uint32(t.endian)
+		case token.Kind == IFDEndToken && token.IFD == IFD1Kind:
+			if int64(thumbnailOffset)+int64(thumbnailLength) > int64(len(t.tiff)) {
+				return fmt.Errorf("Thumbnail at 0x%x (%d bytes) is out of TIFF", thumbnailOffset, thumbnailLength)
+			}
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestTokenizer(t *testing.T) {
+	b := loadTestdata(t, "ii.jpg")
+	want := decodeTestdata(t, "ii.jpg").APP1
+	tokenizer := NewTokenizer(bytes.NewReader(b))
+	var kinds []IFDKind
+	var markers []byte
+	elements := map[IFDKind]int{}
+	for {
+		token, err := tokenizer.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Token error: %s", err)
+		}
+		switch token.Kind {
+		case SegmentStartToken:
+			markers = append(markers, token.Marker)
+		case IFDStartToken:
+			kinds = append(kinds, token.IFD)
+		case ElementToken:
+			elements[token.IFD]++
+			if e := want.IFD(token.IFD).Find(token.Element.Tag); e == nil || !bytes.Equal(e.Value[:e.Length()], token.Element.Value[:token.Element.Length()]) {
+				t.Errorf("%s 0x%04X wants %+v but %+v", token.IFD, token.Element.Tag, e, token.Element)
+			}
+		}
+	}
+	wantKinds := []IFDKind{IFD0Kind, ExifIFDKind, GPSIFDKind, IFD1Kind, InteroperabilityIFDKind}
+	if len(kinds) != len(wantKinds) {
+		t.Fatalf("IFDs wants %v but %v", wantKinds, kinds)
+	}
+	for i, kind := range wantKinds {
+		if kinds[i] != kind {
+			t.Errorf("IFD #%d wants %s but %s", i, kind, kinds[i])
+		}
+		if n := len(want.IFD(kind).Elements); elements[kind] != n {
+			t.Errorf("Elements of %s wants %d but %d", kind, n, elements[kind])
+		}
+	}
+	if len(markers) == 0 || markers[0] != markerAPP1 || !isSOFMarker(markers[len(markers)-1]) {
+		t.Errorf("Segments wants APP1 first and SOF last but % x", markers)
+	}
+	if _, err := tokenizer.Token(); err != io.EOF {
+		t.Errorf("Token after the end wants io.EOF but %v", err)
+	}
+}
+
+func TestTokenizer_Cycle(t *testing.T) {
+	b := append([]byte{}, loadTestdata(t, "ii.jpg")...)
+	// the next IFD offset of the 0th IFD points to the 0th IFD itself
+	binary.LittleEndian.PutUint32(b[12+8+2+12*9:], 8)
+	tokenizer := NewTokenizer(bytes.NewReader(b))
+	for {
+		_, err := tokenizer.Token()
+		if err == io.EOF {
+			t.Fatalf("Token wants error of the cycle")
+		}
+		if err != nil {
+			if !strings.Contains(err.Error(), "already visited") {
+				t.Errorf("Token wants error of the cycle but %s", err)
+			}
+			return
+		}
+	}
+}
+
+func TestTokenizer_NotJPEG(t *testing.T) {
+	if _, err := NewTokenizer(strings.NewReader("GIF89a")).Token(); err == nil {
+		t.Errorf("Token of non JPEG wants error")
+	}
+}
+
+func TestTokenKind_String(t *testing.T) {
+	if s := ElementToken.String(); s != "Element" {
+		t.Errorf("String wants Element but %s", s)
+	}
+	if s := TokenKind(9).String(); s != "TokenKind(9)" {
+		t.Errorf("String of unknown value wants TokenKind(9) but %s", s)
+	}
+}
+
+func TestValidateOnly(t *testing.T) {
+	for _, name := range []string{"ii.jpg", "mm.jpg", "nothumb.jpg", "noexif.jpg"} {
+		t.Run(name, func(t *testing.T) {
+			if err := ValidateOnly(bytes.NewReader(loadTestdata(t, name))); err != nil {
+				t.Errorf("ValidateOnly error: %s", err)
+			}
+		})
+	}
+}
+
+func TestValidateOnly_ThumbnailOutOfTIFF(t *testing.T) {
+	b := append([]byte{}, loadTestdata(t, "ii.jpg")...)
+	// JPEGInterchangeFormatLength of LONG
+	i := bytes.Index(b, []byte("\x02\x02\x04\x00\x01\x00\x00\x00"))
+	if i == -1 {
+		t.Fatalf("JPEGInterchangeFormatLength not found")
+	}
+	binary.LittleEndian.PutUint32(b[i+8:], 0xffff)
+	err := ValidateOnly(bytes.NewReader(b))
+	if err == nil || !strings.Contains(err.Error(), "out of TIFF") {
+		t.Errorf("ValidateOnly wants error of the thumbnail but %v", err)
+	}
+}
+
+func TestValidateOnly_ElementCountTooLarge(t *testing.T) {
+	b := append([]byte{}, loadTestdata(t, "ii.jpg")...)
+	// the element count of the 0th IFD
+	binary.LittleEndian.PutUint16(b[12+8:], 0xffff)
+	err := ValidateOnly(bytes.NewReader(b))
+	if err == nil || !strings.Contains(err.Error(), "65535 elements") {
+		t.Errorf("ValidateOnly wants error of the element count but %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxTreeValueLength is the maximum length of a value shown in the tree.
+const maxTreeValueLength = 64
+
+// Tree returns the structure of the JPEG header as an indented tree.
+// The Exif APP1 contains the 0th IFD, which contains the Exif and GPS IFDs at their pointers,
+// and the 1st IFD. Each element is shown as name, type, count and value.
+func (h *JPEGHeader) Tree() string {
+	var b strings.Builder
+	b.WriteString("SOI\n")
+	for _, s := range h.segments {
+		fmt.Fprintf(&b, "%s (%d bytes)\n", markerName(s.marker), len(s.data))
+		if s.exif && h.APP1 != nil {
+			h.APP1.writeTree(&b, 1)
+		}
+	}
+	return b.String()
+}
+
+func (a *APP1) writeTree(b *strings.Builder, depth int) {
+	endian, err := endianName(a.Endian)
+	if err != nil {
+		endian = "unknown"
+	}
+	fmt.Fprintf(b, "%sTIFF (%s)\n", treeIndent(depth), endian)
+	a.writeIFDTree(b, IFD0Kind, depth+1)
+	a.writeIFDTree(b, IFD1Kind, depth+1)
+}
+
+func (a *APP1) writeIFDTree(b *strings.Builder, kind IFDKind, depth int) {
+	d := a.IFD(kind)
+	if d == nil {
+		return
+	}
+	fmt.Fprintf(b, "%s%s (%d elements)\n", treeIndent(depth), kind, len(d.Elements))
+	for _, e := range d.Elements {
+		name, ok := TagName(kind, e.Tag)
+		if !ok {
+			name = fmt.Sprintf("0x%04X", e.Tag)
+		}
+		value := formatValue(e, a.Endian)
+		if len(value) > maxTreeValueLength {
+			value = value[:maxTreeValueLength] + "..."
+		}
+		fmt.Fprintf(b, "%s%s %s[%d] = %s\n", treeIndent(depth+1), name, e.Type, e.Count, value)
+		switch {
+		case kind == IFD0Kind && e.Tag == tagExifIFDPointer:
+			a.writeIFDTree(b, ExifIFDKind, depth+2)
+		case kind == IFD0Kind && e.Tag == tagGPSInfoIFDPointer:
+			a.writeIFDTree(b, GPSIFDKind, depth+2)
+		case kind == ExifIFDKind && e.Tag == tagInteroperabilityIFDPointer:
+			a.writeIFDTree(b, InteroperabilityIFDKind, depth+2)
+		}
+	}
+	if kind == IFD1Kind && a.thumbnail != nil {
+		fmt.Fprintf(b, "%sThumbnail (%d bytes)\n", treeIndent(depth+1), len(a.thumbnail))
+	}
+}
+
+func treeIndent(depth int) string {
+	return strings.Repeat("  ", depth)
+}
+
+// markerName returns the name of the JPEG marker such as "APP1" or "SOF0".
+func markerName(marker byte) string {
+	switch {
+	case marker >= 0xe0 && marker <= 0xef:
+		return fmt.Sprintf("APP%d", marker-0xe0)
+	case isSOFMarker(marker):
+		return fmt.Sprintf("SOF%d", marker-0xc0)
+	}
+	switch marker {
+	case 0xc4:
+		return "DHT"
+	case 0xdb:
+		return "DQT"
+	case 0xdd:
+		return "DRI"
+	case 0xfe:
+		return "COM"
+	case markerSOS:
+		return "SOS"
+	case markerEOI:
+		return "EOI"
+	}
+	return fmt.Sprintf("0xFF%02X", marker)
+}
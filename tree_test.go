@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestJPEGHeader_Tree(t *testing.T) {
+	tree := decodeTestdata(t, "ii.jpg").Tree()
+	for _, want := range []string{
+		"SOI\n",
+		"APP1 (965 bytes)\n  TIFF (II)\n    IFD0 (9 elements)\n",
+		"      Make ASCII[6] = Canon\n",
+		"      ExifIFDPointer LONG[1] = ",
+		"        Exif (11 elements)\n",
+		"          Interop (2 elements)\n",
+		"        GPS (7 elements)\n",
+		"    IFD1 (3 elements)\n",
+		"      Thumbnail (331 bytes)\n",
+		"SOF0 (",
+	} {
+		if !strings.Contains(tree, want) {
+			t.Errorf("Tree wants %q but\n%s", want, tree)
+		}
+	}
+}
+
+func TestJPEGHeader_Tree_LongValue(t *testing.T) {
+	h := decodeTestdata(t, "ii.jpg")
+	h.APP1.SetElement(IFD0Kind, newASCIIElement(tagArtist, strings.Repeat("x", 100)))
+	if tree := h.Tree(); !strings.Contains(tree, "Artist ASCII[101] = "+strings.Repeat("x", maxTreeValueLength)+"...\n") {
+		t.Errorf("Tree wants the value truncated but\n%s", tree)
+	}
+}
+
+func TestMarkerName(t *testing.T) {
+	for _, c := range []struct {
+		marker byte
+		want   string
+	}{
+		{0xe1, "APP1"},
+		{0xc2, "SOF2"},
+		{0xdb, "DQT"},
+		{markerSOS, "SOS"},
+		{0x01, "0xFF01"},
+	} {
+		if s := markerName(c.marker); s != c.want {
+			t.Errorf("markerName(0x%02x) wants %s but %s", c.marker, c.want, s)
+		}
+	}
+}
+
+func TestRun_Tree(t *testing.T) {
+	var b bytes.Buffer
+	if err := run(nil, &b, "tree", false, []string{"testdata/ii.jpg"}); err != nil {
+		t.Fatalf("run error: %s", err)
+	}
+	if !strings.Contains(b.String(), "IFD0 (9 elements)") {
+		t.Errorf("run wants the tree but %q", b.String())
+	}
+}
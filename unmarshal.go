@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Unmarshal populates the fields of the struct pointed by v from the header.
+// A field is annotated with the tag name or id, e.g. `exif:"Make"` or `exif:"0x829A"`.
+// Supported field types are string, int, float64 and time.Time.
+// A time.Time field has SubSecTime and OffsetTime applied, as same as APP1.Timestamps.
+// A field is left as is if the tag is not present.
+// If the header has no Exif, all fields are left as is.
+func Unmarshal(h *JPEGHeader, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("Unmarshal expects a pointer to struct but got %T", v)
+	}
+	if h == nil || h.APP1 == nil {
+		return nil
+	}
+	rv = rv.Elem()
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		f := rt.Field(i)
+		key, ok := f.Tag.Lookup("exif")
+		if !ok || f.PkgPath != "" {
+			continue
+		}
+		e := h.APP1.findByKey(key)
+		if e == nil {
+			continue
+		}
+		if err := unmarshalElement(rv.Field(i), e, h.APP1); err != nil {
+			return fmt.Errorf("Could not unmarshal %s into field %s: %s", key, f.Name, err)
+		}
+	}
+	return nil
+}
+
+// findByKey returns the first element present of the tag name or id in order of IFD0, Exif, GPS and Interop.
+func (a *APP1) findByKey(key string) *IFDElement {
+	kinds := []IFDKind{IFD0Kind, ExifIFDKind, GPSIFDKind, InteroperabilityIFDKind}
+	if strings.HasPrefix(key, "0x") || strings.HasPrefix(key, "0X") {
+		id, err := strconv.ParseUint(key[2:], 16, 16)
+		if err != nil {
+			return nil
+		}
+		for _, kind := range kinds {
+			if e := a.IFD(kind).Find(uint16(id)); e != nil {
+				return e
+			}
+		}
+		return nil
+	}
+	for _, kind := range kinds {
+		if id, ok := tagIDs[kind][key]; ok {
+			if e := a.IFD(kind).Find(id); e != nil {
+				return e
+			}
+		}
+	}
+	return nil
+}
+
+// Value returns the decoded value of the tag name or id, such as "Make" or "0x010F".
+// Elements keep the raw bytes on parsing and the element of the tag is decoded on the first access.
+// The decoded value is cached in the element, so it is not updated when the value is changed.
+// Value can be called concurrently, but it is not safe for concurrent use with changes to the header.
+func (h *JPEGHeader) Value(name string) (interface{}, bool) {
+	if h.APP1 == nil {
+		return nil, false
+	}
+	e := h.APP1.findByKey(name)
+	if e == nil {
+		return nil, false
+	}
+	e.lazyOnce.Do(func() {
+		e.lazyValue, e.lazyErr = e.Decode(h.APP1.Endian)
+	})
+	if e.lazyErr != nil {
+		return nil, false
+	}
+	return e.lazyValue, true
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func unmarshalElement(field reflect.Value, e *IFDElement, a *APP1) error {
+	if field.Type() == timeType {
+		// SubSecTime and OffsetTime are applied as same as Timestamps
+		subSecTime, offsetTime := a.dateTimeSubElements(e.Tag)
+		t := timestamp(e, subSecTime, offsetTime)
+		if t.IsZero() {
+			return fmt.Errorf("Invalid date time: %s", formatValue(e, a.Endian))
+		}
+		field.Set(reflect.ValueOf(t))
+		return nil
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(formatValue(e, a.Endian))
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := elementInt(e, a)
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+		return nil
+	case reflect.Float32, reflect.Float64:
+		f, err := elementFloat(e, a)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+		return nil
+	}
+	return fmt.Errorf("Unsupported field type %s", field.Type())
+}
+
+// elementInt returns the first value of an integer element.
+func elementInt(e *IFDElement, a *APP1) (int64, error) {
+	switch e.Type {
+	case 1:
+		v, err := e.Bytes()
+		if err != nil {
+			return 0, err
+		}
+		if len(v) > 0 {
+			return int64(v[0]), nil
+		}
+	case 3:
+		v, err := e.Uint16s(a.Endian)
+		if err != nil {
+			return 0, err
+		}
+		if len(v) > 0 {
+			return int64(v[0]), nil
+		}
+	case 4:
+		v, err := e.Uint32s(a.Endian)
+		if err != nil {
+			return 0, err
+		}
+		if len(v) > 0 {
+			return int64(v[0]), nil
+		}
+	case 9:
+		v, err := e.Int32s(a.Endian)
+		if err != nil {
+			return 0, err
+		}
+		if len(v) > 0 {
+			return int64(v[0]), nil
+		}
+	default:
+		return 0, fmt.Errorf("%s is not an integer type", e.Type)
+	}
+	return 0, fmt.Errorf("No value")
+}
+
+// elementFloat returns the first value of a rational or integer element.
+func elementFloat(e *IFDElement, a *APP1) (float64, error) {
+	switch e.Type {
+	case 5:
+		v, err := e.Rationals(a.Endian)
+		if err != nil {
+			return 0, err
+		}
+		if len(v) > 0 {
+			return v[0].Float64(), nil
+		}
+		return 0, fmt.Errorf("No value")
+	case 10:
+		v, err := e.SRationals(a.Endian)
+		if err != nil {
+			return 0, err
+		}
+		if len(v) > 0 {
+			return v[0].Float64(), nil
+		}
+		return 0, fmt.Errorf("No value")
+	}
+	n, err := elementInt(e, a)
+	return float64(n), err
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+)
+
+type unmarshalTestStruct struct {
+	Make        string    `exif:"Make"`
+	Orientation int       `exif:"0x0112"`
+	FNumber     float64   `exif:"FNumber"`
+	DateTime    time.Time `exif:"DateTimeOriginal"`
+}
+
+func TestUnmarshal(t *testing.T) {
+	h := decodeTestdata(t, "ii.jpg")
+	var v unmarshalTestStruct
+	if err := Unmarshal(h, &v); err != nil {
+		t.Fatalf("Unmarshal error: %s", err)
+	}
+	if v.Make != "Canon" {
+		t.Errorf("Make wants Canon but %q", v.Make)
+	}
+	if v.Orientation != 6 {
+		t.Errorf("Orientation wants 6 but %d", v.Orientation)
+	}
+	if v.FNumber != 2.8 {
+		t.Errorf("FNumber wants 2.8 but %v", v.FNumber)
+	}
+	if want := h.APP1.Timestamps().DateTimeOriginal; !v.DateTime.Equal(want) {
+		t.Errorf("DateTime wants %s of Timestamps but %s", want, v.DateTime)
+	}
+}
+
+func TestUnmarshal_OffsetTime(t *testing.T) {
+	h := decodeTestdata(t, "ii.jpg")
+	h.APP1.SetElement(ExifIFDKind, newASCIIElement(tagOffsetTimeOriginal, "+09:00"))
+	var v unmarshalTestStruct
+	if err := Unmarshal(h, &v); err != nil {
+		t.Fatalf("Unmarshal error: %s", err)
+	}
+	want := time.Date(2018, 9, 22, 10, 11, 12, 340000000, time.FixedZone("", 9*60*60))
+	if !v.DateTime.Equal(want) {
+		t.Errorf("DateTime wants %s but %s", want, v.DateTime)
+	}
+	if _, offset := v.DateTime.Zone(); offset != 9*60*60 {
+		t.Errorf("Offset wants +09:00 but %d", offset)
+	}
+}
+
+func TestUnmarshal_NoExif(t *testing.T) {
+	h := decodeTestdata(t, "noexif.jpg")
+	v := unmarshalTestStruct{Make: "unchanged"}
+	if err := Unmarshal(h, &v); err != nil {
+		t.Fatalf("Unmarshal error: %s", err)
+	}
+	if v.Make != "unchanged" {
+		t.Errorf("Make wants unchanged but %q", v.Make)
+	}
+}
+
+func TestUnmarshal_NotPointer(t *testing.T) {
+	h := decodeTestdata(t, "ii.jpg")
+	if err := Unmarshal(h, unmarshalTestStruct{}); err == nil {
+		t.Errorf("Unmarshal wants error")
+	}
+}
+
+func TestJPEGHeader_Value(t *testing.T) {
+	h := decodeTestdata(t, "ii.jpg")
+	e := h.APP1.findByKey("Make")
+	if e.lazyValue != nil {
+		t.Fatalf("lazyValue wants nil before access")
+	}
+	for i := 0; i < 2; i++ {
+		v, ok := h.Value("Make")
+		if !ok || v != "Canon" {
+			t.Errorf("Value wants Canon but %q", v)
+		}
+	}
+	if e.lazyValue == nil {
+		t.Errorf("lazyValue wants non-nil after access")
+	}
+	if e := h.APP1.findByKey("Model"); e.lazyValue != nil {
+		t.Errorf("Model wants not decoded")
+	}
+	if _, ok := h.Value("0x9999"); ok {
+		t.Errorf("Value of missing tag wants false")
+	}
+}
+
+func TestAPP1_findByKey(t *testing.T) {
+	a := newTestAPP1(nil)
+	padding := newUndefinedElement(0xEA1C, []byte{0, 0})
+	a.SetElement(ExifIFDKind, padding)
+	if e := a.findByKey("Padding"); e != padding {
+		t.Errorf("Padding wants the element in Exif IFD but %+v", e)
+	}
+	if e := a.findByKey("0xEA1C"); e != padding {
+		t.Errorf("0xEA1C wants the element in Exif IFD but %+v", e)
+	}
+	if e := a.findByKey("Unknown"); e != nil {
+		t.Errorf("Unknown wants nil but %+v", e)
+	}
+	if err := RegisterTag(ExifIFDKind, 0xEA1D, "TestFindByKey", 7); err != nil {
+		t.Fatalf("RegisterTag error: %s", err)
+	}
+	defer func() {
+		delete(tagDefs[ExifIFDKind], 0xEA1D)
+		tagIDs = buildTagIDs()
+	}()
+	a.SetElement(ExifIFDKind, newUndefinedElement(0xEA1D, []byte{1}))
+	if e := a.findByKey("TestFindByKey"); e == nil || e.Tag != 0xEA1D {
+		t.Errorf("Registered tag wants the element but %+v", e)
+	}
+}
+
+func TestJPEGHeader_Value_Concurrent(t *testing.T) {
+	h, err := NewFromBytes(loadTestdata(t, "ii.jpg"))
+	if err != nil {
+		t.Fatalf("NewFromBytes error: %s", err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, ok := h.Value("Model"); !ok || v != "Canon EOS 5D Mark III" {
+				t.Errorf("Value wants Canon EOS 5D Mark III but %q", v)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func BenchmarkJPEGHeader_Value(b *testing.B) {
+	data := loadTestdata(b, "ii.jpg")
+	b.Run("Lazy", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			h, err := Decode(data)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if _, ok := h.Value("Model"); !ok {
+				b.Fatal("Model not found")
+			}
+		}
+	})
+	b.Run("DecodeValues", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			h, err := parseJPEGHeader(bytes.NewReader(data), readBytes, DecodeOptions{DecodeValues: true})
+			if err != nil {
+				b.Fatal(err)
+			}
+			if h.APP1.findByKey("Model").Decoded == nil {
+				b.Fatal("Model not found")
+			}
+		}
+	})
+}
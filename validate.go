@@ -0,0 +1,177 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	tagBitsPerSample             = 0x0102
+	tagPhotometricInterpretation = 0x0106
+	tagSamplesPerPixel           = 0x0115
+	tagExtraSamples              = 0x0152
+)
+
+// Finding is a problem found by Validate.
+// IFD is segmentFinding and Tag is zero for a problem of the APP1 segment itself.
+type Finding struct {
+	IFD     IFDKind
+	Tag     uint16
+	Message string
+}
+
+const segmentFinding IFDKind = -1
+
+func (f Finding) String() string {
+	if f.IFD == segmentFinding {
+		return fmt.Sprintf("APP1: %s", f.Message)
+	}
+	name, ok := TagName(f.IFD, f.Tag)
+	if !ok {
+		name = fmt.Sprintf("0x%04X", f.Tag)
+	}
+	return fmt.Sprintf("%s %s: %s", f.IFD, name, f.Message)
+}
+
+// Validate checks the consistency of the values and returns the problems found.
+// It returns nil if no problem is found.
+func (a *APP1) Validate() []Finding {
+	findings := a.validateLength()
+	findings = append(findings, a.validateDuplicates()...)
+	findings = append(findings, a.validateValueLocations()...)
+	for _, kind := range []IFDKind{IFD0Kind, IFD1Kind} {
+		findings = append(findings, a.validateSamples(kind)...)
+	}
+	return findings
+}
+
+// photometricSamples is the number of color samples of PhotometricInterpretation,
+// not including the extra samples such as alpha.
+var photometricSamples = map[uint16]int{
+	0:     1, // WhiteIsZero
+	1:     1, // BlackIsZero
+	2:     3, // RGB
+	3:     1, // Palette color
+	4:     1, // Transparency mask
+	5:     4, // Separated, usually CMYK
+	6:     3, // YCbCr
+	32803: 1, // CFA of DNG
+}
+
+// validateSamples checks BitsPerSample has SamplesPerPixel entries
+// and SamplesPerPixel is consistent with PhotometricInterpretation and ExtraSamples.
+func (a *APP1) validateSamples(kind IFDKind) []Finding {
+	d := a.IFD(kind)
+	if d == nil {
+		return nil
+	}
+	var findings []Finding
+	samples := 1 // default of the TIFF spec
+	if e := d.Find(tagSamplesPerPixel); e != nil {
+		v, err := e.Uint16s(a.Endian)
+		if err != nil || len(v) != 1 {
+			return []Finding{{kind, tagSamplesPerPixel, "SamplesPerPixel must be a single SHORT"}}
+		}
+		samples = int(v[0])
+	}
+	if e := d.Find(tagBitsPerSample); e != nil {
+		v, err := e.Uint16s(a.Endian)
+		switch {
+		case err != nil:
+			findings = append(findings, Finding{kind, tagBitsPerSample, fmt.Sprintf("Could not decode: %s", err)})
+		case len(v) != samples:
+			findings = append(findings, Finding{kind, tagBitsPerSample,
+				fmt.Sprintf("BitsPerSample has %d entries but SamplesPerPixel is %d", len(v), samples)})
+		}
+	}
+	photometric, ok := findUint16(d, tagPhotometricInterpretation, a.Endian)
+	if !ok {
+		return findings
+	}
+	colorSamples, ok := photometricSamples[photometric]
+	if !ok {
+		return findings
+	}
+	var extraSamples int
+	if e := d.Find(tagExtraSamples); e != nil {
+		extraSamples = int(e.Count)
+	}
+	if samples != colorSamples+extraSamples {
+		findings = append(findings, Finding{kind, tagPhotometricInterpretation,
+			fmt.Sprintf("PhotometricInterpretation %d expects %d samples and %d extra samples but SamplesPerPixel is %d",
+				photometric, colorSamples, extraSamples, samples)})
+	}
+	return findings
+}
+
+// validateValueLocations checks each out-of-line value does not overlap the other regions of the TIFF,
+// i.e. the TIFF header, the table of an IFD, the thumbnail or a value of another IFD.
+// Some writers put the values elsewhere, such as in the area of another IFD.
+// It checks the layout of the parsed bytes, so it does nothing if RawTIFF is not present.
+func (a *APP1) validateValueLocations() []Finding {
+	regions := a.Layout()
+	if regions == nil {
+		return nil
+	}
+	type value struct {
+		kind       IFDKind
+		e          *IFDElement
+		start, end int64
+	}
+	var values []value
+	a.Walk(func(kind IFDKind, e *IFDElement) {
+		if length := e.length64(); length > 4 {
+			start := int64(e.Uint32(a.Endian))
+			values = append(values, value{kind, e, start, start + length})
+		}
+	})
+	var findings []Finding
+	for _, v := range values {
+		message := fmt.Sprintf("Value at 0x%x (%d bytes) overlaps", v.start, v.end-v.start)
+		for _, r := range regions {
+			// the values of an IFD are checked by each element below
+			if strings.HasSuffix(r.Name, " values") || v.end <= int64(r.Start) || int64(r.End) <= v.start {
+				continue
+			}
+			findings = append(findings, Finding{v.kind, v.e.Tag, fmt.Sprintf("%s the %s at 0x%x-0x%x", message, r.Name, r.Start, r.End)})
+		}
+		for _, w := range values {
+			if w.kind == v.kind || v.end <= w.start || w.end <= v.start {
+				continue
+			}
+			name, ok := TagName(w.kind, w.e.Tag)
+			if !ok {
+				name = fmt.Sprintf("0x%04X", w.e.Tag)
+			}
+			findings = append(findings, Finding{v.kind, v.e.Tag, fmt.Sprintf("%s the value of %s %s at 0x%x-0x%x", message, w.kind, name, w.start, w.end)})
+		}
+	}
+	return findings
+}
+
+// validateLength checks the TIFF content ends at the end of the APP1 segment,
+// i.e. the length field of the segment matches the bytes used by the TIFF.
+// Trailing bytes are left by a writer which truncated or padded the segment.
+func (a *APP1) validateLength() []Finding {
+	if a.RawTIFF == nil {
+		return nil
+	}
+	var end int64
+	for _, r := range a.Layout() {
+		if int64(r.End) > end {
+			end = int64(r.End)
+		}
+	}
+	a.Walk(func(kind IFDKind, e *IFDElement) {
+		if length := e.length64(); length > 4 {
+			if valueEnd := int64(e.Uint32(a.Endian)) + length; valueEnd > end {
+				end = valueEnd
+			}
+		}
+	})
+	if size := int64(len(a.RawTIFF)); end < size {
+		return []Finding{{segmentFinding, 0, fmt.Sprintf(
+			"Length of the segment has %d bytes of TIFF but the content ends at %d, leaving %d bytes", size, end, size-end)}}
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/binary"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// patchValueOffset returns a copy of the TIFF with the value offset of the element replaced.
+func patchValueOffset(t *testing.T, tiff []byte, kind IFDKind, tag uint16, offset uint32) []byte {
+	t.Helper()
+	a, err := DecodeTIFFBytes(tiff)
+	if err != nil {
+		t.Fatalf("DecodeTIFFBytes error: %s", err)
+	}
+	b := append([]byte{}, tiff...)
+	for i, e := range a.IFD(kind).Elements {
+		if e.Tag == tag {
+			a.Endian.PutUint32(b[ifdOffsets(a)[kind]+2+12*uint32(i)+8:], offset)
+			return b
+		}
+	}
+	t.Fatalf("%s 0x%04X not found", kind, tag)
+	return nil
+}
+
+func regionOf(t *testing.T, a *APP1, name string) Region {
+	t.Helper()
+	for _, r := range a.Layout() {
+		if r.Name == name {
+			return r
+		}
+	}
+	t.Fatalf("Region %s not found", name)
+	return Region{}
+}
+
+func TestAPP1_Validate(t *testing.T) {
+	for _, name := range []string{"ii.jpg", "mm.jpg", "nothumb.jpg"} {
+		t.Run(name, func(t *testing.T) {
+			h := decodeTestdata(t, name)
+			if findings := h.APP1.Validate(); findings != nil {
+				t.Errorf("Validate wants nil but %v", findings)
+			}
+		})
+	}
+}
+
+func TestAPP1_validateValueLocations(t *testing.T) {
+	tiff := loadTestdata(t, "exif.tiff")
+	a, err := DecodeTIFFBytes(tiff)
+	if err != nil {
+		t.Fatalf("DecodeTIFFBytes error: %s", err)
+	}
+	exposureTime := a.ExifIFD.Find(tagExposureTime).Uint32(a.Endian)
+	for _, c := range []struct {
+		name   string
+		offset uint32
+		count  int
+		want   []string
+	}{
+		{"TIFF header", 0, 1, []string{"IFD0 Make: Value at 0x0 (6 bytes) overlaps the TIFF header"}},
+		{"IFD table", uint32(regionOf(t, a, "Exif table").Start), 1, []string{"IFD0 Make: Value at 0x", "overlaps the Exif table"}},
+		{"another IFD value", exposureTime, 2, []string{
+			"IFD0 Make: Value at 0x", "overlaps the value of Exif ExposureTime",
+			"Exif ExposureTime: Value at 0x", "overlaps the value of IFD0 Make",
+		}},
+		{"Thumbnail", uint32(regionOf(t, a, "Thumbnail").Start), 1, []string{"IFD0 Make: Value at 0x", "overlaps the Thumbnail"}},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			patched, err := DecodeTIFFBytes(patchValueOffset(t, tiff, IFD0Kind, tagMake, c.offset))
+			if err != nil {
+				t.Fatalf("DecodeTIFFBytes error: %s", err)
+			}
+			findings := patched.validateValueLocations()
+			if len(findings) != c.count {
+				t.Fatalf("Findings wants %d but %v", c.count, findings)
+			}
+			var got []string
+			for _, f := range findings {
+				got = append(got, f.String())
+			}
+			for _, want := range c.want {
+				if !strings.Contains(strings.Join(got, "\n"), want) {
+					t.Errorf("Findings wants %q but %v", want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestAPP1_validateLength(t *testing.T) {
+	a, err := DecodeTIFFBytes(append(loadTestdata(t, "exif.tiff"), 0, 0, 0, 0))
+	if err != nil {
+		t.Fatalf("DecodeTIFFBytes error: %s", err)
+	}
+	findings := a.validateLength()
+	if len(findings) != 1 || findings[0].IFD != segmentFinding || !strings.Contains(findings[0].Message, "leaving 4 bytes") {
+		t.Errorf("Findings wants 4 trailing bytes but %v", findings)
+	}
+}
+
+func TestAPP1_Validate_TrailingBytes(t *testing.T) {
+	if findings := decodeTestdata(t, "ii.jpg").APP1.validateLength(); findings != nil {
+		t.Errorf("validateLength wants nil but %v", findings)
+	}
+	if findings := NewAPP1(binary.LittleEndian).validateLength(); findings != nil {
+		t.Errorf("validateLength without the raw TIFF wants nil but %v", findings)
+	}
+	a, err := DecodeTIFFBytes(append(loadTestdata(t, "exif.tiff"), 0, 0))
+	if err != nil {
+		t.Fatalf("DecodeTIFFBytes error: %s", err)
+	}
+	findings := a.Validate()
+	if len(findings) != 1 || !strings.HasPrefix(findings[0].String(), "APP1: Length of the segment") {
+		t.Errorf("Validate wants the finding of APP1 but %v", findings)
+	}
+}
+
+func TestAPP1_validateSamples(t *testing.T) {
+	bits := func(n int) *IFDElement {
+		b := make([]byte, 2*n)
+		for i := 0; i < n; i++ {
+			b[i*2] = 8
+		}
+		return newElement(tagBitsPerSample, 3, uint32(n), b)
+	}
+	for _, c := range []struct {
+		name     string
+		elements []*IFDElement
+		want     []string
+	}{
+		{"RGB", []*IFDElement{
+			newShortElement(tagSamplesPerPixel, 3, binary.LittleEndian), bits(3),
+			newShortElement(tagPhotometricInterpretation, 2, binary.LittleEndian),
+		}, nil},
+		{"RGBA", []*IFDElement{
+			newShortElement(tagSamplesPerPixel, 4, binary.LittleEndian), bits(4),
+			newShortElement(tagPhotometricInterpretation, 2, binary.LittleEndian),
+			newShortElement(tagExtraSamples, 2, binary.LittleEndian),
+		}, nil},
+		{"default SamplesPerPixel", []*IFDElement{bits(1)}, nil},
+		{"BitsPerSample mismatch", []*IFDElement{
+			newShortElement(tagSamplesPerPixel, 3, binary.LittleEndian), bits(1),
+		}, []string{"IFD0 BitsPerSample: BitsPerSample has 1 entries but SamplesPerPixel is 3"}},
+		{"PhotometricInterpretation mismatch", []*IFDElement{
+			newShortElement(tagSamplesPerPixel, 1, binary.LittleEndian), bits(1),
+			newShortElement(tagPhotometricInterpretation, 6, binary.LittleEndian),
+		}, []string{"IFD0 PhotometricInterpretation: PhotometricInterpretation 6 expects 3 samples and 0 extra samples but SamplesPerPixel is 1"}},
+		{"invalid SamplesPerPixel", []*IFDElement{
+			newLongElement(tagSamplesPerPixel, 3, binary.LittleEndian), bits(1),
+		}, []string{"IFD0 SamplesPerPixel: SamplesPerPixel must be a single SHORT"}},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			a := newTestAPP1(nil)
+			for _, e := range c.elements {
+				a.IFD0.Set(e)
+			}
+			var got []string
+			for _, f := range a.validateSamples(IFD0Kind) {
+				got = append(got, f.String())
+			}
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("validateSamples wants %q but %q", c.want, got)
+			}
+		})
+	}
+}
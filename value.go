@@ -0,0 +1,455 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+)
+
+var ifdElementTypeNames = map[IFDElementType]string{
+	1:  "BYTE",
+	2:  "ASCII",
+	3:  "SHORT",
+	4:  "LONG",
+	5:  "RATIONAL",
+	6:  "SBYTE",
+	7:  "UNDEFINED",
+	8:  "SSHORT",
+	9:  "SLONG",
+	10: "SRATIONAL",
+	11: "FLOAT",
+	12: "DOUBLE",
+	13: "IFD",
+}
+
+func (t IFDElementType) String() string {
+	if s, ok := ifdElementTypeNames[t]; ok {
+		return s
+	}
+	return fmt.Sprintf("IFDElementType(%d)", uint16(t))
+}
+
+// Rational represents an unsigned fraction of RATIONAL type.
+type Rational struct {
+	Numerator   uint32
+	Denominator uint32
+}
+
+// Float64 returns the fraction. It returns 0 if the denominator is 0.
+func (r Rational) Float64() float64 {
+	if r.Denominator == 0 {
+		return 0
+	}
+	return float64(r.Numerator) / float64(r.Denominator)
+}
+
+func (r Rational) String() string {
+	return fmt.Sprintf("%d/%d", r.Numerator, r.Denominator)
+}
+
+// SRational represents a signed fraction of SRATIONAL type.
+type SRational struct {
+	Numerator   int32
+	Denominator int32
+}
+
+// Float64 returns the fraction. It returns 0 if the denominator is 0.
+func (r SRational) Float64() float64 {
+	if r.Denominator == 0 {
+		return 0
+	}
+	return float64(r.Numerator) / float64(r.Denominator)
+}
+
+func (r SRational) String() string {
+	return fmt.Sprintf("%d/%d", r.Numerator, r.Denominator)
+}
+
+// checkLength returns an error if the value is shorter than the count.
+func (e *IFDElement) checkLength() error {
+	if len(e.Value) < e.Length() {
+		return fmt.Errorf("Value expects %d bytes but got %d bytes", e.Length(), len(e.Value))
+	}
+	return nil
+}
+
+// ASCII returns the value of an ASCII element.
+// It is tolerant of non-conformant writers:
+// the value ends at the first NUL if present, otherwise it is the whole count bytes,
+// and trailing whitespaces are removed.
+// It returns an empty string if the count is 0.
+func (e *IFDElement) ASCII() (string, error) {
+	b, err := e.asciiBytes()
+	if err != nil {
+		return "", err
+	}
+	if i := bytes.IndexByte(b, 0); i != -1 {
+		b = b[:i]
+	}
+	return strings.TrimRight(string(b), " \t\r\n"), nil
+}
+
+// StrictASCII returns the value of an ASCII element.
+// Unlike ASCII, it returns an error if the value is not terminated with NUL.
+func (e *IFDElement) StrictASCII() (string, error) {
+	b, err := e.asciiBytes()
+	if err != nil {
+		return "", err
+	}
+	if len(b) == 0 || b[len(b)-1] != 0 {
+		return "", fmt.Errorf("ASCII value is not terminated with NUL")
+	}
+	return string(b[:len(b)-1]), nil
+}
+
+func (e *IFDElement) asciiBytes() ([]byte, error) {
+	if e.Type != 2 {
+		return nil, fmt.Errorf("ASCII expects type 2 but got type %d", e.Type)
+	}
+	if err := e.checkLength(); err != nil {
+		return nil, err
+	}
+	return e.Value[:e.Length()], nil
+}
+
+// Bytes returns a copy of the values of a BYTE element.
+func (e *IFDElement) Bytes() ([]byte, error) {
+	if e.Type != 1 {
+		return nil, fmt.Errorf("BYTE expects type 1 but got type %d", e.Type)
+	}
+	if err := e.checkLength(); err != nil {
+		return nil, err
+	}
+	b := make([]byte, e.Count)
+	copy(b, e.Value)
+	return b, nil
+}
+
+// Uint16s returns the values of a SHORT element.
+// Up to 2 values are stored inline, e.g. YCbCrSubSampling is 02 00 01 00 in little endian.
+// They are read from the inline 4 bytes in the endian, not as a LONG offset.
+func (e *IFDElement) Uint16s(endian binary.ByteOrder) ([]uint16, error) {
+	if e.Type != 3 {
+		return nil, fmt.Errorf("SHORT expects type 3 but got type %d", e.Type)
+	}
+	if err := e.checkLength(); err != nil {
+		return nil, err
+	}
+	values := make([]uint16, e.Count)
+	for i := range values {
+		values[i] = endian.Uint16(e.Value[i*2 : i*2+2])
+	}
+	return values, nil
+}
+
+// Uint32s returns the values of a LONG element.
+func (e *IFDElement) Uint32s(endian binary.ByteOrder) ([]uint32, error) {
+	if e.Type != 4 {
+		return nil, fmt.Errorf("LONG expects type 4 but got type %d", e.Type)
+	}
+	if err := e.checkLength(); err != nil {
+		return nil, err
+	}
+	values := make([]uint32, e.Count)
+	for i := range values {
+		values[i] = endian.Uint32(e.Value[i*4 : i*4+4])
+	}
+	return values, nil
+}
+
+// Int32s returns the values of a SLONG element.
+func (e *IFDElement) Int32s(endian binary.ByteOrder) ([]int32, error) {
+	if e.Type != 9 {
+		return nil, fmt.Errorf("SLONG expects type 9 but got type %d", e.Type)
+	}
+	if err := e.checkLength(); err != nil {
+		return nil, err
+	}
+	values := make([]int32, e.Count)
+	for i := range values {
+		values[i] = int32(endian.Uint32(e.Value[i*4 : i*4+4]))
+	}
+	return values, nil
+}
+
+// Rationals returns the values of a RATIONAL element.
+func (e *IFDElement) Rationals(endian binary.ByteOrder) ([]Rational, error) {
+	if e.Type != 5 {
+		return nil, fmt.Errorf("RATIONAL expects type 5 but got type %d", e.Type)
+	}
+	if err := e.checkLength(); err != nil {
+		return nil, err
+	}
+	values := make([]Rational, e.Count)
+	for i := range values {
+		values[i].Numerator = endian.Uint32(e.Value[i*8 : i*8+4])
+		values[i].Denominator = endian.Uint32(e.Value[i*8+4 : i*8+8])
+	}
+	return values, nil
+}
+
+// SRationals returns the values of a SRATIONAL element.
+func (e *IFDElement) SRationals(endian binary.ByteOrder) ([]SRational, error) {
+	if e.Type != 10 {
+		return nil, fmt.Errorf("SRATIONAL expects type 10 but got type %d", e.Type)
+	}
+	if err := e.checkLength(); err != nil {
+		return nil, err
+	}
+	values := make([]SRational, e.Count)
+	for i := range values {
+		values[i].Numerator = int32(endian.Uint32(e.Value[i*8 : i*8+4]))
+		values[i].Denominator = int32(endian.Uint32(e.Value[i*8+4 : i*8+8]))
+	}
+	return values, nil
+}
+
+var (
+	charsetASCII     = []byte("ASCII\x00\x00\x00")
+	charsetJIS       = []byte("JIS\x00\x00\x00\x00\x00")
+	charsetUnicode   = []byte("UNICODE\x00")
+	charsetUndefined = []byte("\x00\x00\x00\x00\x00\x00\x00\x00")
+)
+
+// EncodedString returns the value of an UNDEFINED element which begins with the 8 bytes character code,
+// such as UserComment, GPSProcessingMethod and GPSAreaInformation.
+// UNICODE is decoded as UCS-2 in the endian. JIS is not supported.
+// Trailing NULs and spaces are removed.
+func (e *IFDElement) EncodedString(endian binary.ByteOrder) (string, error) {
+	if e.Type != 7 {
+		return "", fmt.Errorf("Encoded string expects type 7 but got type %d", e.Type)
+	}
+	if err := e.checkLength(); err != nil {
+		return "", err
+	}
+	b := e.Value[:e.Length()]
+	if len(b) < 8 {
+		return "", fmt.Errorf("Encoded string expects at least 8 bytes but got %d bytes", len(b))
+	}
+	code, text := b[:8], b[8:]
+	var s string
+	switch {
+	case bytes.Equal(code, charsetASCII), bytes.Equal(code, charsetUndefined):
+		s = string(text)
+	case bytes.Equal(code, charsetUnicode):
+		u := make([]uint16, len(text)/2)
+		for i := range u {
+			u[i] = endian.Uint16(text[i*2 : i*2+2])
+		}
+		s = string(utf16.Decode(u))
+	case bytes.Equal(code, charsetJIS):
+		return "", fmt.Errorf("JIS character code is not supported")
+	default:
+		return "", fmt.Errorf("Unknown character code: % x", code)
+	}
+	return strings.TrimRight(s, "\x00 "), nil
+}
+
+// Decode returns the values decoded by the type of the element.
+// A single value is returned as is and multiple values as a slice.
+// ASCII is returned as string, and UNDEFINED and unknown types as []byte.
+func (e *IFDElement) Decode(endian binary.ByteOrder) (interface{}, error) {
+	switch e.Type {
+	case 1:
+		v, err := e.Bytes()
+		if err == nil && len(v) == 1 {
+			return v[0], nil
+		}
+		return v, err
+	case 2:
+		return e.ASCII()
+	case 3:
+		v, err := e.Uint16s(endian)
+		if err == nil && len(v) == 1 {
+			return v[0], nil
+		}
+		return v, err
+	case 4:
+		v, err := e.Uint32s(endian)
+		if err == nil && len(v) == 1 {
+			return v[0], nil
+		}
+		return v, err
+	case 5:
+		v, err := e.Rationals(endian)
+		if err == nil && len(v) == 1 {
+			return v[0], nil
+		}
+		return v, err
+	case 9:
+		v, err := e.Int32s(endian)
+		if err == nil && len(v) == 1 {
+			return v[0], nil
+		}
+		return v, err
+	case 10:
+		v, err := e.SRationals(endian)
+		if err == nil && len(v) == 1 {
+			return v[0], nil
+		}
+		return v, err
+	}
+	if err := e.checkLength(); err != nil {
+		return nil, err
+	}
+	return e.Value[:e.Length()], nil
+}
+
+// decodeValue returns the value for IFDElement.Decoded.
+// ASCII is trimmed, RATIONAL and SRATIONAL are converted to float64,
+// and nil is returned if the value could not be decoded.
+func decodeValue(e *IFDElement, endian binary.ByteOrder) interface{} {
+	v, err := e.Decode(endian)
+	if err != nil {
+		return nil
+	}
+	switch v := v.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case Rational:
+		return v.Float64()
+	case SRational:
+		return v.Float64()
+	case []Rational:
+		f := make([]float64, len(v))
+		for i, r := range v {
+			f[i] = r.Float64()
+		}
+		return f
+	case []SRational:
+		f := make([]float64, len(v))
+		for i, r := range v {
+			f[i] = r.Float64()
+		}
+		return f
+	}
+	return v
+}
+
+// findEncodedString returns the encoded string value of the tag in the IFD.
+func findEncodedString(d *IFD, tag uint16, endian binary.ByteOrder) (string, bool) {
+	e := d.Find(tag)
+	if e == nil || e.Count == 0 {
+		return "", false
+	}
+	s, err := e.EncodedString(endian)
+	if err != nil {
+		return "", false
+	}
+	return s, true
+}
+
+// findASCII returns the ASCII value of the tag in the IFD.
+// It returns false if the tag is not found or the value is empty.
+func findASCII(d *IFD, tag uint16) (string, bool) {
+	e := d.Find(tag)
+	if e == nil || e.Count == 0 {
+		return "", false
+	}
+	s, err := e.ASCII()
+	if err != nil {
+		return "", false
+	}
+	return s, true
+}
+
+// findTrimmedASCII returns the ASCII value without the leading and trailing spaces.
+func findTrimmedASCII(d *IFD, tag uint16) (string, bool) {
+	s, ok := findASCII(d, tag)
+	if !ok {
+		return "", false
+	}
+	return strings.TrimSpace(s), true
+}
+
+// findUint16 returns the first SHORT value of the tag in the IFD.
+func findUint16(d *IFD, tag uint16, endian binary.ByteOrder) (uint16, bool) {
+	e := d.Find(tag)
+	if e == nil {
+		return 0, false
+	}
+	values, err := e.Uint16s(endian)
+	if err != nil || len(values) == 0 {
+		return 0, false
+	}
+	return values[0], true
+}
+
+// findUint32 returns the first LONG value of the tag in the IFD.
+func findUint32(d *IFD, tag uint16, endian binary.ByteOrder) (uint32, bool) {
+	e := d.Find(tag)
+	if e == nil {
+		return 0, false
+	}
+	values, err := e.Uint32s(endian)
+	if err != nil || len(values) == 0 {
+		return 0, false
+	}
+	return values[0], true
+}
+
+// findUint returns the first value of the tag which may be SHORT or LONG.
+func findUint(d *IFD, tag uint16, endian binary.ByteOrder) (uint32, bool) {
+	if v, ok := findUint16(d, tag, endian); ok {
+		return uint32(v), true
+	}
+	return findUint32(d, tag, endian)
+}
+
+// findRational returns the first RATIONAL value of the tag in the IFD.
+func findRational(d *IFD, tag uint16, endian binary.ByteOrder) (Rational, bool) {
+	e := d.Find(tag)
+	if e == nil {
+		return Rational{}, false
+	}
+	values, err := e.Rationals(endian)
+	if err != nil || len(values) == 0 {
+		return Rational{}, false
+	}
+	return values[0], true
+}
+
+// findBytes returns the BYTE values of the tag in the IFD.
+func findBytes(d *IFD, tag uint16) ([]byte, bool) {
+	e := d.Find(tag)
+	if e == nil || e.Count == 0 {
+		return nil, false
+	}
+	b, err := e.Bytes()
+	if err != nil {
+		return nil, false
+	}
+	return b, true
+}
+
+// findUndefinedByte returns the value of the UNDEFINED tag of a single byte, which is inline.
+func findUndefinedByte(d *IFD, tag uint16) (byte, bool) {
+	e := d.Find(tag)
+	if e == nil || e.Type != 7 || e.Count != 1 || len(e.Value) < 1 {
+		return 0, false
+	}
+	return e.Value[0], true
+}
+
+// findFloat64s returns the RATIONAL values of the tag in the IFD as float64.
+// It returns false if the number of values is not the count or any denominator is zero.
+func findFloat64s(d *IFD, tag uint16, count int, endian binary.ByteOrder) ([]float64, bool) {
+	e := d.Find(tag)
+	if e == nil {
+		return nil, false
+	}
+	r, err := e.Rationals(endian)
+	if err != nil || len(r) != count {
+		return nil, false
+	}
+	values := make([]float64, len(r))
+	for i, v := range r {
+		if v.Denominator == 0 {
+			return nil, false
+		}
+		values[i] = v.Float64()
+	}
+	return values, true
+}
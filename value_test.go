@@ -0,0 +1,179 @@
+package main
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+func TestIFDElement_Uint16s(t *testing.T) {
+	for _, c := range []struct {
+		name    string
+		e       *IFDElement
+		endian  binary.ByteOrder
+		want    []uint16
+		wantErr bool
+	}{
+		{"II", newElement(0x0102, 3, 2, []byte{8, 0, 16, 0}), binary.LittleEndian, []uint16{8, 16}, false},
+		{"MM", newElement(0x0102, 3, 2, []byte{0, 8, 0, 16}), binary.BigEndian, []uint16{8, 16}, false},
+		{"not SHORT", newElement(0x0102, 4, 1, []byte{8, 0, 0, 0}), binary.LittleEndian, nil, true},
+		{"short value", &IFDElement{Tag: 0x0102, Type: 3, Count: 3, Value: []byte{8, 0}}, binary.LittleEndian, nil, true},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := c.e.Uint16s(c.endian)
+			if c.wantErr {
+				if err == nil {
+					t.Errorf("Uint16s wants error but %v", got)
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(got, c.want) {
+				t.Errorf("Uint16s wants %v but %v, %v", c.want, got, err)
+			}
+		})
+	}
+}
+
+func TestFindUint16(t *testing.T) {
+	d := &IFD{Elements: []*IFDElement{newShortElement(0x0112, 6, binary.BigEndian), newASCIIElement(0x010f, "Canon")}}
+	if v, ok := findUint16(d, 0x0112, binary.BigEndian); !ok || v != 6 {
+		t.Errorf("findUint16 wants 6 but %d, %v", v, ok)
+	}
+	if _, ok := findUint16(d, 0x010f, binary.BigEndian); ok {
+		t.Errorf("findUint16 of ASCII wants false")
+	}
+	if _, ok := findUint16(nil, 0x0112, binary.BigEndian); ok {
+		t.Errorf("findUint16 of nil IFD wants false")
+	}
+	if v, ok := findASCII(d, 0x010f); !ok || v != "Canon" {
+		t.Errorf("findASCII wants Canon but %q, %v", v, ok)
+	}
+}
+
+func TestIFDElement_Uint32s(t *testing.T) {
+	e := newElement(0x0201, 4, 2, []byte{0, 0, 1, 0, 0, 0, 0, 2})
+	if got, err := e.Uint32s(binary.BigEndian); err != nil || !reflect.DeepEqual(got, []uint32{0x100, 2}) {
+		t.Errorf("Uint32s wants [256 2] but %v, %v", got, err)
+	}
+	if _, err := newShortElement(0x0201, 1, binary.BigEndian).Uint32s(binary.BigEndian); err == nil {
+		t.Errorf("Uint32s of SHORT wants error")
+	}
+	if v, ok := findUint32(&IFD{Elements: []*IFDElement{e}}, 0x0201, binary.BigEndian); !ok || v != 0x100 {
+		t.Errorf("findUint32 wants the first value but %d, %v", v, ok)
+	}
+}
+
+func TestIFDElement_CountZero(t *testing.T) {
+	ascii := newElement(0x010f, 2, 0, nil)
+	if s, err := ascii.ASCII(); err != nil || s != "" {
+		t.Errorf("ASCII of count 0 wants empty but %q, %v", s, err)
+	}
+	d := &IFD{Elements: []*IFDElement{ascii, newElement(0x0112, 3, 0, nil)}}
+	if _, ok := findASCII(d, 0x010f); ok {
+		t.Errorf("findASCII of count 0 wants false")
+	}
+	if _, ok := findUint16(d, 0x0112, binary.LittleEndian); ok {
+		t.Errorf("findUint16 of count 0 wants false")
+	}
+	if ts := timestamp(newElement(tagDateTime, 2, 0, nil), nil, nil); !ts.IsZero() {
+		t.Errorf("timestamp of count 0 wants zero but %s", ts)
+	}
+}
+
+func TestIFDElement_checkLength(t *testing.T) {
+	e := &IFDElement{Tag: 0x010f, Type: 2, Count: 10, Value: []byte("short")}
+	if err := e.checkLength(); err == nil {
+		t.Errorf("checkLength wants error")
+	}
+	if _, err := e.ASCII(); err == nil {
+		t.Errorf("ASCII of a short value wants error")
+	}
+	if err := newASCIIElement(0x010f, "Canon").checkLength(); err != nil {
+		t.Errorf("checkLength wants nil but %s", err)
+	}
+}
+
+func TestIFDElement_ASCII(t *testing.T) {
+	for _, c := range []struct {
+		name   string
+		value  string
+		ascii  string
+		strict string
+		err    bool
+	}{
+		{"terminated", "Canon\x00", "Canon", "Canon", false},
+		{"not terminated", "Canon", "Canon", "", true},
+		{"trailing spaces", "Canon  \x00", "Canon", "Canon  ", false},
+		{"padded with NUL", "Canon\x00\x00\x00", "Canon", "Canon\x00\x00", false},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			e := newElement(0x010f, 2, uint32(len(c.value)), []byte(c.value))
+			if s, err := e.ASCII(); err != nil || s != c.ascii {
+				t.Errorf("ASCII wants %q but %q, %v", c.ascii, s, err)
+			}
+			s, err := e.StrictASCII()
+			if c.err {
+				if err == nil {
+					t.Errorf("StrictASCII wants error but %q", s)
+				}
+				return
+			}
+			if err != nil || s != c.strict {
+				t.Errorf("StrictASCII wants %q but %q, %v", c.strict, s, err)
+			}
+		})
+	}
+}
+
+func TestIFDElement_EncodedString(t *testing.T) {
+	for _, c := range []struct {
+		name    string
+		value   []byte
+		endian  binary.ByteOrder
+		want    string
+		wantErr bool
+	}{
+		{"ASCII", []byte("ASCII\x00\x00\x00GPS\x00"), binary.LittleEndian, "GPS", false},
+		{"undefined", []byte("\x00\x00\x00\x00\x00\x00\x00\x00hello  "), binary.LittleEndian, "hello", false},
+		{"UNICODE II", []byte("UNICODE\x00h\x00i\x00"), binary.LittleEndian, "hi", false},
+		{"UNICODE MM", []byte("UNICODE\x00\x00h\x00i"), binary.BigEndian, "hi", false},
+		{"JIS", []byte("JIS\x00\x00\x00\x00\x00\x1b$B"), binary.LittleEndian, "", true},
+		{"unknown", []byte("FOO\x00\x00\x00\x00\x00bar"), binary.LittleEndian, "", true},
+		{"too short", []byte("ASCII"), binary.LittleEndian, "", true},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			e := newElement(tagUserComment, 7, uint32(len(c.value)), c.value)
+			got, err := e.EncodedString(c.endian)
+			if c.wantErr {
+				if err == nil {
+					t.Errorf("EncodedString wants error but %q", got)
+				}
+				return
+			}
+			if err != nil || got != c.want {
+				t.Errorf("EncodedString wants %q but %q, %v", c.want, got, err)
+			}
+		})
+	}
+	if _, err := newASCIIElement(tagUserComment, "ASCII").EncodedString(binary.LittleEndian); err == nil {
+		t.Errorf("EncodedString of ASCII wants error")
+	}
+}
+
+func TestIFDElement_Bytes(t *testing.T) {
+	e := newElement(tagGPSVersionID, 1, 4, []byte{2, 3, 0, 0})
+	b, err := e.Bytes()
+	if err != nil || !reflect.DeepEqual(b, []byte{2, 3, 0, 0}) {
+		t.Fatalf("Bytes wants [2 3 0 0] but %v, %v", b, err)
+	}
+	b[0] = 9
+	if e.Value[0] != 2 {
+		t.Errorf("Bytes wants a copy of the value")
+	}
+	if _, err := newShortElement(tagGPSVersionID, 1, binary.LittleEndian).Bytes(); err == nil {
+		t.Errorf("Bytes of SHORT wants error")
+	}
+	if _, ok := findBytes(&IFD{Elements: []*IFDElement{newElement(tagGPSVersionID, 1, 0, nil)}}, tagGPSVersionID); ok {
+		t.Errorf("findBytes of no value wants false")
+	}
+}
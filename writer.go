@@ -0,0 +1,230 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+func writeAPP1(w io.Writer, app1 *APP1) error {
+	var tiff bytes.Buffer
+	if err := writeTIFF(&tiff, app1); err != nil {
+		return fmt.Errorf("Could not write TIFF: %s", err)
+	}
+	app1Length := 2 + len(exifMarker) + tiff.Len()
+	if app1Length > 0xffff {
+		return fmt.Errorf("APP1 segment too large: %d bytes", app1Length)
+	}
+	length := make([]byte, 2)
+	binary.BigEndian.PutUint16(length, uint16(app1Length))
+	for _, b := range [][]byte{app1marker, length, exifMarker, tiff.Bytes()} {
+		if err := writeBytes(w, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeTIFF writes the TIFF header and IFDs.
+// Offsets of the linked IFDs and the thumbnail are recomputed.
+// The layout is 0th IFD, Exif IFD, Interoperability IFD, GPS IFD, 1st IFD and thumbnail.
+func writeTIFF(w *bytes.Buffer, app1 *APP1) error {
+	app1 = adjustPadding(app1)
+	endian := app1.Endian
+	switch endian {
+	case binary.BigEndian:
+		w.Write([]byte{0x4d, 0x4d})
+	case binary.LittleEndian:
+		w.Write([]byte{0x49, 0x49})
+	default:
+		return fmt.Errorf("Invalid endian: %v", endian)
+	}
+	if app1.IFD0 == nil {
+		return fmt.Errorf("0th IFD is missing")
+	}
+	binary.Write(w, endian, uint16(0x002a))
+	ifd0Offset := uint32(8 + len(app1.rawPreIFD))
+	binary.Write(w, endian, ifd0Offset)
+	w.Write(app1.rawPreIFD)
+
+	// sizes of IFDs depend on which links exist, not on their offsets
+	ifd0Links, exifLinks, ifd1Links := newLinks(app1)
+
+	exifOffset := ifd0Offset + ifdSize(app1.IFD0, ifd0Links, endian)
+	interopOffset := exifOffset + ifdSize(app1.ExifIFD, exifLinks, endian)
+	gpsOffset := interopOffset + ifdSize(app1.InteroperabilityIFD, nil, endian)
+	ifd1Offset := gpsOffset + ifdSize(app1.GPSIFD, nil, endian)
+	thumbnailOffset := ifd1Offset + ifdSize(app1.IFD1, ifd1Links, endian)
+
+	if _, ok := ifd0Links[tagExifIFDPointer]; ok {
+		ifd0Links[tagExifIFDPointer] = exifOffset
+	}
+	if _, ok := ifd0Links[tagGPSInfoIFDPointer]; ok {
+		ifd0Links[tagGPSInfoIFDPointer] = gpsOffset
+	}
+	if _, ok := exifLinks[tagInteroperabilityIFDPointer]; ok {
+		exifLinks[tagInteroperabilityIFDPointer] = interopOffset
+	}
+	if _, ok := ifd1Links[tagJPEGInterchangeFormat]; ok {
+		ifd1Links[tagJPEGInterchangeFormat] = thumbnailOffset
+	}
+	var ifd1NextOffset uint32
+	if app1.IFD1 != nil {
+		ifd1NextOffset = ifd1Offset
+	}
+
+	writeIFD(w, app1.IFD0, ifd0Offset, ifd1NextOffset, ifd0Links, endian)
+	writeIFD(w, app1.ExifIFD, exifOffset, 0, exifLinks, endian)
+	writeIFD(w, app1.InteroperabilityIFD, interopOffset, 0, nil, endian)
+	writeIFD(w, app1.GPSIFD, gpsOffset, 0, nil, endian)
+	writeIFD(w, app1.IFD1, ifd1Offset, 0, ifd1Links, endian)
+	w.Write(app1.thumbnail)
+	return nil
+}
+
+// linkTags are tags which have an offset or length of another block.
+// They are rewritten on writing.
+var linkTags = map[uint16]bool{
+	tagExifIFDPointer:              true,
+	tagGPSInfoIFDPointer:           true,
+	tagInteroperabilityIFDPointer:  true,
+	tagJPEGInterchangeFormat:       true,
+	tagJPEGInterchangeFormatLength: true,
+}
+
+// linkedElements returns a copy of the elements where the link tags are replaced with the links.
+// A link tag is removed if it is not in the links.
+func linkedElements(d *IFD, links map[uint16]uint32, endian binary.ByteOrder) []*IFDElement {
+	c := &IFD{}
+	for _, e := range d.Elements {
+		if !linkTags[e.Tag] {
+			c.Elements = append(c.Elements, e)
+		} else if _, ok := links[e.Tag]; ok {
+			c.Elements = append(c.Elements, e)
+		}
+	}
+	for tag, offset := range links {
+		c.Set(newLongElement(tag, offset, endian))
+	}
+	return c.Elements
+}
+
+func ifdSize(d *IFD, links map[uint16]uint32, endian binary.ByteOrder) uint32 {
+	if d == nil {
+		return 0
+	}
+	elements := linkedElements(d, links, endian)
+	size := 2 + 12*len(elements) + 4
+	for _, e := range elements {
+		if e.Length() > 4 {
+			size += e.Length() + e.Length()%2
+		}
+	}
+	return uint32(size)
+}
+
+func writeIFD(w *bytes.Buffer, d *IFD, offset uint32, nextIFDOffset uint32, links map[uint16]uint32, endian binary.ByteOrder) {
+	if d == nil {
+		return
+	}
+	elements := linkedElements(d, links, endian)
+	valuesOffset := offset + uint32(2+12*len(elements)+4)
+	var values bytes.Buffer
+	binary.Write(w, endian, uint16(len(elements)))
+	for _, e := range elements {
+		binary.Write(w, endian, e.Tag)
+		binary.Write(w, endian, uint16(e.Type))
+		binary.Write(w, endian, e.Count)
+		if e.Length() > 4 {
+			binary.Write(w, endian, valuesOffset+uint32(values.Len()))
+			values.Write(e.Value[:e.Length()])
+			if values.Len()%2 != 0 {
+				values.WriteByte(0)
+			}
+		} else {
+			inline := make([]byte, 4)
+			copy(inline, e.Value)
+			w.Write(inline)
+		}
+	}
+	binary.Write(w, endian, nextIFDOffset)
+	w.Write(values.Bytes())
+}
+
+const tagPadding = 0xEA1C
+
+// newLinks returns the links of 0th IFD, Exif IFD and 1st IFD with zero offsets.
+func newLinks(app1 *APP1) (ifd0Links, exifLinks, ifd1Links map[uint16]uint32) {
+	ifd0Links = map[uint16]uint32{}
+	if app1.ExifIFD != nil {
+		ifd0Links[tagExifIFDPointer] = 0
+	}
+	if app1.GPSIFD != nil {
+		ifd0Links[tagGPSInfoIFDPointer] = 0
+	}
+	exifLinks = map[uint16]uint32{}
+	if app1.InteroperabilityIFD != nil {
+		exifLinks[tagInteroperabilityIFDPointer] = 0
+	}
+	ifd1Links = map[uint16]uint32{}
+	if app1.thumbnail != nil {
+		ifd1Links[tagJPEGInterchangeFormat] = 0
+		ifd1Links[tagJPEGInterchangeFormatLength] = uint32(len(app1.thumbnail))
+	}
+	return
+}
+
+// tiffSize returns the size of the TIFF written by writeTIFF.
+func tiffSize(app1 *APP1) int {
+	ifd0Links, exifLinks, ifd1Links := newLinks(app1)
+	return 8 + len(app1.rawPreIFD) +
+		int(ifdSize(app1.IFD0, ifd0Links, app1.Endian)) +
+		int(ifdSize(app1.ExifIFD, exifLinks, app1.Endian)) +
+		int(ifdSize(app1.InteroperabilityIFD, nil, app1.Endian)) +
+		int(ifdSize(app1.GPSIFD, nil, app1.Endian)) +
+		int(ifdSize(app1.IFD1, ifd1Links, app1.Endian)) +
+		len(app1.thumbnail)
+}
+
+// adjustPadding returns the APP1 with the Padding tag resized
+// so that the TIFF keeps the original size, which allows in-place update of the file.
+// It returns the APP1 as is if there is no Padding tag, it is not parsed from a file,
+// or the padding is not enough to absorb the change.
+func adjustPadding(app1 *APP1) *APP1 {
+	if app1.RawTIFF == nil || app1.IFD0 == nil {
+		return app1
+	}
+	diff := len(app1.RawTIFF) - tiffSize(app1)
+	if diff == 0 {
+		return app1
+	}
+	for _, kind := range []IFDKind{IFD0Kind, ExifIFDKind} {
+		d := app1.IFD(kind)
+		padding := d.Find(tagPadding)
+		if padding == nil {
+			continue
+		}
+		length := padding.Length() + padding.Length()%2
+		if padding.Length() <= 4 {
+			length = 0
+		}
+		length += diff
+		if length != 0 && (length <= 4 || length%2 != 0) {
+			return app1
+		}
+		value := make([]byte, length)
+		copy(value, padding.Value)
+		c := *app1
+		dc := &IFD{Elements: append([]*IFDElement{}, d.Elements...), NextIFDOffset: d.NextIFDOffset}
+		dc.Set(newUndefinedElement(tagPadding, value))
+		switch kind {
+		case IFD0Kind:
+			c.IFD0 = dc
+		case ExifIFDKind:
+			c.ExifIFD = dc
+		}
+		return &c
+	}
+	return app1
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+// reencode writes the header and decodes it again.
+func reencode(t *testing.T, h *JPEGHeader) *JPEGHeader {
+	t.Helper()
+	var b bytes.Buffer
+	if _, err := h.WriteTo(&b); err != nil {
+		t.Fatalf("WriteTo error: %s", err)
+	}
+	decoded, err := Decode(b.Bytes())
+	if err != nil {
+		t.Fatalf("Decode error: %s", err)
+	}
+	return decoded
+}
+
+// assertSameElements checks the elements of all IFDs have the same type, count and value.
+func assertSameElements(t *testing.T, want, got *APP1) {
+	t.Helper()
+	var n int
+	want.Walk(func(kind IFDKind, e *IFDElement) {
+		n++
+		g := got.IFD(kind).Find(e.Tag)
+		if g == nil {
+			t.Errorf("%s 0x%04X wants present", kind, e.Tag)
+			return
+		}
+		// pointers and the thumbnail offset are recomputed on writing
+		switch e.Tag {
+		case tagExifIFDPointer, tagGPSInfoIFDPointer, tagInteroperabilityIFDPointer, tagJPEGInterchangeFormat:
+			return
+		}
+		if g.Type != e.Type || g.Count != e.Count || !bytes.Equal(g.Value[:g.Length()], e.Value[:e.Length()]) {
+			t.Errorf("%s 0x%04X wants %+v but %+v", kind, e.Tag, e, g)
+		}
+	})
+	var m int
+	got.Walk(func(IFDKind, *IFDElement) { m++ })
+	if n != m {
+		t.Errorf("Elements wants %d but %d", n, m)
+	}
+}
+
+func TestJPEGHeader_WriteTo(t *testing.T) {
+	for _, name := range []string{"ii.jpg", "mm.jpg", "nothumb.jpg"} {
+		t.Run(name, func(t *testing.T) {
+			h := decodeTestdata(t, name)
+			decoded := reencode(t, h)
+			if decoded.APP1.Endian != h.APP1.Endian {
+				t.Errorf("Endian wants %s but %s", h.APP1.Endian, decoded.APP1.Endian)
+			}
+			assertSameElements(t, h.APP1, decoded.APP1)
+			if !bytes.Equal(decoded.APP1.Thumbnail(), h.APP1.Thumbnail()) {
+				t.Errorf("Thumbnail wants %d bytes but %d bytes", len(h.APP1.Thumbnail()), len(decoded.APP1.Thumbnail()))
+			}
+		})
+	}
+}
+
+func TestAPP1_SetThumbnail(t *testing.T) {
+	thumbnail := loadTestdata(t, "noexif.jpg")
+	for _, name := range []string{"ii.jpg", "nothumb.jpg"} {
+		t.Run(name, func(t *testing.T) {
+			h := decodeTestdata(t, name)
+			h.APP1.SetThumbnail(thumbnail)
+			decoded := reencode(t, h)
+			if !bytes.Equal(decoded.APP1.Thumbnail(), thumbnail) {
+				t.Errorf("Thumbnail wants %d bytes but %d bytes", len(thumbnail), len(decoded.APP1.Thumbnail()))
+			}
+			if v, ok := findUint16(decoded.APP1.IFD1, tagCompression, decoded.APP1.Endian); !ok || v != 6 {
+				t.Errorf("Compression wants 6 but %d", v)
+			}
+			if v, ok := decoded.APP1.Make(); !ok || v != "Canon" {
+				t.Errorf("Make wants Canon but %q", v)
+			}
+		})
+	}
+}
+
+func TestWriteTIFF_Padding(t *testing.T) {
+	a := decodeTestdata(t, "ii.jpg").APP1
+	a.SetElement(IFD0Kind, newUndefinedElement(tagPadding, make([]byte, 100)))
+	var b bytes.Buffer
+	if err := writeTIFF(&b, a); err != nil {
+		t.Fatalf("writeTIFF error: %s", err)
+	}
+	padded, err := DecodeTIFFBytes(b.Bytes())
+	if err != nil {
+		t.Fatalf("DecodeTIFFBytes error: %s", err)
+	}
+	size := len(padded.RawTIFF)
+
+	padded.SetElement(IFD0Kind, newASCIIElement(tagModel, "Canon EOS 5D Mark III with a longer name"))
+	b.Reset()
+	if err := writeTIFF(&b, padded); err != nil {
+		t.Fatalf("writeTIFF error: %s", err)
+	}
+	if b.Len() != size {
+		t.Errorf("TIFF size wants %d but %d", size, b.Len())
+	}
+	decoded, err := DecodeTIFFBytes(b.Bytes())
+	if err != nil {
+		t.Fatalf("DecodeTIFFBytes error: %s", err)
+	}
+	if v, ok := decoded.Model(); !ok || v != "Canon EOS 5D Mark III with a longer name" {
+		t.Errorf("Model wants the longer name but %q", v)
+	}
+	if e := decoded.IFD0.Find(tagPadding); e == nil || e.Length() >= 100 {
+		t.Errorf("Padding wants shrunk but %+v", e)
+	}
+}
+
+func TestWriteTIFF_PaddingNotEnough(t *testing.T) {
+	a := decodeTestdata(t, "ii.jpg").APP1
+	a.SetElement(IFD0Kind, newUndefinedElement(tagPadding, make([]byte, 8)))
+	var b bytes.Buffer
+	if err := writeTIFF(&b, a); err != nil {
+		t.Fatalf("writeTIFF error: %s", err)
+	}
+	padded, err := DecodeTIFFBytes(b.Bytes())
+	if err != nil {
+		t.Fatalf("DecodeTIFFBytes error: %s", err)
+	}
+	padded.SetElement(IFD0Kind, newASCIIElement(tagModel, "Canon EOS 5D Mark III with a much longer name than the padding"))
+	if c := adjustPadding(padded); c != padded {
+		t.Errorf("adjustPadding wants the APP1 as is if the padding is not enough")
+	}
+}
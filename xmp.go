@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var xmpMarker = []byte("http://ns.adobe.com/xap/1.0/\x00")
+
+// XMP returns the XMP packet in the APP1 segment, or nil if not present.
+func (h *JPEGHeader) XMP() []byte {
+	for _, s := range h.segments {
+		if s.marker == markerAPP1 && bytes.HasPrefix(s.data, xmpMarker) {
+			return s.data[len(xmpMarker):]
+		}
+	}
+	return nil
+}
+
+// xmpProperty returns the value of the simple property such as "tiff:Orientation".
+// It supports both the attribute form and the element form.
+func xmpProperty(packet []byte, name string) (string, bool) {
+	n := regexp.QuoteMeta(name)
+	for _, re := range []*regexp.Regexp{
+		regexp.MustCompile(`\s` + n + `\s*=\s*"([^"]*)"`),
+		regexp.MustCompile(`\s` + n + `\s*=\s*'([^']*)'`),
+		regexp.MustCompile(`<` + n + `>([^<]*)</` + n + `>`),
+	} {
+		if m := re.FindSubmatch(packet); m != nil {
+			return string(bytes.TrimSpace(m[1])), true
+		}
+	}
+	return "", false
+}
+
+// maxXMPLength is the maximum length of the standard XMP packet in a segment.
+var maxXMPLength = 0xffff - 2 - len(xmpMarker)
+
+// maxExtendedXMPChunk is the maximum length of a portion of the extended XMP in a segment,
+// excluding the GUID, full length and offset.
+var maxExtendedXMPChunk = 0xffff - 2 - len(extendedXMPMarker) - 32 - 4 - 4
+
+// ExtendedXMP returns the extended XMP reassembled from the continuation segments,
+// or nil if not present.
+// It returns nil if the full length exceeds DecodeOptions.MaxSegmentSize,
+// since the length is declared by the file and may be up to 4GB.
+func (h *JPEGHeader) ExtendedXMP() []byte {
+	max := h.maxSegmentSize
+	if max <= 0 {
+		max = defaultMaxSegmentSize
+	}
+	var extended []byte
+	for _, s := range h.segments {
+		if s.marker != markerAPP1 || !bytes.HasPrefix(s.data, extendedXMPMarker) {
+			continue
+		}
+		b := s.data[len(extendedXMPMarker):]
+		if len(b) < 40 {
+			continue
+		}
+		fullLength := binary.BigEndian.Uint32(b[32:36])
+		offset := binary.BigEndian.Uint32(b[36:40])
+		chunk := b[40:]
+		if uint64(fullLength) > uint64(max) {
+			return nil
+		}
+		if extended == nil {
+			extended = make([]byte, fullLength)
+		}
+		if int64(offset)+int64(len(chunk)) > int64(len(extended)) {
+			continue
+		}
+		copy(extended[offset:], chunk)
+	}
+	return extended
+}
+
+var (
+	hasExtendedXMPPattern = regexp.MustCompile(`(xmpNote:HasExtendedXMP\s*=\s*["'])[0-9A-Fa-f]*(["'])`)
+	hasExtendedXMPAttr    = regexp.MustCompile(`\s+xmpNote:HasExtendedXMP\s*=\s*("[^"]*"|'[^']*')`)
+	rdfDescriptionPattern = regexp.MustCompile(`<rdf:Description\b`)
+)
+
+// setHasExtendedXMP sets xmpNote:HasExtendedXMP of the standard XMP to the GUID.
+// If the attribute is missing, it is inserted into the first rdf:Description with the namespace.
+func setHasExtendedXMP(standard []byte, guid string) ([]byte, error) {
+	if hasExtendedXMPPattern.Match(standard) {
+		return hasExtendedXMPPattern.ReplaceAll(standard, []byte("${1}"+guid+"${2}")), nil
+	}
+	loc := rdfDescriptionPattern.FindIndex(standard)
+	if loc == nil {
+		return nil, fmt.Errorf("rdf:Description not found in XMP for xmpNote:HasExtendedXMP")
+	}
+	attr := fmt.Sprintf(` xmpNote:HasExtendedXMP="%s"`, guid)
+	if !bytes.Contains(standard, []byte("xmlns:xmpNote")) {
+		attr = ` xmlns:xmpNote="http://ns.adobe.com/xmp/note/"` + attr
+	}
+	var b bytes.Buffer
+	b.Write(standard[:loc[1]])
+	b.WriteString(attr)
+	b.Write(standard[loc[1]:])
+	return b.Bytes(), nil
+}
+
+// SetXMP replaces the XMP segments with the standard XMP and the extended XMP.
+// The extended XMP is split into continuation segments with the GUID and length headers,
+// and xmpNote:HasExtendedXMP of the standard XMP is set to the GUID.
+// The segments are placed after the Exif APP1, or after the leading APP0 such as JFIF if Exif is not present,
+// since APP0 of JFIF must come right after SOI.
+// If the extended XMP is nil, the continuation segments and xmpNote:HasExtendedXMP are removed.
+// The extended XMP cannot be set without the standard XMP.
+func (h *JPEGHeader) SetXMP(standard, extended []byte) error {
+	if extended != nil && standard == nil {
+		return fmt.Errorf("Extended XMP needs the standard XMP")
+	}
+	var xmpSegments []*segment
+	if extended == nil && standard != nil {
+		standard = hasExtendedXMPAttr.ReplaceAll(standard, nil)
+	}
+	if extended != nil {
+		sum := md5.Sum(extended)
+		guid := strings.ToUpper(hex.EncodeToString(sum[:]))
+		var err error
+		standard, err = setHasExtendedXMP(standard, guid)
+		if err != nil {
+			return err
+		}
+		for offset := 0; offset < len(extended); offset += maxExtendedXMPChunk {
+			end := offset + maxExtendedXMPChunk
+			if end > len(extended) {
+				end = len(extended)
+			}
+			var data bytes.Buffer
+			data.Write(extendedXMPMarker)
+			data.WriteString(guid)
+			binary.Write(&data, binary.BigEndian, uint32(len(extended)))
+			binary.Write(&data, binary.BigEndian, uint32(offset))
+			data.Write(extended[offset:end])
+			xmpSegments = append(xmpSegments, &segment{marker: markerAPP1, data: data.Bytes()})
+		}
+	}
+	if standard != nil {
+		if len(standard) > maxXMPLength {
+			return fmt.Errorf("XMP packet too large: %d bytes", len(standard))
+		}
+		s := &segment{marker: markerAPP1, data: append(append([]byte{}, xmpMarker...), standard...)}
+		xmpSegments = append([]*segment{s}, xmpSegments...)
+	}
+
+	var segments []*segment
+	// index to insert after the leading APP0 segments, or -1 if inserted after Exif
+	leading := 0
+	for _, s := range h.segments {
+		if s.marker == markerAPP1 && (bytes.HasPrefix(s.data, xmpMarker) || bytes.HasPrefix(s.data, extendedXMPMarker)) {
+			continue
+		}
+		if leading == len(segments) && s.marker == markerAPP0 {
+			leading++
+		}
+		segments = append(segments, s)
+		if s.exif {
+			segments = append(segments, xmpSegments...)
+			leading = -1
+		}
+	}
+	if leading >= 0 {
+		segments = append(segments[:leading], append(xmpSegments, segments[leading:]...)...)
+	}
+	h.segments = segments
+	return nil
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/binary"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// extendedXMPSegment returns an extended XMP segment of the chunk with the declared full length.
+func extendedXMPSegment(fullLength, offset uint32, chunk []byte) []byte {
+	var data bytes.Buffer
+	data.Write(extendedXMPMarker)
+	data.WriteString("0123456789ABCDEF0123456789ABCDEF")
+	binary.Write(&data, binary.BigEndian, fullLength)
+	binary.Write(&data, binary.BigEndian, offset)
+	data.Write(chunk)
+	var b bytes.Buffer
+	b.Write([]byte{0xff, markerAPP1})
+	binary.Write(&b, binary.BigEndian, uint16(data.Len()+2))
+	b.Write(data.Bytes())
+	return b.Bytes()
+}
+
+// insertSegments returns the JPEG with the segments inserted after SOI.
+func insertSegments(jpeg []byte, segments ...[]byte) []byte {
+	b := append([]byte{}, jpeg[:2]...)
+	for _, s := range segments {
+		b = append(b, s...)
+	}
+	return append(b, jpeg[2:]...)
+}
+
+func TestJPEGHeader_SetXMP(t *testing.T) {
+	h := decodeTestdata(t, "ii.jpg")
+	standard := []byte(`<x:xmpmeta xmlns:x="adobe:ns:meta/"><rdf:Description xmpNote:HasExtendedXMP="0"/></x:xmpmeta>`)
+	extended := bytes.Repeat([]byte("x"), maxExtendedXMPChunk+10)
+	if err := h.SetXMP(standard, extended); err != nil {
+		t.Fatalf("SetXMP error: %s", err)
+	}
+	var b bytes.Buffer
+	if _, err := h.WriteTo(&b); err != nil {
+		t.Fatalf("WriteTo error: %s", err)
+	}
+	decoded, err := Decode(b.Bytes())
+	if err != nil {
+		t.Fatalf("Decode error: %s", err)
+	}
+	if !bytes.Contains(decoded.XMP(), []byte("xmpNote:HasExtendedXMP")) {
+		t.Errorf("XMP wants the standard packet but %q", decoded.XMP())
+	}
+	if !bytes.Equal(decoded.ExtendedXMP(), extended) {
+		t.Errorf("ExtendedXMP wants %d bytes but %d bytes", len(extended), len(decoded.ExtendedXMP()))
+	}
+}
+
+func TestJPEGHeader_ExtendedXMP_Declared4GB(t *testing.T) {
+	b := insertSegments(loadTestdata(t, "ii.jpg"), extendedXMPSegment(0xffffffff, 0, []byte("chunk")))
+	h, err := Decode(b)
+	if err != nil {
+		t.Fatalf("Decode error: %s", err)
+	}
+	if x := h.ExtendedXMP(); x != nil {
+		t.Errorf("ExtendedXMP wants nil but %d bytes", len(x))
+	}
+}
+
+func TestJPEGHeader_ExtendedXMP_MaxSegmentSize(t *testing.T) {
+	// each segment fits in the limit but the reassembled data does not
+	chunk := bytes.Repeat([]byte("x"), 512)
+	b := insertSegments(loadTestdata(t, "ii.jpg"),
+		extendedXMPSegment(2048, 0, chunk),
+		extendedXMPSegment(2048, 512, chunk))
+	for _, c := range []struct {
+		max  int
+		want int
+	}{
+		{0, 2048},
+		{2048, 2048},
+		{2047, 0},
+		{1024, 0},
+	} {
+		d := Decoder{Options: DecodeOptions{MaxSegmentSize: c.max}}
+		h, err := d.Decode(bytes.NewReader(b))
+		if err != nil {
+			t.Fatalf("Decode with MaxSegmentSize %d error: %s", c.max, err)
+		}
+		if got := len(h.ExtendedXMP()); got != c.want {
+			t.Errorf("ExtendedXMP with MaxSegmentSize %d wants %d bytes but %d bytes", c.max, c.want, got)
+		}
+	}
+}
+
+func TestXMPProperty(t *testing.T) {
+	for _, c := range []struct {
+		name   string
+		packet string
+		want   string
+		wantOK bool
+	}{
+		{"double quoted attribute", `<rdf:Description tiff:Orientation="6"/>`, "6", true},
+		{"single quoted attribute", `<rdf:Description tiff:Orientation = '3'/>`, "3", true},
+		{"element", `<tiff:Orientation> 8 </tiff:Orientation>`, "8", true},
+		{"not present", `<rdf:Description tiff:Make="Canon"/>`, "", false},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			got, ok := xmpProperty([]byte(c.packet), "tiff:Orientation")
+			if ok != c.wantOK || got != c.want {
+				t.Errorf("xmpProperty wants %q, %v but %q, %v", c.want, c.wantOK, got, ok)
+			}
+		})
+	}
+}
+
+func TestJPEGHeader_SetXMP_Replace(t *testing.T) {
+	h := decodeTestdata(t, "ii.jpg")
+	if err := h.SetXMP([]byte("<first/>"), nil); err != nil {
+		t.Fatalf("SetXMP error: %s", err)
+	}
+	if err := h.SetXMP([]byte("<second/>"), nil); err != nil {
+		t.Fatalf("SetXMP error: %s", err)
+	}
+	segments := h.AppSegments()
+	if len(segments) != 3 || !bytes.HasPrefix(segments[0].Data, exifMarker) || !bytes.HasPrefix(segments[1].Data, xmpMarker) {
+		t.Fatalf("AppSegments wants Exif, XMP and JFIF in order but %d segments", len(segments))
+	}
+	if string(h.XMP()) != "<second/>" {
+		t.Errorf("XMP wants the second packet but %q", h.XMP())
+	}
+}
+
+func TestJPEGHeader_SetXMP_TooLarge(t *testing.T) {
+	h := decodeTestdata(t, "ii.jpg")
+	if err := h.SetXMP(bytes.Repeat([]byte("x"), maxXMPLength+1), nil); err == nil {
+		t.Errorf("SetXMP of too large packet wants error")
+	}
+}
+
+func TestSetHasExtendedXMP(t *testing.T) {
+	for _, c := range []struct {
+		standard string
+		want     string
+	}{
+		{
+			`<rdf:Description xmpNote:HasExtendedXMP='0'/>`,
+			`<rdf:Description xmpNote:HasExtendedXMP='ABC'/>`,
+		},
+		{
+			`<rdf:Description tiff:Orientation="6"/>`,
+			`<rdf:Description xmlns:xmpNote="http://ns.adobe.com/xmp/note/" xmpNote:HasExtendedXMP="ABC" tiff:Orientation="6"/>`,
+		},
+		{
+			`<rdf:RDF xmlns:xmpNote="http://ns.adobe.com/xmp/note/"><rdf:Description/></rdf:RDF>`,
+			`<rdf:RDF xmlns:xmpNote="http://ns.adobe.com/xmp/note/"><rdf:Description xmpNote:HasExtendedXMP="ABC"/></rdf:RDF>`,
+		},
+	} {
+		got, err := setHasExtendedXMP([]byte(c.standard), "ABC")
+		if err != nil || string(got) != c.want {
+			t.Errorf("setHasExtendedXMP wants %s but %s, %v", c.want, got, err)
+		}
+	}
+	if _, err := setHasExtendedXMP([]byte("<x:xmpmeta/>"), "ABC"); err == nil {
+		t.Errorf("setHasExtendedXMP without rdf:Description wants error")
+	}
+}
+
+func TestJPEGHeader_SetXMP_InsertHasExtendedXMP(t *testing.T) {
+	h := decodeTestdata(t, "ii.jpg")
+	extended := []byte("<extended/>")
+	if err := h.SetXMP([]byte(`<rdf:Description tiff:Orientation="6"/>`), extended); err != nil {
+		t.Fatalf("SetXMP error: %s", err)
+	}
+	sum := md5.Sum(extended)
+	guid := strings.ToUpper(hex.EncodeToString(sum[:]))
+	if v, ok := xmpProperty(h.XMP(), "xmpNote:HasExtendedXMP"); !ok || v != guid {
+		t.Errorf("HasExtendedXMP wants %s but %q", guid, v)
+	}
+	if v, ok := xmpProperty(h.XMP(), "tiff:Orientation"); !ok || v != "6" {
+		t.Errorf("Orientation wants 6 but %q", v)
+	}
+}
+
+func TestJPEGHeader_SetXMP_RemoveExtended(t *testing.T) {
+	h := decodeTestdata(t, "ii.jpg")
+	if err := h.SetXMP(nil, []byte("<extended/>")); err == nil {
+		t.Errorf("SetXMP of extended XMP without standard XMP wants error")
+	}
+	if h.XMP() != nil {
+		t.Errorf("XMP wants nil after the error but %q", h.XMP())
+	}
+	if err := h.SetXMP([]byte(`<rdf:Description/>`), []byte("<extended/>")); err != nil {
+		t.Fatalf("SetXMP error: %s", err)
+	}
+	if err := h.SetXMP([]byte(`<rdf:Description xmpNote:HasExtendedXMP="0123" tiff:Orientation="6"/>`), nil); err != nil {
+		t.Fatalf("SetXMP error: %s", err)
+	}
+	if want := `<rdf:Description tiff:Orientation="6"/>`; string(h.XMP()) != want {
+		t.Errorf("XMP wants %s but %s", want, h.XMP())
+	}
+	if h.ExtendedXMP() != nil {
+		t.Errorf("ExtendedXMP wants nil but %q", h.ExtendedXMP())
+	}
+}
+
+func TestJPEGHeader_SetXMP_HasExtendedXMP(t *testing.T) {
+	h := decodeTestdata(t, "noexif.jpg")
+	extended := []byte("<extended/>")
+	if err := h.SetXMP([]byte(`<rdf:Description xmpNote:HasExtendedXMP="0"/>`), extended); err != nil {
+		t.Fatalf("SetXMP error: %s", err)
+	}
+	sum := md5.Sum(extended)
+	guid := strings.ToUpper(hex.EncodeToString(sum[:]))
+	if v, ok := xmpProperty(h.XMP(), "xmpNote:HasExtendedXMP"); !ok || v != guid {
+		t.Errorf("HasExtendedXMP wants %s but %q", guid, v)
+	}
+	segments := h.AppSegments()
+	if len(segments) != 3 || segments[0].Marker != markerAPP0 || !bytes.HasPrefix(segments[1].Data, xmpMarker) {
+		t.Errorf("AppSegments wants JFIF and then XMP without Exif but %d segments", len(segments))
+	}
+}